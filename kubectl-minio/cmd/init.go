@@ -94,6 +94,7 @@ func newInitCmd(out io.Writer, errOut io.Writer) *cobra.Command {
 	f.StringVar(&o.operatorOpts.TenantMinIOImage, "default-minio-image", "", "default tenant MinIO image")
 	f.StringVar(&o.operatorOpts.TenantConsoleImage, "default-console-image", "", "default tenant Console image")
 	f.StringVar(&o.operatorOpts.TenantKesImage, "default-kes-image", "", "default tenant KES image")
+	f.BoolVar(&o.operatorOpts.ServiceMonitor, "service-monitor", false, "create a Prometheus Operator ServiceMonitor scraping the operator metrics")
 	f.BoolVarP(&o.output, "output", "o", false, "dry run this command and generate requisite yaml")
 
 	return cmd
@@ -246,6 +247,21 @@ func (o *operatorInitCmd) run(writer io.Writer) error {
 		})
 	}
 
+	if o.operatorOpts.ServiceMonitor {
+		smYaml, err := yaml.Marshal(resources.NewServiceMonitorForOperator(o.operatorOpts).Object)
+		if err != nil {
+			return err
+		}
+		smFile, err := inMemSys.Create("service-monitor.yaml")
+		if err != nil {
+			return err
+		}
+		if _, err = smFile.Write(smYaml); err != nil {
+			return err
+		}
+		kustomizationYaml.Resources = append(kustomizationYaml.Resources, "service-monitor.yaml")
+	}
+
 	if o.operatorOpts.Namespace != "" {
 		kustomizationYaml.Namespace = o.operatorOpts.Namespace
 	}
@@ -30,6 +30,7 @@ type OperatorOptions struct {
 	TenantMinIOImage   string
 	TenantConsoleImage string
 	TenantKesImage     string
+	ServiceMonitor     bool
 }
 
 func operatorLabels() map[string]string {
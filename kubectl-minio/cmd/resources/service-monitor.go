@@ -0,0 +1,75 @@
+/*
+ * This file is part of MinIO Operator
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package resources
+
+import (
+	"fmt"
+
+	"github.com/minio/kubectl-minio/cmd/helpers"
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// rootCAConfigMapName is the ConfigMap Kubernetes publishes in every namespace with the CA of the cluster, the CA
+// signing the certificate the Operator requests with a CertificateSigningRequest
+const rootCAConfigMapName = "kube-root-ca.crt"
+
+// NewServiceMonitorForOperator will return a new Prometheus Operator ServiceMonitor scraping the metrics of the
+// MinIO Operator replicas
+func NewServiceMonitorForOperator(opts OperatorOptions) *unstructured.Unstructured {
+	labels := map[string]interface{}{}
+	for k, v := range operatorLabels() {
+		labels[k] = v
+	}
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "monitoring.coreos.com/v1",
+			"kind":       "ServiceMonitor",
+			"metadata": map[string]interface{}{
+				"name":      helpers.DefaultServiceAccount,
+				"namespace": opts.Namespace,
+				"labels":    labels,
+			},
+			"spec": map[string]interface{}{
+				"selector": map[string]interface{}{
+					"matchLabels": labels,
+				},
+				"endpoints": []interface{}{
+					map[string]interface{}{
+						// the metrics are served with TLS to the service accounts allowed to get the metrics path
+						"port":            "metrics",
+						"path":            miniov2.OperatorMetricsPath,
+						"scheme":          "https",
+						"bearerTokenFile": "/var/run/secrets/kubernetes.io/serviceaccount/token",
+						// the certificate of the Operator is issued for its service
+						"tlsConfig": map[string]interface{}{
+							"ca": map[string]interface{}{
+								"configMap": map[string]interface{}{
+									"name": rootCAConfigMapName,
+									"key":  "ca.crt",
+								},
+							},
+							"serverName": fmt.Sprintf("%s.%s.svc", helpers.DefaultOperatorServiceName, opts.Namespace),
+						},
+					},
+				},
+			},
+		},
+	}
+}
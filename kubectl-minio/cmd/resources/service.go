@@ -28,6 +28,7 @@ import (
 func NewServiceForOperator(opts OperatorOptions) *corev1.Service {
 	operatorWebhookHTTPPort := corev1.ServicePort{Port: 4222, Name: "http"}
 	operatorWebhookHTTPSPort := corev1.ServicePort{Port: 4233, Name: "https"}
+	operatorMetricsPort := corev1.ServicePort{Port: 4223, Name: "metrics"}
 	return &corev1.Service{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "v1",
@@ -42,6 +43,7 @@ func NewServiceForOperator(opts OperatorOptions) *corev1.Service {
 			Ports: []corev1.ServicePort{
 				operatorWebhookHTTPPort,
 				operatorWebhookHTTPSPort,
+				operatorMetricsPort,
 			},
 			Selector: operatorLabels(),
 			Type:     corev1.ServiceTypeClusterIP,
@@ -26,6 +26,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/dustin/go-humanize"
 	"github.com/minio/kubectl-minio/cmd/helpers"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
@@ -130,4 +131,23 @@ func printTenantInfo(tenant miniov2.Tenant) {
 	}
 	t.Render()
 	fmt.Println()
+
+	if len(tenant.Status.Servers) > 0 {
+		fmt.Printf(Blue("  Health: %s, %d drives online, %d offline, %d healing\n\n", tenant.Status.HealthStatus,
+			tenant.Status.DrivesOnline, tenant.Status.DrivesOffline, tenant.Status.DrivesHealing))
+		t = helpers.GetTable()
+		t.SetHeader([]string{"Pod", "Pool", "Set", "Drive", "PVC", "State", "Used", "Total", "Healing"})
+		for _, server := range tenant.Status.Servers {
+			for _, drive := range server.Drives {
+				healing := ""
+				if drive.Healing != nil {
+					healing = fmt.Sprintf("%d objects, %s", drive.Healing.ObjectsHealed, humanize.IBytes(uint64(drive.Healing.BytesHealed)))
+				}
+				t.Append([]string{server.Pod, server.Pool, strconv.Itoa(int(drive.Set)), drive.Path, drive.PVC, drive.State,
+					humanize.IBytes(uint64(drive.UsedSpace)), humanize.IBytes(uint64(drive.TotalSpace)), healing})
+			}
+		}
+		t.Render()
+		fmt.Println()
+	}
 }
@@ -38,10 +38,13 @@ import (
 
 	clientset "github.com/minio/operator/pkg/client/clientset/versioned"
 	informers "github.com/minio/operator/pkg/client/informers/externalversions"
+	"github.com/minio/operator/pkg/controller/bucket"
 	"github.com/minio/operator/pkg/controller/cluster"
+	"github.com/minio/operator/pkg/controller/iam"
 	prominformers "github.com/prometheus-operator/prometheus-operator/pkg/client/informers/externalversions"
 	promclientset "github.com/prometheus-operator/prometheus-operator/pkg/client/versioned"
 	apiextension "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/client-go/dynamic"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	certapi "k8s.io/client-go/kubernetes/typed/certificates/v1"
@@ -52,10 +55,11 @@ import (
 var version = "DEVELOPMENT.GOGET"
 
 var (
-	masterURL     string
-	kubeconfig    string
-	hostsTemplate string
-	checkVersion  bool
+	masterURL        string
+	kubeconfig       string
+	hostsTemplate    string
+	checkVersion     bool
+	pinImageDefaults bool
 
 	onlyOneSignalHandler = make(chan struct{})
 	shutdownSignals      = []os.Signal{os.Interrupt, syscall.SIGTERM}
@@ -68,6 +72,7 @@ func init() {
 	flag.StringVar(&masterURL, "master", "", "the address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster")
 	flag.StringVar(&hostsTemplate, "hosts-template", "", "the go template to use for hostname formatting of name fields (StatefulSet, CIService, HLService, Ellipsis, Domain)")
 	flag.BoolVar(&checkVersion, "version", false, "print version")
+	flag.BoolVar(&pinImageDefaults, "pin-image-defaults", false, "store the default MinIO, Console, KES, Log Search and Prometheus images in new and updated Tenants instead of following the operator defaults")
 }
 
 func main() {
@@ -118,16 +123,29 @@ func main() {
 		klog.Errorf("Error building Prometheus clientset: %v", err.Error())
 	}
 
+	dynamicClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		klog.Errorf("Error building dynamic client: %v", err.Error())
+	}
+
 	namespace, isNamespaced := os.LookupEnv("WATCHED_NAMESPACE")
 
 	ctx := context.Background()
+	// the CA of the Operator signs the certificate of its webhooks, it must exist before the caBundle is set
+	if err = cluster.EnsureOperatorCA(ctx, kubeClient); err != nil {
+		klog.Errorf("Error generating the CA of the Operator: %v", err)
+	}
+	if err = cluster.EnsureHashKey(ctx, kubeClient); err != nil {
+		klog.Errorf("Error generating the hash key of the Operator: %v", err)
+	}
+
 	var caContent []byte
-	operatorCATLSCert, err := kubeClient.CoreV1().Secrets(miniov2.GetNSFromFile()).Get(ctx, "operator-ca-tls", metav1.GetOptions{})
+	operatorCATLSCert, err := kubeClient.CoreV1().Secrets(miniov2.GetNSFromFile()).Get(ctx, miniov2.OperatorCATLSSecretName, metav1.GetOptions{})
 	// if custom ca.crt is not present in kubernetes secrets use the one stored in the pod
 	if err != nil {
 		caContent = miniov2.GetPodCAFromFile()
 	} else {
-		if val, ok := operatorCATLSCert.Data["ca.crt"]; ok {
+		if val, ok := operatorCATLSCert.Data[miniov2.CACertificateKey]; ok {
 			caContent = val
 		}
 	}
@@ -145,6 +163,40 @@ func main() {
 			}
 			klog.Info("caBundle on CRD updated")
 		}
+
+		webhookConfig, err := kubeClient.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(ctx, miniov2.ValidatingWebhookConfigName, metav1.GetOptions{})
+		if err != nil {
+			klog.Errorf("Error getting ValidatingWebhookConfiguration for adding caBundle: %v", err.Error())
+		} else {
+			for i := range webhookConfig.Webhooks {
+				webhookConfig.Webhooks[i].ClientConfig.CABundle = caContent
+				if webhookConfig.Webhooks[i].ClientConfig.Service != nil {
+					webhookConfig.Webhooks[i].ClientConfig.Service.Namespace = miniov2.GetNSFromFile()
+				}
+			}
+			_, err := kubeClient.AdmissionregistrationV1().ValidatingWebhookConfigurations().Update(ctx, webhookConfig, metav1.UpdateOptions{})
+			if err != nil {
+				klog.Errorf("Error updating ValidatingWebhookConfiguration with caBundle: %v", err.Error())
+			}
+			klog.Info("caBundle on ValidatingWebhookConfiguration updated")
+		}
+
+		mutatingConfig, err := kubeClient.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(ctx, miniov2.MutatingWebhookConfigName, metav1.GetOptions{})
+		if err != nil {
+			klog.Errorf("Error getting MutatingWebhookConfiguration for adding caBundle: %v", err.Error())
+		} else {
+			for i := range mutatingConfig.Webhooks {
+				mutatingConfig.Webhooks[i].ClientConfig.CABundle = caContent
+				if mutatingConfig.Webhooks[i].ClientConfig.Service != nil {
+					mutatingConfig.Webhooks[i].ClientConfig.Service.Namespace = miniov2.GetNSFromFile()
+				}
+			}
+			_, err := kubeClient.AdmissionregistrationV1().MutatingWebhookConfigurations().Update(ctx, mutatingConfig, metav1.UpdateOptions{})
+			if err != nil {
+				klog.Errorf("Error updating MutatingWebhookConfiguration with caBundle: %v", err.Error())
+			}
+			klog.Info("caBundle on MutatingWebhookConfiguration updated")
+		}
 	} else {
 		klog.Info("WARNING: Could not read ca.crt from the pod")
 	}
@@ -162,24 +214,64 @@ func main() {
 		promInformerFactory = prominformers.NewSharedInformerFactory(promClient, time.Second*30)
 	}
 
-	mainController := cluster.NewController(kubeClient, controllerClient, *certClient, promClient,
+	mainController := cluster.NewController(kubeClient, controllerClient, *certClient, promClient, dynamicClient,
 		kubeInformerFactory.Apps().V1().StatefulSets(),
 		kubeInformerFactory.Apps().V1().Deployments(),
 		kubeInformerFactory.Batch().V1().Jobs(),
 		minioInformerFactory.Minio().V2().Tenants(),
 		kubeInformerFactory.Core().V1().Services(),
+		kubeInformerFactory.Core().V1().Secrets(),
 		promInformerFactory.Monitoring().V1().ServiceMonitors(),
-		hostsTemplate, version)
+		hostsTemplate, version, pinImageDefaults)
+
+	bucketController := bucket.NewController(kubeClient, controllerClient,
+		minioInformerFactory.Minio().V2().Buckets(),
+		minioInformerFactory.Minio().V2().Tenants(),
+		mainController.TenantTransports())
+
+	iamController := iam.NewController(kubeClient, controllerClient,
+		minioInformerFactory.Minio().V2().Policies(),
+		minioInformerFactory.Minio().V2().Users(),
+		minioInformerFactory.Minio().V2().Tenants(),
+		kubeInformerFactory.Core().V1().Secrets(),
+		mainController.TenantTransports())
 
 	go kubeInformerFactory.Start(stopCh)
 	go minioInformerFactory.Start(stopCh)
 
-	if err = mainController.Start(2, stopCh); err != nil {
-		klog.Fatalf("Error running mainController: %s", err.Error())
+	// every replica serves the webhooks, only the leader runs the controllers
+	mainController.ServeWebhooks(stopCh)
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+	err = mainController.RunLeaderElection(leaderCtx, func(ctx context.Context) {
+		if err := mainController.Start(2, ctx.Done()); err != nil {
+			klog.Fatalf("Error running mainController: %s", err.Error())
+		}
+
+		if err := bucketController.Start(2, ctx.Done()); err != nil {
+			klog.Fatalf("Error running bucketController: %s", err.Error())
+		}
+
+		if err := iamController.Start(2, ctx.Done()); err != nil {
+			klog.Fatalf("Error running iamController: %s", err.Error())
+		}
+	})
+	if err != nil {
+		klog.Fatalf("Error running the leader election: %s", err.Error())
+	}
+	// the election only stops before the shutdown if this replica lost the leadership, exit so it doesn't keep
+	// reconciling along with the new leader
+	if leaderCtx.Err() == nil {
+		klog.Fatal("Lost the leadership of the MinIO Operator")
 	}
 
-	<-stopCh
 	klog.Info("Shutting down the MinIO Operator")
+	iamController.Stop()
+	bucketController.Stop()
 	mainController.Stop()
 }
 
@@ -197,7 +197,7 @@ func (in *TenantSpec) DeepCopyInto(out *TenantSpec) {
 	if in.ExposeServices != nil {
 		in, out := &in.ExposeServices, &out.ExposeServices
 		*out = new(v2.ExposeServices)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	return
 }
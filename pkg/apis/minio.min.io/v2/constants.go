@@ -43,6 +43,15 @@ const DefaultUpdateStrategy = "RollingUpdate"
 // DefaultImagePullPolicy specifies the policy to image pulls
 const DefaultImagePullPolicy = corev1.PullIfNotPresent
 
+// DefaultUpgradeBakeTime is the time the cluster health of a rolling upgrade is watched once every pod runs the new image
+const DefaultUpgradeBakeTime = 5 * time.Minute
+
+// DefaultUpgradeProgressDeadline is the time a pod of a rolling upgrade has to become ready and the cluster healthy
+const DefaultUpgradeProgressDeadline = 10 * time.Minute
+
+// MaxUpgradeHistory is the number of upgrades kept in the status of a tenant
+const MaxUpgradeHistory = 10
+
 // CSRNameSuffix specifies the suffix added to Tenant name to create a CSR
 const CSRNameSuffix = "-csr"
 
@@ -70,6 +79,18 @@ const Revision = "min.io/revision"
 // MinIOPort specifies the default Tenant port number.
 const MinIOPort = 9000
 
+// Default probes of the MinIO containers
+const (
+	MinIOLivenessPath              = "/minio/health/live"
+	MinIOReadinessPath             = "/minio/health/ready"
+	MinIOProbeTimeoutSeconds       = 5
+	MinIOProbePeriodSeconds        = 10
+	MinIOLivenessFailureThreshold  = 6
+	MinIOReadinessFailureThreshold = 3
+	// MinIOStartupFailureThreshold allows MinIO 10 minutes to start
+	MinIOStartupFailureThreshold = 60
+)
+
 // MinIOPortLoadBalancerSVC specifies the default Service port number for the load balancer service.
 const MinIOPortLoadBalancerSVC = 80
 
@@ -252,6 +273,9 @@ const DefaultKESImage = "minio/kes:v0.14.0"
 // KESInstanceLabel is applied to the KES pods of a Tenant cluster
 const KESInstanceLabel = "v1.min.io/kes"
 
+// KESJobLabel is applied to the pod of the Job creating the MinIO key on KES
+const KESJobLabel = "v1.min.io/kes-job"
+
 // KESPort specifies the default KES Service's port number.
 const KESPort = 7373
 
@@ -323,5 +347,85 @@ const tenantKesImageEnv = "TENANT_KES_IMAGE"
 
 const monitoringIntervalEnv = "MONITORING_INTERVAL"
 
+const csrSignerNameEnv = "CSR_SIGNER_NAME"
+
+const operatorCertManagerIssuerEnv = "OPERATOR_CERT_MANAGER_ISSUER"
+
+const operatorCertManagerIssuerKindEnv = "OPERATOR_CERT_MANAGER_ISSUER_KIND"
+
+const operatorInternalCAEnv = "OPERATOR_INTERNAL_CA"
+
+// DefaultMinIOUpdateMinisignPubKey is the minisign public key of the MinIO releases, verifying the binaries the
+// Operator serves to MinIO during upgrades
+const DefaultMinIOUpdateMinisignPubKey = "RWTx5Zr1tiHQLwG9keckT0c45M3AGeHD6IvimQHpyRywVWGbP1aVSGav"
+
+const minioUpdateMinisignPubKeyEnv = "MINIO_UPDATE_MINISIGN_PUBKEY"
+
+const updateRegistryMirrorsEnv = "UPDATE_REGISTRY_MIRRORS"
+
+const updateRegistryCAFileEnv = "UPDATE_REGISTRY_CA_FILE"
+
+const updateRegistrySecretEnv = "UPDATE_REGISTRY_SECRET"
+
+const updateOCILayoutPathEnv = "UPDATE_OCI_LAYOUT_PATH"
+
+const updateArtifactsPathEnv = "UPDATE_ARTIFACTS_PATH"
+
+const imageRegistryRewriteEnv = "IMAGE_REGISTRY_REWRITE"
+
+const imageAllowListEnv = "IMAGE_ALLOW_LIST"
+
+// HealthCheckTimeout is the timeout of the requests checking the health of MinIO
+const HealthCheckTimeout = 10 * time.Second
+
+const certRenewalFractionEnv = "CERT_RENEWAL_FRACTION"
+
+// DefaultCertRenewalFraction is the fraction of the lifetime of the certificates issued with CSRs or the
+// CA of the Operator after which they are renewed
+const DefaultCertRenewalFraction = 2.0 / 3.0
+
+// OperatorTLSSecretName is the secret holding the TLS certificate of the Operator
+const OperatorTLSSecretName = "operator-tls"
+
+// OperatorCATLSSecretName is the secret holding the CA of the Operator, the CA signing the certificates
+// of the tenants when the internal CA is enabled
+const OperatorCATLSSecretName = "operator-ca-tls"
+
+// Keys of the certificate and private key of the CA in the Operator CA secret. The CA certificate is
+// also stored with CACertificateKey in the secrets of the certificates the CA signs.
+const (
+	CACertificateKey = "ca.crt"
+	CAPrivateKeyKey  = "ca.key"
+)
+
+// OperatorHashKeySecretName is the secret holding the key of the HMACs of the credentials the Operator records in
+// the status of the tenants
+const OperatorHashKeySecretName = "operator-hash-key"
+
+// HashKeyKey is the key of the HMAC key in the Operator hash key secret
+const HashKeyKey = "hash.key"
+
+// Keys of the certificate and private key in the secrets of the certificates the Operator requests
+// with CertificateSigningRequests
+const (
+	CSRCertificateKey = "public.crt"
+	CSRPrivateKeyKey  = "private.key"
+)
+
+// Keys of the certificate and private key in the `kubernetes.io/tls` secrets cert-manager issues
+const (
+	TLSCertificateKey = "tls.crt"
+	TLSPrivateKeyKey  = "tls.key"
+)
+
 // DefaultMonitoringInterval is how often we run monitoring on tenants
 const DefaultMonitoringInterval = 3
+
+// BucketFinalizer is set on Bucket objects so the Operator applies their deletion policy before they are removed
+const BucketFinalizer = "minio.min.io/bucket"
+
+// PolicyFinalizer is set on Policy objects so the Operator removes the policy from MinIO before they are removed
+const PolicyFinalizer = "minio.min.io/policy"
+
+// UserFinalizer is set on User objects so the Operator removes the user from MinIO before they are removed
+const UserFinalizer = "minio.min.io/user"
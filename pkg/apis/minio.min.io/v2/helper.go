@@ -22,7 +22,6 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
-	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
@@ -41,12 +40,14 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/klog/v2"
 
 	jwtgo "github.com/dgrijalva/jwt-go"
 	"github.com/minio/madmin-go"
+	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
 )
 
@@ -54,6 +55,7 @@ import (
 const (
 	WebhookAPIVersion       = "/webhook/v1"
 	WebhookDefaultPort      = "4222"
+	OperatorMetricsPort     = "4223"
 	WebhookSecret           = "operator-webhook-secret"
 	WebhookOperatorUsername = "webhookUsername"
 	WebhookOperatorPassword = "webhookPassword"
@@ -82,6 +84,15 @@ const (
 	WebhookAPIBucketService = WebhookAPIVersion + "/bucketsrv"
 	WebhookAPIUpdate        = WebhookAPIVersion + "/update"
 	WebhookCRDConversaion   = WebhookAPIVersion + "/crd-conversion"
+	WebhookValidateTenant   = WebhookAPIVersion + "/validate-tenant"
+	WebhookDefaultTenant    = WebhookAPIVersion + "/default-tenant"
+	OperatorMetricsPath     = "/metrics"
+)
+
+// Admission webhook configurations pointing to the operator
+const (
+	ValidatingWebhookConfigName = "minio-operator-validating-webhook"
+	MutatingWebhookConfigName   = "minio-operator-mutating-webhook"
 )
 
 type hostsTemplateValues struct {
@@ -98,13 +109,39 @@ var (
 	tenantConsoleImageOnce sync.Once
 	tenantKesImageOnce     sync.Once
 	monitoringIntervalOnce sync.Once
+	certProvidersOnce      sync.Once
 	k8sClusterDomain       string
 	tenantMinIOImage       string
 	tenantConsoleImage     string
 	tenantKesImage         string
 	monitoringInterval     int
+	csrSignerName          string
+	operatorCertIssuer     *CertManagerIssuerReference
+	operatorInternalCA     bool
+	certRenewalFraction    float64
+	updateSourcesOnce      sync.Once
+	updateSources          UpdateSources
 )
 
+// UpdateSources are the sources of the MinIO binaries the Operator serves to MinIO during upgrades, besides the
+// registry of the image of the tenant
+type UpdateSources struct {
+	// MinisignPubKey verifies the signature of the binaries
+	MinisignPubKey string
+	// RegistryMirrors maps registries to the mirrors the images are pulled from instead, a mirror may include a
+	// path prefixed to the repositories
+	RegistryMirrors map[string]string
+	// RegistryCAFile is a bundle of CA certificates trusted by the registries, besides the system ones
+	RegistryCAFile string
+	// RegistrySecret is a docker config secret of the Operator namespace with the credentials of the registries
+	RegistrySecret string
+	// OCILayoutPath is a directory of OCI image layout tarballs named after the tag of their image
+	OCILayoutPath string
+	// ArtifactsPath is a directory of pre-staged binaries named `minio.<release tag>`, along with their
+	// `.minisig` signature and optionally their `.sha256sum`
+	ArtifactsPath string
+}
+
 // GetPodCAFromFile assumes the operator is running inside a k8s pod and extract the
 // current ca certificate from /var/run/secrets/kubernetes.io/serviceaccount/ca.crt
 func GetPodCAFromFile() []byte {
@@ -192,6 +229,19 @@ func (t *Tenant) AutoCert() bool {
 	return *t.Spec.RequestAutoCert
 }
 
+// HasCertManagerEnabled returns true if the AutoCert certificates of the tenant are issued by cert-manager
+func (t *Tenant) HasCertManagerEnabled() bool {
+	return t.Spec.CertConfig != nil && t.Spec.CertConfig.CertManager != nil
+}
+
+// AutoCertKeys returns the keys of the certificate and private key in the secrets of the AutoCert certificates
+func (t *Tenant) AutoCertKeys() (certKey, keyKey string) {
+	if t.HasCertManagerEnabled() {
+		return TLSCertificateKey, TLSPrivateKeyKey
+	}
+	return CSRCertificateKey, CSRPrivateKeyKey
+}
+
 // VolumePathForPool returns the paths for MinIO mounts based on
 // total number of volumes on a given pool
 func (t *Tenant) VolumePathForPool(pool *Pool) string {
@@ -430,6 +480,10 @@ func (t *Tenant) GenBearerToken(accessKey, secretKey string) string {
 func (t *Tenant) MinIOHosts() (hosts []string) {
 	// Create the ellipses style URL
 	for pi, pool := range t.Spec.Pools {
+		// decommissioned pools are no longer part of the deployment
+		if t.PoolDecommissioned(pi) {
+			continue
+		}
 		// determine the proper statefulset name
 		ssName := t.PoolStatefulsetName(&pool)
 		if len(t.Status.Pools) > pi {
@@ -445,6 +499,12 @@ func (t *Tenant) MinIOHosts() (hosts []string) {
 	return hosts
 }
 
+// PoolDecommissioned returns true when the pool at the given index of the spec is no longer
+// part of the MinIO deployment
+func (t *Tenant) PoolDecommissioned(pi int) bool {
+	return len(t.Status.Pools) > pi && t.Status.Pools[pi].State == PoolDecommissioned
+}
+
 // TemplatedMinIOHosts returns the domain names in ellipses format created for current Tenant without the service part
 func (t *Tenant) TemplatedMinIOHosts(hostsTemplate string) (hosts []string) {
 	tmpl, err := template.New("hosts").Parse(hostsTemplate)
@@ -455,8 +515,13 @@ func (t *Tenant) TemplatedMinIOHosts(hostsTemplate string) (hosts []string) {
 	}
 	var max, index int32
 	// Create the ellipses style URL
-	for _, pool := range t.Spec.Pools {
+	for pi, pool := range t.Spec.Pools {
 		max = max + pool.Servers
+		// decommissioned pools keep their range so the hosts of the other pools don't change
+		if t.PoolDecommissioned(pi) {
+			index = max
+			continue
+		}
 		data := hostsTemplateValues{
 			StatefulSet: t.MinIOStatefulSetNameForPool(&pool),
 			CIService:   t.MinIOCIServiceName(),
@@ -561,6 +626,195 @@ func (t *Tenant) HasConsoleSecret() bool {
 	return t.Spec.Console != nil && t.Spec.Console.ConsoleSecret != nil
 }
 
+// HasPodDisruptionBudgetEnabled checks if the pools of the tenant are protected by PodDisruptionBudgets
+func (t *Tenant) HasPodDisruptionBudgetEnabled() bool {
+	return t.Spec.PodDisruptionBudget == nil || !t.Spec.PodDisruptionBudget.Disabled
+}
+
+// GetUpgradeStrategy returns the strategy upgrading the MinIO pods of the tenant
+func (t *Tenant) GetUpgradeStrategy() UpgradeStrategyType {
+	if t.Spec.UpgradeStrategy == nil || t.Spec.UpgradeStrategy.Type == "" {
+		return InPlaceUpgradeStrategy
+	}
+	return t.Spec.UpgradeStrategy.Type
+}
+
+// UpgradeBakeTime returns the time the cluster health of a rolling upgrade is watched once every pod runs the new image
+func (t *Tenant) UpgradeBakeTime() time.Duration {
+	if t.Spec.UpgradeStrategy == nil || t.Spec.UpgradeStrategy.BakeTime == nil {
+		return DefaultUpgradeBakeTime
+	}
+	return t.Spec.UpgradeStrategy.BakeTime.Duration
+}
+
+// UpgradeProgressDeadline returns the time a pod of a rolling upgrade has to become ready and the cluster healthy
+func (t *Tenant) UpgradeProgressDeadline() time.Duration {
+	if t.Spec.UpgradeStrategy == nil || t.Spec.UpgradeStrategy.ProgressDeadline == nil {
+		return DefaultUpgradeProgressDeadline
+	}
+	return t.Spec.UpgradeStrategy.ProgressDeadline.Duration
+}
+
+// CurrentUpgrade returns the upgrade of the MinIO pods in progress, nil if there is none
+func (t *Tenant) CurrentUpgrade() *UpgradeRecord {
+	if n := len(t.Status.UpgradeHistory); n > 0 && t.Status.UpgradeHistory[n-1].EndTime == nil {
+		return &t.Status.UpgradeHistory[n-1]
+	}
+	return nil
+}
+
+// RolledBackUpgrade returns the last upgrade if it was rolled back and `spec.image` still requests its image, the
+// pods keep the previous image until `spec.image` changes
+func (t *Tenant) RolledBackUpgrade() *UpgradeRecord {
+	if n := len(t.Status.UpgradeHistory); n > 0 {
+		last := &t.Status.UpgradeHistory[n-1]
+		if last.Result == UpgradeRolledBack && last.To == t.Spec.Image {
+			return last
+		}
+	}
+	return nil
+}
+
+// HasNetworkPolicyEnabled checks if the pods of the tenant are isolated by NetworkPolicies
+func (t *Tenant) HasNetworkPolicyEnabled() bool {
+	return t.Spec.NetworkPolicy != nil
+}
+
+// HasIngressEnabled checks if the services of the tenant are exposed through Ingresses
+func (t *Tenant) HasIngressEnabled() bool {
+	return t.Spec.ExposeServices != nil && t.Spec.ExposeServices.Ingress != nil
+}
+
+// HasGatewayEnabled checks if the services of the tenant are exposed through Gateway API routes
+func (t *Tenant) HasGatewayEnabled() bool {
+	return t.Spec.ExposeServices != nil && t.Spec.ExposeServices.Gateway != nil
+}
+
+// MinIOExternalHosts returns the hostnames the MinIO service is exposed at through Ingresses or Gateway API routes
+func (t *Tenant) MinIOExternalHosts() []string {
+	var hosts []string
+	if t.HasIngressEnabled() && t.Spec.ExposeServices.Ingress.MinIOHost != "" {
+		hosts = append(hosts, t.Spec.ExposeServices.Ingress.MinIOHost)
+	}
+	if t.HasGatewayEnabled() && t.Spec.ExposeServices.Gateway.MinIOHost != "" {
+		if len(hosts) == 0 || hosts[0] != t.Spec.ExposeServices.Gateway.MinIOHost {
+			hosts = append(hosts, t.Spec.ExposeServices.Gateway.MinIOHost)
+		}
+	}
+	return hosts
+}
+
+// MinIODomain returns the domains of Bucket DNS, the internal one and the external hostnames of the MinIO service
+func (t *Tenant) MinIODomain() string {
+	return strings.Join(append([]string{t.MinIOBucketBaseDomain()}, t.MinIOExternalHosts()...), ",")
+}
+
+// ExternalURLs returns the URLs the tenant is reachable at through its Ingresses or Gateway API routes, the
+// ones of the Ingresses take precedence. Returns nil if neither exposes any hostname.
+func (t *Tenant) ExternalURLs() *ExternalURLs {
+	urls := &ExternalURLs{}
+	set := func(scheme, minioHost, consoleHost string) {
+		if urls.MinIO == "" && minioHost != "" {
+			urls.MinIO = fmt.Sprintf("%s://%s", scheme, minioHost)
+			if t.S3BucketDNS() {
+				urls.BucketDNS = fmt.Sprintf("%s://*.%s", scheme, minioHost)
+			}
+		}
+		if urls.Console == "" && consoleHost != "" && t.HasConsoleEnabled() {
+			urls.Console = fmt.Sprintf("%s://%s", scheme, consoleHost)
+		}
+	}
+	if t.HasIngressEnabled() {
+		ingress := t.Spec.ExposeServices.Ingress
+		scheme := "http"
+		if ingress.TLSSecret != "" {
+			scheme = "https"
+		}
+		set(scheme, ingress.MinIOHost, ingress.ConsoleHost)
+	}
+	if t.HasGatewayEnabled() {
+		gateway := t.Spec.ExposeServices.Gateway
+		scheme := "https"
+		if gateway.Insecure && !gateway.TLSPassthrough {
+			scheme = "http"
+		}
+		set(scheme, gateway.MinIOHost, gateway.ConsoleHost)
+	}
+	if *urls == (ExternalURLs{}) {
+		return nil
+	}
+	return urls
+}
+
+// envValue returns the value the tenant sets for the given MinIO environment variable
+func (t *Tenant) envValue(name string) string {
+	for _, env := range t.Spec.Env {
+		if env.Name == name {
+			return env.Value
+		}
+	}
+	return ""
+}
+
+// PoolErasureSetDriveCount returns the number of drives of the erasure sets MinIO creates for the pool. Like
+// MinIO, it picks the largest set size evenly dividing the drives of the pool that spreads symmetrically
+// across its servers, unless `MINIO_ERASURE_SET_DRIVE_COUNT` is set.
+func (t *Tenant) PoolErasureSetDriveCount(pool *Pool) int32 {
+	drives := pool.Servers * pool.VolumesPerServer
+	if count, err := strconv.Atoi(t.envValue("MINIO_ERASURE_SET_DRIVE_COUNT")); err == nil && count > 0 && drives%int32(count) == 0 {
+		return int32(count)
+	}
+	for count := int32(16); count >= 2; count-- {
+		if drives%count == 0 && (count%pool.Servers == 0 || pool.Servers%count == 0) {
+			return count
+		}
+	}
+	return drives
+}
+
+// PoolParity returns the number of parity drives of the erasure sets of the pool, from the standard
+// storage class of the tenant (`MINIO_STORAGE_CLASS_STANDARD=EC:N`) or MinIO defaults
+func (t *Tenant) PoolParity(pool *Pool) int32 {
+	setDriveCount := t.PoolErasureSetDriveCount(pool)
+	if sc := t.envValue("MINIO_STORAGE_CLASS_STANDARD"); strings.HasPrefix(sc, "EC:") {
+		if parity, err := strconv.Atoi(strings.TrimPrefix(sc, "EC:")); err == nil && parity >= 0 && int32(parity) <= setDriveCount/2 {
+			return int32(parity)
+		}
+	}
+	switch {
+	case setDriveCount <= 1:
+		return 0
+	case setDriveCount <= 3:
+		return 1
+	case setDriveCount <= 5:
+		return 2
+	case setDriveCount <= 7:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// PoolMaxUnavailableServers returns how many servers of the pool can be down while every erasure set
+// keeps its write quorum
+func (t *Tenant) PoolMaxUnavailableServers(pool *Pool) int32 {
+	if pool.Servers <= 0 || pool.VolumesPerServer <= 0 {
+		return 0
+	}
+	setDriveCount := t.PoolErasureSetDriveCount(pool)
+	parity := t.PoolParity(pool)
+	writeQuorum := setDriveCount - parity
+	if writeQuorum == parity {
+		writeQuorum++
+	}
+	// the drives of a set are spread evenly across the servers
+	drivesPerServer := int32(1)
+	if setDriveCount > pool.Servers {
+		drivesPerServer = setDriveCount / pool.Servers
+	}
+	return (setDriveCount - writeQuorum) / drivesPerServer
+}
+
 // GetConsoleEnvVars returns the environment variables for the console
 // deployment of a particular tenant
 func (t *Tenant) GetConsoleEnvVars() (env []corev1.EnvVar) {
@@ -624,59 +878,36 @@ func (t *Tenant) MinIOServerEndpoint() string {
 	return u.String()
 }
 
-// MinIOHealthCheck check MinIO cluster health
-func (t *Tenant) MinIOHealthCheck() bool {
-	// Keep TLS config.
-	tlsConfig := &tls.Config{
-		// Can't use SSLv3 because of POODLE and BEAST
-		// Can't use TLSv1.0 because of POODLE and BEAST using CBC cipher
-		// Can't use TLSv1.1 because of RC4 cipher usage
-		MinVersion:         tls.VersionTLS12,
-		InsecureSkipVerify: true, // FIXME: use trusted CA
-	}
-
+// MinIOHealthCheck check MinIO cluster health, connecting with the given transport
+func (t *Tenant) MinIOHealthCheck(tr *http.Transport) bool {
 	req, err := http.NewRequest(http.MethodGet, t.MinIOServerEndpoint()+"/minio/health/cluster", nil)
 	if err != nil {
 		return false
 	}
 
 	httpClient := &http.Client{
-		Transport:
-		// For more details about various values used here refer
-		// https://golang.org/pkg/net/http/#Transport documentation
-		&http.Transport{
-			Proxy: http.ProxyFromEnvironment,
-			DialContext: (&net.Dialer{
-				Timeout:   2 * time.Second,
-				KeepAlive: 10 * time.Second,
-			}).DialContext,
-			ResponseHeaderTimeout: 5 * time.Second,
-			TLSHandshakeTimeout:   5 * time.Second,
-			ExpectContinueTimeout: 5 * time.Second,
-			TLSClientConfig:       tlsConfig,
-			// Go net/http automatically unzip if content-type is
-			// gzip disable this feature, as we are always interested
-			// in raw stream.
-			DisableCompression: true,
-		},
-	}
-	defer httpClient.CloseIdleConnections()
+		Transport: tr,
+		Timeout:   HealthCheckTimeout,
+	}
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
 		return false
 	}
+	// the transport is shared, release the connection
+	defer resp.Body.Close()
 
 	return resp.StatusCode == http.StatusOK
 }
 
-// NewMinIOAdmin initializes a new madmin.Client for operator interaction
-func (t *Tenant) NewMinIOAdmin(minioSecret map[string][]byte) (*madmin.AdminClient, error) {
-	return t.NewMinIOAdminForAddress("", minioSecret)
+// NewMinIOAdmin initializes a new madmin.Client for operator interaction, connecting with the given transport
+func (t *Tenant) NewMinIOAdmin(minioSecret map[string][]byte, tr *http.Transport) (*madmin.AdminClient, error) {
+	return t.NewMinIOAdminForAddress("", minioSecret, tr)
 }
 
-// NewMinIOAdminForAddress initializes a new madmin.Client for operator interaction
-func (t *Tenant) NewMinIOAdminForAddress(address string, minioSecret map[string][]byte) (*madmin.AdminClient, error) {
+// NewMinIOAdminForAddress initializes a new madmin.Client for operator interaction, connecting with the given
+// transport
+func (t *Tenant) NewMinIOAdminForAddress(address string, minioSecret map[string][]byte, tr *http.Transport) (*madmin.AdminClient, error) {
 	host := address
 	if host == "" {
 		host = t.MinIOServerHostAddress()
@@ -706,13 +937,41 @@ func (t *Tenant) NewMinIOAdminForAddress(address string, minioSecret map[string]
 	}
 
 	if opts.Secure {
-		// FIXME: add trusted CA
-		madmClnt = setUpInsecureTLS(madmClnt)
+		madmClnt.SetCustomTransport(tr)
 	}
 
 	return madmClnt, nil
 }
 
+// NewMinIOClient initializes a new minio.Client for the operator to manage the buckets of the Tenant, connecting
+// with the given transport
+func (t *Tenant) NewMinIOClient(minioSecret map[string][]byte, tr *http.Transport) (*minio.Client, error) {
+	host := t.MinIOServerHostAddress()
+	if host == "" {
+		return nil, errors.New("MinIO server host is empty")
+	}
+
+	accessKey, ok := minioSecret["accesskey"]
+	if !ok {
+		return nil, errors.New("MinIO server accesskey not set")
+	}
+
+	secretKey, ok := minioSecret["secretkey"]
+	if !ok {
+		return nil, errors.New("MinIO server secretkey not set")
+	}
+
+	opts := &minio.Options{
+		Secure: t.TLS(),
+		Creds:  credentials.NewStaticV4(string(accessKey), string(secretKey), ""),
+	}
+	if opts.Secure {
+		opts.Transport = tr
+	}
+
+	return minio.New(host, opts)
+}
+
 // CreateUsers creates a list of admin users on MinIO, optionally creating users is disabled.
 func (t *Tenant) CreateUsers(madmClnt *madmin.AdminClient, userCredentialSecrets []*corev1.Secret, skipCreateUser bool) error {
 	// add user with a 20 seconds timeout
@@ -806,37 +1065,102 @@ func (t *Tenant) Validate() error {
 	}
 
 	// Every pool must contain a Volume Claim Template
+	poolNames := make(map[string]bool)
+	decommissioned := 0
 	for zi, pool := range t.Spec.Pools {
+		if pool.Decommission {
+			decommissioned++
+		}
 		if err := pool.Validate(zi); err != nil {
 			return err
 		}
+		// Pool names are used to name the statefulsets, they must be unique
+		if poolNames[pool.Name] {
+			return fmt.Errorf("pool #%d name '%s' is already used by another pool", zi, pool.Name)
+		}
+		poolNames[pool.Name] = true
+	}
+
+	// MinIO needs a pool to move the objects of decommissioned pools to
+	if decommissioned == len(t.Spec.Pools) {
+		return errors.New("at least one pool must not be decommissioned")
+	}
+
+	if t.UpgradeBakeTime() < 0 || t.UpgradeProgressDeadline() <= 0 {
+		return errors.New("upgradeStrategy bakeTime cannot be negative and progressDeadline must be positive")
+	}
+
+	if window := t.Spec.MaintenanceWindow; window != nil {
+		if _, err := ParseSchedule(window.Schedule); err != nil {
+			return fmt.Errorf("invalid maintenanceWindow schedule: %v", err)
+		}
+		if window.Duration.Duration <= 0 {
+			return errors.New("maintenanceWindow duration must be positive")
+		}
 	}
 
 	return nil
 }
 
-// Set up admin client to use self certificates
-func setUpInsecureTLS(api *madmin.AdminClient) *madmin.AdminClient {
-	// Keep TLS config.
-	tlsConfig := &tls.Config{
-		// Can't use SSLv3 because of POODLE and BEAST
-		// Can't use TLSv1.0 because of POODLE and BEAST using CBC cipher
-		// Can't use TLSv1.1 because of RC4 cipher usage
-		MinVersion:         tls.VersionTLS12,
-		InsecureSkipVerify: true,
+// validateVolumeClaimTemplateUpdate only allows the storage request of a volume claim template to grow,
+// the operator takes care of expanding the existing claims.
+func validateVolumeClaimTemplateUpdate(vct, oldVct *corev1.PersistentVolumeClaim) error {
+	if vct == nil || oldVct == nil {
+		if vct != oldVct {
+			return errors.New("cannot be modified")
+		}
+		return nil
+	}
+	size := vct.Spec.Resources.Requests.Storage()
+	oldSize := oldVct.Spec.Resources.Requests.Storage()
+	if size.Cmp(*oldSize) < 0 {
+		return fmt.Errorf("storage request cannot be decreased from %s to %s", oldSize, size)
+	}
+	// Anything but the storage request must stay the same
+	resized := oldVct.DeepCopy()
+	if resized.Spec.Resources.Requests == nil {
+		resized.Spec.Resources.Requests = corev1.ResourceList{}
+	}
+	resized.Spec.Resources.Requests[corev1.ResourceStorage] = *size
+	if vct.Spec.Resources.Requests.Storage().IsZero() {
+		delete(resized.Spec.Resources.Requests, corev1.ResourceStorage)
+	}
+	if !equality.Semantic.DeepEqual(vct, resized) {
+		return errors.New("cannot be modified other than increasing the storage request")
+	}
+	return nil
+}
+
+// ValidateUpdate returns an error if the changes from the old Tenant can't be applied to
+// the existing pools, both tenants are expected to have the defaults set.
+func (t *Tenant) ValidateUpdate(old *Tenant) error {
+	if len(t.Spec.Pools) < len(old.Spec.Pools) {
+		return errors.New("pools cannot be removed from a tenant, set decommission on the pool instead")
 	}
 
-	var transport http.RoundTripper = &http.Transport{
-		DialContext: (&net.Dialer{
-			Timeout:   10 * time.Second,
-			KeepAlive: 15 * time.Second,
-		}).DialContext,
-		TLSClientConfig: tlsConfig,
+	for zi, oldPool := range old.Spec.Pools {
+		pool := t.Spec.Pools[zi]
+		// Pools are addressed by position in the MinIO endpoints, they can't be swapped around
+		if pool.Name != oldPool.Name {
+			return fmt.Errorf("pool #%d '%s' cannot be renamed or reordered to '%s'", zi, oldPool.Name, pool.Name)
+		}
+		if pool.Servers != oldPool.Servers {
+			return fmt.Errorf("servers of pool '%s' cannot be modified", pool.Name)
+		}
+		if pool.VolumesPerServer != oldPool.VolumesPerServer {
+			return fmt.Errorf("volumesPerServer of pool '%s' cannot be modified", pool.Name)
+		}
+		if err := validateVolumeClaimTemplateUpdate(pool.VolumeClaimTemplate, oldPool.VolumeClaimTemplate); err != nil {
+			return fmt.Errorf("volumeClaimTemplate of pool '%s' %v", pool.Name, err)
+		}
+		// Once MinIO started moving objects out of the pool it can't go back to serve them
+		if !pool.Decommission && len(old.Status.Pools) > zi &&
+			(old.Status.Pools[zi].State == PoolDecommissioning || old.Status.Pools[zi].State == PoolDecommissioned) {
+			return fmt.Errorf("decommission of pool '%s' cannot be reverted", pool.Name)
+		}
 	}
 
-	// Set custom transport.
-	api.SetCustomTransport(transport)
-	return api
+	return nil
 }
 
 // OwnerRef returns the OwnerReference to be added to all resources created by Tenant
@@ -863,6 +1187,99 @@ func GetClusterDomain() string {
 	return k8sClusterDomain
 }
 
+// loadCertProviders reads the configuration of the certificate providers of the Operator
+func loadCertProviders() {
+	certProvidersOnce.Do(func() {
+		csrSignerName = envGet(csrSignerNameEnv, "")
+		operatorInternalCA = envGet(operatorInternalCAEnv, "off") == "on"
+		certRenewalFraction = DefaultCertRenewalFraction
+		if fraction, err := strconv.ParseFloat(envGet(certRenewalFractionEnv, ""), 64); err == nil && fraction > 0 && fraction < 1 {
+			certRenewalFraction = fraction
+		}
+		if issuer := envGet(operatorCertManagerIssuerEnv, ""); issuer != "" {
+			operatorCertIssuer = &CertManagerIssuerReference{
+				Name: issuer,
+				Kind: envGet(operatorCertManagerIssuerKindEnv, ""),
+			}
+		}
+	})
+}
+
+// parseRegistryMirrors parses a list of `registry=mirror` separated by commas
+func parseRegistryMirrors(value string) map[string]string {
+	mirrors := make(map[string]string)
+	for _, mapping := range strings.Split(value, ",") {
+		fields := strings.SplitN(strings.TrimSpace(mapping), "=", 2)
+		if len(fields) != 2 || fields[0] == "" || fields[1] == "" {
+			continue
+		}
+		mirrors[fields[0]] = strings.TrimSuffix(fields[1], "/")
+	}
+	return mirrors
+}
+
+// GetUpdateSources returns the sources of the MinIO binaries of the upgrades, configured with the environment of
+// the Operator
+func GetUpdateSources() UpdateSources {
+	updateSourcesOnce.Do(func() {
+		updateSources = UpdateSources{
+			MinisignPubKey:  envGet(minioUpdateMinisignPubKeyEnv, DefaultMinIOUpdateMinisignPubKey),
+			RegistryMirrors: parseRegistryMirrors(envGet(updateRegistryMirrorsEnv, "")),
+			RegistryCAFile:  envGet(updateRegistryCAFileEnv, ""),
+			RegistrySecret:  envGet(updateRegistrySecretEnv, ""),
+			OCILayoutPath:   envGet(updateOCILayoutPathEnv, ""),
+			ArtifactsPath:   envGet(updateArtifactsPathEnv, ""),
+		}
+	})
+	return updateSources
+}
+
+// GetCSRSignerName returns the signer of the CertificateSigningRequests created by the Operator. Empty
+// means the Kubernetes signers of serving and client certificates.
+func GetCSRSignerName() string {
+	loadCertProviders()
+	return csrSignerName
+}
+
+// GetOperatorCertManagerIssuer returns the cert-manager issuer of the Operator TLS certificate, nil when
+// the Operator requests it with a CertificateSigningRequest
+func GetOperatorCertManagerIssuer() *CertManagerIssuerReference {
+	loadCertProviders()
+	return operatorCertIssuer
+}
+
+// GetOperatorInternalCA returns true if the CA of the Operator signs the AutoCert certificates of the tenants
+// not issued by cert-manager, and the certificate of the Operator, instead of CertificateSigningRequests
+func GetOperatorInternalCA() bool {
+	loadCertProviders()
+	return operatorInternalCA
+}
+
+// GetCertRenewalFraction returns the fraction of the lifetime of a certificate issued with a CSR or the CA of
+// the Operator after which the Operator renews it
+func GetCertRenewalFraction() float64 {
+	loadCertProviders()
+	return certRenewalFraction
+}
+
+// IssuedCertificate returns the status of the certificate stored in the given secret, nil if it isn't tracked
+func (t *Tenant) IssuedCertificate(secretName string) *IssuedCertificate {
+	for i := range t.Status.Certificates.Issued {
+		if t.Status.Certificates.Issued[i].SecretName == secretName {
+			return &t.Status.Certificates.Issued[i]
+		}
+	}
+	return nil
+}
+
+// OperatorTLSKeys returns the keys of the certificate and private key in the Operator TLS secret
+func OperatorTLSKeys() (certKey, keyKey string) {
+	if GetOperatorCertManagerIssuer() != nil {
+		return TLSCertificateKey, TLSPrivateKeyKey
+	}
+	return CSRCertificateKey, CSRPrivateKeyKey
+}
+
 // MergeMaps merges two maps and returns the union
 func MergeMaps(a, b map[string]string) map[string]string {
 	for k, v := range b {
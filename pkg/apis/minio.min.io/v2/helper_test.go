@@ -2,10 +2,12 @@ package v2
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -221,3 +223,298 @@ func TestCompareEnvs(t *testing.T) {
 		})
 	}
 }
+
+func TestTenant_ValidateUpdate(t *testing.T) {
+	newPool := func(name string, servers, volumes int32, size string) Pool {
+		return Pool{
+			Name:             name,
+			Servers:          servers,
+			VolumesPerServer: volumes,
+			VolumeClaimTemplate: &corev1.PersistentVolumeClaim{
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceStorage: resource.MustParse(size),
+						},
+					},
+				},
+			},
+		}
+	}
+	old := Tenant{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant"},
+		Spec: TenantSpec{
+			CredsSecret: &corev1.LocalObjectReference{Name: "creds"},
+			Pools: []Pool{
+				newPool("pool-0", 4, 4, "1Gi"),
+				newPool("pool-1", 4, 4, "1Gi"),
+			},
+		},
+	}
+	tests := []struct {
+		name    string
+		pools   []Pool
+		wantErr bool
+	}{
+		{
+			name:  "unchanged",
+			pools: []Pool{newPool("pool-0", 4, 4, "1Gi"), newPool("pool-1", 4, 4, "1Gi")},
+		},
+		{
+			name:  "pool added",
+			pools: []Pool{newPool("pool-0", 4, 4, "1Gi"), newPool("pool-1", 4, 4, "1Gi"), newPool("pool-2", 8, 2, "2Gi")},
+		},
+		{
+			name:    "pool removed",
+			pools:   []Pool{newPool("pool-0", 4, 4, "1Gi")},
+			wantErr: true,
+		},
+		{
+			name:    "pools reordered",
+			pools:   []Pool{newPool("pool-1", 4, 4, "1Gi"), newPool("pool-0", 4, 4, "1Gi")},
+			wantErr: true,
+		},
+		{
+			name:    "servers changed",
+			pools:   []Pool{newPool("pool-0", 8, 4, "1Gi"), newPool("pool-1", 4, 4, "1Gi")},
+			wantErr: true,
+		},
+		{
+			name:    "volumes per server changed",
+			pools:   []Pool{newPool("pool-0", 4, 2, "1Gi"), newPool("pool-1", 4, 4, "1Gi")},
+			wantErr: true,
+		},
+		{
+			name:  "volume claim template storage grown",
+			pools: []Pool{newPool("pool-0", 4, 4, "1Gi"), newPool("pool-1", 4, 4, "2Gi")},
+		},
+		{
+			name:    "volume claim template storage shrunk",
+			pools:   []Pool{newPool("pool-0", 4, 4, "1Gi"), newPool("pool-1", 4, 4, "512Mi")},
+			wantErr: true,
+		},
+		{
+			name: "volume claim template changed",
+			pools: func() []Pool {
+				pool := newPool("pool-1", 4, 4, "2Gi")
+				pool.VolumeClaimTemplate.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
+				return []Pool{newPool("pool-0", 4, 4, "1Gi"), pool}
+			}(),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tenant := old.DeepCopy()
+			tenant.Spec.Pools = tt.pools
+			err := tenant.ValidateUpdate(&old)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateUpdate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("decommission can't be reverted", func(t *testing.T) {
+		decommissioning := old.DeepCopy()
+		decommissioning.Spec.Pools[0].Decommission = true
+		decommissioning.Status.Pools = []PoolStatus{
+			{SSName: "tenant-pool-0", State: PoolDecommissioning},
+			{SSName: "tenant-pool-1", State: PoolInitialized},
+		}
+		tenant := decommissioning.DeepCopy()
+		assert.NoError(t, tenant.ValidateUpdate(decommissioning))
+		tenant.Spec.Pools[0].Decommission = false
+		assert.Error(t, tenant.ValidateUpdate(decommissioning))
+	})
+
+	t.Run("all pools decommissioned", func(t *testing.T) {
+		tenant := old.DeepCopy()
+		tenant.Spec.Pools[0].Decommission = true
+		assert.NoError(t, tenant.Validate())
+		tenant.Spec.Pools[1].Decommission = true
+		assert.Error(t, tenant.Validate())
+	})
+
+	t.Run("duplicate pool names", func(t *testing.T) {
+		tenant := old.DeepCopy()
+		tenant.Spec.Pools = []Pool{newPool("pool-0", 4, 4, "1Gi"), newPool("pool-0", 4, 4, "1Gi")}
+		assert.Error(t, tenant.Validate())
+		assert.NoError(t, old.Validate())
+	})
+}
+
+func TestTenant_PoolMaxUnavailableServers(t *testing.T) {
+	tests := []struct {
+		name             string
+		servers          int32
+		volumesPerServer int32
+		env              []corev1.EnvVar
+		setDriveCount    int32
+		parity           int32
+		want             int32
+	}{
+		{
+			name:             "Single server",
+			servers:          1,
+			volumesPerServer: 4,
+			setDriveCount:    4,
+			parity:           2,
+			want:             0,
+		},
+		{
+			name:             "One drive per server",
+			servers:          4,
+			volumesPerServer: 1,
+			setDriveCount:    4,
+			parity:           2,
+			want:             1,
+		},
+		{
+			name:             "Set spread across all servers",
+			servers:          4,
+			volumesPerServer: 4,
+			setDriveCount:    16,
+			parity:           4,
+			want:             1,
+		},
+		{
+			name:             "Two drives of a set per server",
+			servers:          8,
+			volumesPerServer: 4,
+			setDriveCount:    16,
+			parity:           4,
+			want:             2,
+		},
+		{
+			name:             "Sixteen servers",
+			servers:          16,
+			volumesPerServer: 1,
+			setDriveCount:    16,
+			parity:           4,
+			want:             4,
+		},
+		{
+			name:             "Custom parity",
+			servers:          16,
+			volumesPerServer: 1,
+			env:              []corev1.EnvVar{{Name: "MINIO_STORAGE_CLASS_STANDARD", Value: "EC:8"}},
+			setDriveCount:    16,
+			parity:           8,
+			want:             7,
+		},
+		{
+			name:             "Custom set drive count",
+			servers:          16,
+			volumesPerServer: 1,
+			env:              []corev1.EnvVar{{Name: "MINIO_ERASURE_SET_DRIVE_COUNT", Value: "8"}},
+			setDriveCount:    8,
+			parity:           4,
+			want:             3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tenant := &Tenant{Spec: TenantSpec{Env: tt.env}}
+			pool := &Pool{Servers: tt.servers, VolumesPerServer: tt.volumesPerServer}
+			assert.Equal(t, tt.setDriveCount, tenant.PoolErasureSetDriveCount(pool))
+			assert.Equal(t, tt.parity, tenant.PoolParity(pool))
+			assert.Equal(t, tt.want, tenant.PoolMaxUnavailableServers(pool))
+		})
+	}
+}
+
+func TestTenant_ExternalURLs(t *testing.T) {
+	tests := []struct {
+		name   string
+		expose *ExposeServices
+		s3     *S3Features
+		want   *ExternalURLs
+	}{
+		{
+			name: "Not exposed",
+			want: nil,
+		},
+		{
+			name:   "Ingress without TLS",
+			expose: &ExposeServices{Ingress: &IngressConfig{MinIOHost: "s3.example.com", ConsoleHost: "console.example.com"}},
+			want:   &ExternalURLs{MinIO: "http://s3.example.com", Console: "http://console.example.com"},
+		},
+		{
+			name:   "Ingress with TLS and Bucket DNS",
+			expose: &ExposeServices{Ingress: &IngressConfig{MinIOHost: "s3.example.com", TLSSecret: "example-tls"}},
+			s3:     &S3Features{BucketDNS: true},
+			want:   &ExternalURLs{MinIO: "https://s3.example.com", BucketDNS: "https://*.s3.example.com"},
+		},
+		{
+			name: "Gateway",
+			expose: &ExposeServices{Gateway: &GatewayConfig{
+				ParentRef:   GatewayParentReference{Name: "gateway"},
+				MinIOHost:   "s3.example.com",
+				ConsoleHost: "console.example.com",
+				Insecure:    true,
+			}},
+			want: &ExternalURLs{MinIO: "http://s3.example.com", Console: "http://console.example.com"},
+		},
+		{
+			name: "Ingress takes precedence",
+			expose: &ExposeServices{
+				Ingress: &IngressConfig{MinIOHost: "s3.example.com"},
+				Gateway: &GatewayConfig{MinIOHost: "gw.example.com", ConsoleHost: "console.example.com", TLSPassthrough: true},
+			},
+			want: &ExternalURLs{MinIO: "http://s3.example.com", Console: "https://console.example.com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tenant := &Tenant{Spec: TenantSpec{ExposeServices: tt.expose, S3: tt.s3, Console: &ConsoleConfiguration{}}}
+			assert.Equal(t, tt.want, tenant.ExternalURLs())
+		})
+	}
+}
+
+func TestTenant_MinIOCIServiceName(t *testing.T) {
+	tenant := &Tenant{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a", Namespace: "ns"}}
+	assert.Equal(t, "tenant-a-minio", tenant.MinIOCIServiceName())
+	assert.Equal(t, "tenant-a-minio.ns", tenant.MinIOFQDNServiceNameAndNamespace())
+
+	tenant.Status.LegacyServiceName = true
+	assert.Equal(t, MinIOLegacyServiceName, tenant.MinIOCIServiceName())
+	assert.Equal(t, "minio.ns", tenant.MinIOFQDNServiceNameAndNamespace())
+}
+
+func TestTenant_Upgrades(t *testing.T) {
+	tenant := &Tenant{Spec: TenantSpec{Image: "minio/minio:new"}}
+	assert.Equal(t, InPlaceUpgradeStrategy, tenant.GetUpgradeStrategy())
+	assert.Equal(t, DefaultUpgradeBakeTime, tenant.UpgradeBakeTime())
+	assert.Nil(t, tenant.CurrentUpgrade())
+	assert.Nil(t, tenant.RolledBackUpgrade())
+
+	tenant.Spec.UpgradeStrategy = &UpgradeStrategy{Type: RollingUpgradeStrategy, BakeTime: &metav1.Duration{Duration: time.Minute}}
+	assert.Equal(t, RollingUpgradeStrategy, tenant.GetUpgradeStrategy())
+	assert.Equal(t, time.Minute, tenant.UpgradeBakeTime())
+	assert.Equal(t, DefaultUpgradeProgressDeadline, tenant.UpgradeProgressDeadline())
+
+	tenant.Status.UpgradeHistory = []UpgradeRecord{{From: "minio/minio:old", To: "minio/minio:new", Result: UpgradeInProgress}}
+	require.NotNil(t, tenant.CurrentUpgrade())
+	assert.Equal(t, "minio/minio:old", tenant.CurrentUpgrade().From)
+
+	now := metav1.Now()
+	tenant.Status.UpgradeHistory[0].EndTime = &now
+	tenant.Status.UpgradeHistory[0].Result = UpgradeRolledBack
+	assert.Nil(t, tenant.CurrentUpgrade())
+	assert.NotNil(t, tenant.RolledBackUpgrade())
+
+	// the upgrade is retried once the image changes
+	tenant.Spec.Image = "minio/minio:newer"
+	assert.Nil(t, tenant.RolledBackUpgrade())
+}
+
+func TestParseRegistryMirrors(t *testing.T) {
+	mirrors := parseRegistryMirrors("docker.io=registry.example.com:5000/docker/, quay.io=registry.example.com:5000,invalid,=empty")
+	assert.Equal(t, map[string]string{
+		"docker.io": "registry.example.com:5000/docker",
+		"quay.io":   "registry.example.com:5000",
+	}, mirrors)
+	assert.Empty(t, parseRegistryMirrors(""))
+}
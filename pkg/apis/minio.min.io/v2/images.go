@@ -0,0 +1,172 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package v2
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	imagePolicyOnce      sync.Once
+	imageRegistryRewrite map[string]string
+	imageAllowList       []string
+)
+
+// loadImagePolicy loads the registry rewrite rules and the allow-list of the images from the environment of the
+// Operator
+func loadImagePolicy() {
+	imagePolicyOnce.Do(func() {
+		imageRegistryRewrite = parseRegistryMirrors(envGet(imageRegistryRewriteEnv, ""))
+		for _, entry := range strings.Split(envGet(imageAllowListEnv, ""), ",") {
+			if entry = strings.TrimSuffix(strings.TrimSpace(entry), "/"); entry != "" {
+				imageAllowList = append(imageAllowList, entry)
+			}
+		}
+	})
+}
+
+// normalizeImage returns the image with its registry, `docker.io` when omitted, and the `library` repository of
+// the official Docker Hub images
+func normalizeImage(image string) string {
+	i := strings.Index(image, "/")
+	if i < 0 {
+		return "docker.io/library/" + image
+	}
+	registry := image[:i]
+	if registry == "index.docker.io" {
+		return "docker.io" + image[i:]
+	}
+	if strings.ContainsAny(registry, ".:") || registry == "localhost" {
+		return image
+	}
+	return "docker.io/" + image
+}
+
+// hasImagePrefix returns whether the normalized image is in the registry or repository of the prefix
+func hasImagePrefix(image, prefix string) bool {
+	if !strings.HasPrefix(image, prefix) {
+		return false
+	}
+	rest := image[len(prefix):]
+	return rest == "" || strings.ContainsAny(rest[:1], "/:@")
+}
+
+// rewriteImage replaces the longest registry or repository prefix of the image matching a rule, the image is
+// returned as is when no rule matches
+func rewriteImage(image string, rules map[string]string) string {
+	if image == "" || len(rules) == 0 {
+		return image
+	}
+	normalized := normalizeImage(image)
+	longest := ""
+	for prefix := range rules {
+		if len(prefix) > len(longest) && hasImagePrefix(normalized, prefix) {
+			longest = prefix
+		}
+	}
+	if longest == "" {
+		return image
+	}
+	return rules[longest] + normalized[len(longest):]
+}
+
+// imageAllowed returns whether the image is in a registry or repository of the allow-list, or its digest is in the
+// allow-list. Every image is allowed with an empty allow-list.
+func imageAllowed(image string, allowList []string) bool {
+	if len(allowList) == 0 {
+		return true
+	}
+	normalized := normalizeImage(image)
+	for _, entry := range allowList {
+		if strings.HasPrefix(entry, "sha256:") {
+			if strings.HasSuffix(image, "@"+entry) {
+				return true
+			}
+			continue
+		}
+		if hasImagePrefix(normalized, entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// RewriteImage returns the image the pods run, with the registry rewrite rules of the Operator applied
+func RewriteImage(image string) string {
+	loadImagePolicy()
+	return rewriteImage(image, imageRegistryRewrite)
+}
+
+// ImageAllowed returns whether the allow-list of the Operator allows the image
+func ImageAllowed(image string) bool {
+	loadImagePolicy()
+	return imageAllowed(image, imageAllowList)
+}
+
+// Images returns the images the pods of the tenant run, with the registry rewrite rules of the Operator applied
+func (t *Tenant) Images() []string {
+	images := []string{t.Spec.Image}
+	if t.HasConsoleEnabled() {
+		images = append(images, t.Spec.Console.Image)
+	}
+	if t.HasKESEnabled() {
+		images = append(images, t.Spec.KES.Image)
+	}
+	if t.HasLogEnabled() {
+		logImage, dbImage := DefaultLogSearchAPIImage, LogPgImage
+		if t.Spec.Log.Image != "" {
+			logImage = t.Spec.Log.Image
+		}
+		if t.Spec.Log.Db != nil && t.Spec.Log.Db.Image != "" {
+			dbImage = t.Spec.Log.Db.Image
+		}
+		images = append(images, logImage, dbImage)
+	}
+	if t.HasPrometheusEnabled() {
+		images = append(images, t.Spec.Prometheus.Image, t.Spec.Prometheus.SideCarImage, t.Spec.Prometheus.InitImage)
+	}
+	if t.Spec.SideCars != nil {
+		for _, container := range t.Spec.SideCars.Containers {
+			images = append(images, container.Image)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var rewritten []string
+	for _, image := range images {
+		if image = RewriteImage(image); image != "" && !seen[image] {
+			seen[image] = true
+			rewritten = append(rewritten, image)
+		}
+	}
+	sort.Strings(rewritten)
+	return rewritten
+}
+
+// DisallowedImages returns the images the pods of the tenant run the allow-list of the Operator doesn't allow
+func (t *Tenant) DisallowedImages() []string {
+	var disallowed []string
+	for _, image := range t.Images() {
+		if !ImageAllowed(image) {
+			disallowed = append(disallowed, image)
+		}
+	}
+	return disallowed
+}
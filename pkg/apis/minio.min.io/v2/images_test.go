@@ -0,0 +1,54 @@
+package v2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestRewriteImage(t *testing.T) {
+	rules := map[string]string{
+		"docker.io":       "registry.example.com/docker",
+		"docker.io/minio": "registry.example.com/minio",
+		"quay.io":         "registry.example.com/quay",
+	}
+	tests := map[string]string{
+		"minio/minio:RELEASE.2021-06-17T00-10-46Z":                "registry.example.com/minio/minio:RELEASE.2021-06-17T00-10-46Z",
+		"docker.io/minio/console:v0.7.4":                          "registry.example.com/minio/console:v0.7.4",
+		"index.docker.io/minio/console:v0.7.4":                    "registry.example.com/minio/console:v0.7.4",
+		"library/postgres":                                        "registry.example.com/docker/library/postgres",
+		"busybox":                                                 "registry.example.com/docker/library/busybox",
+		"minioadmin/tool:1":                                       "registry.example.com/docker/minioadmin/tool:1",
+		"quay.io/prometheus/prometheus:latest":                    "registry.example.com/quay/prometheus/prometheus:latest",
+		"quay.io.example.com/prometheus:latest":                   "quay.io.example.com/prometheus:latest",
+		"gcr.io/minio/minio@sha256:0123456789abcdef":              "gcr.io/minio/minio@sha256:0123456789abcdef",
+		"localhost:5000/minio/minio:RELEASE.2021-06-17T00-10-46Z": "localhost:5000/minio/minio:RELEASE.2021-06-17T00-10-46Z",
+		"": "",
+	}
+	for image, want := range tests {
+		assert.Equal(t, want, rewriteImage(image, rules), image)
+	}
+	assert.Equal(t, "minio/minio:edge", rewriteImage("minio/minio:edge", nil))
+}
+
+func TestImageAllowed(t *testing.T) {
+	allowList := []string{"registry.example.com", "docker.io/minio", "sha256:0123456789abcdef"}
+	assert.True(t, imageAllowed("registry.example.com/minio/minio:RELEASE.2021-06-17T00-10-46Z", allowList))
+	assert.True(t, imageAllowed("minio/console:v0.7.4", allowList))
+	assert.True(t, imageAllowed("gcr.io/minio/minio@sha256:0123456789abcdef", allowList))
+	assert.False(t, imageAllowed("registry.example.com.evil.io/minio/minio:latest", allowList))
+	assert.False(t, imageAllowed("minioadmin/tool:1", allowList))
+	assert.False(t, imageAllowed("library/postgres", allowList))
+	assert.True(t, imageAllowed("library/postgres", nil))
+}
+
+func TestTenant_Images(t *testing.T) {
+	tenant := &Tenant{Spec: TenantSpec{
+		Image:    "minio/minio:RELEASE.2021-06-17T00-10-46Z",
+		Log:      &LogConfig{},
+		SideCars: &SideCars{Containers: []corev1.Container{{Name: "warp", Image: "minio/minio:RELEASE.2021-06-17T00-10-46Z"}}},
+	}}
+	assert.Equal(t, []string{LogPgImage, DefaultLogSearchAPIImage, "minio/minio:RELEASE.2021-06-17T00-10-46Z"}, tenant.Images())
+	assert.Empty(t, tenant.DisallowedImages())
+}
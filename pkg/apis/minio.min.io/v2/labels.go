@@ -39,6 +39,13 @@ func (t *Tenant) KESPodLabels() map[string]string {
 	return m
 }
 
+// KESJobPodLabels returns the default labels for the pod of the Job creating the MinIO key on KES
+func (t *Tenant) KESJobPodLabels() map[string]string {
+	m := make(map[string]string, 1)
+	m[KESJobLabel] = t.KESJobName()
+	return m
+}
+
 // LogPgPodLabels returns the default labels for Log Postgres server pods
 func (t *Tenant) LogPgPodLabels() map[string]string {
 	m := make(map[string]string, 1)
@@ -47,6 +47,10 @@ const LogSearchAPIContainerName = "log-search-api"
 // PrometheusContainerName is the name of the prometheus server container
 const PrometheusContainerName = "prometheus"
 
+// MinIOLegacyServiceName is the name of the MinIO service of the tenants created before several tenants could
+// share a namespace
+const MinIOLegacyServiceName = "minio"
+
 // InitContainerImage name for init container.
 const InitContainerImage = "busybox:1.32"
 
@@ -82,9 +86,23 @@ func (t *Tenant) MinIOHLServiceName() string {
 // MinIOCIServiceName returns the name of Cluster IP service that is created to communicate
 // with current MinIO StatefulSet pods
 func (t *Tenant) MinIOCIServiceName() string {
-	// DO NOT CHANGE, this should be constant
-	// This is possible because each namespace has only one Tenant
-	return "minio"
+	// DO NOT CHANGE, clients of existing tenants resolve this name
+	if t.Status.LegacyServiceName {
+		return MinIOLegacyServiceName
+	}
+	return fmt.Sprintf("%s-%s", t.Name, MinIOServerName)
+}
+
+// WebhookSecretName returns the name of the Secret holding the credentials MinIO uses to call the Operator webhook,
+// tenants created before several tenants could share a namespace used the WebhookSecret of the namespace
+func (t *Tenant) WebhookSecretName() string {
+	return fmt.Sprintf("%s-%s", t.Name, WebhookSecret)
+}
+
+// OperatorTLSSecretCopyName returns the name of the Secret holding the copy of the Operator certificate
+// trusted by MinIO, tenants created before several tenants could share a namespace used OperatorTLSSecretName
+func (t *Tenant) OperatorTLSSecretCopyName() string {
+	return fmt.Sprintf("%s-%s", t.Name, OperatorTLSSecretName)
 }
 
 // MinIOBucketBaseDomain returns the base domain name for buckets
@@ -178,6 +196,16 @@ func (t *Tenant) ConsoleDeploymentName() string {
 	return t.Name + ConsoleName
 }
 
+// MinIOExternalName returns the name of the Ingress or Gateway API route exposing the MinIO service
+func (t *Tenant) MinIOExternalName() string {
+	return fmt.Sprintf("%s-%s", t.Name, MinIOServerName)
+}
+
+// ConsoleExternalName returns the name of the Ingress or Gateway API route exposing the Console service
+func (t *Tenant) ConsoleExternalName() string {
+	return t.ConsoleCIServiceName()
+}
+
 // ConsoleCIServiceName returns the name for Console Cluster IP Service
 func (t *Tenant) ConsoleCIServiceName() string {
 	return t.Name + ConsoleName
@@ -258,6 +286,12 @@ func (t *Tenant) PrometheusServiceMonitorName() string {
 	return fmt.Sprintf("%s-%s", t.Name, "prometheus")
 }
 
+// CABundleConfigMapName returns the name of the config map publishing the CA of the Operator signing the
+// certificates of the tenant
+func (t *Tenant) CABundleConfigMapName() string {
+	return fmt.Sprintf("%s-%s", t.Name, "ca-bundle")
+}
+
 // PrometheusConfigMapName returns name of the config map for Prometheus.
 func (t *Tenant) PrometheusConfigMapName() string {
 	return fmt.Sprintf("%s-%s", t.Name, "prometheus-config-map")
@@ -0,0 +1,172 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package v2
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a cron schedule, the minutes, hours, days of the month, months and days of the week it matches are
+// kept as bit sets
+type Schedule struct {
+	minutes, hours, daysOfMonth, months, daysOfWeek uint64
+	// a day matches either the days of the month or the days of the week when both are restricted
+	anyDayOfMonth, anyDayOfWeek bool
+}
+
+// ParseSchedule parses a cron schedule of 5 fields: minute, hour, day of the month, month and day of the week,
+// in UTC. Each field is `*`, a value, a range like `1-5` or a list of them like `1,15`, optionally followed by a
+// step like `*/15`. Sunday is either 0 or 7.
+func ParseSchedule(spec string) (*Schedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%q must have 5 fields: minute, hour, day of month, month and day of week", spec)
+	}
+	var s Schedule
+	var err error
+	if s.minutes, err = parseScheduleField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("minute %v", err)
+	}
+	if s.hours, err = parseScheduleField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("hour %v", err)
+	}
+	if s.daysOfMonth, err = parseScheduleField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("day of month %v", err)
+	}
+	if s.months, err = parseScheduleField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("month %v", err)
+	}
+	if s.daysOfWeek, err = parseScheduleField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("day of week %v", err)
+	}
+	if s.daysOfWeek&(1<<7) != 0 {
+		s.daysOfWeek |= 1
+	}
+	s.anyDayOfMonth = fields[2] == "*"
+	s.anyDayOfWeek = fields[4] == "*"
+	return &s, nil
+}
+
+// parseScheduleField returns the bit set of the values a field of a cron schedule matches
+func parseScheduleField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, fmt.Errorf("%q has an invalid step", field)
+			}
+			part = part[:i]
+		}
+		low, high := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if low, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("%q is not a number", field)
+			}
+			high = low
+			if len(bounds) == 2 {
+				if high, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, fmt.Errorf("%q is not a number", field)
+				}
+			} else if step > 1 {
+				// `5/15` starts at 5 and runs up to the maximum
+				high = max
+			}
+		}
+		if low < min || high > max || low > high {
+			return 0, fmt.Errorf("%q is out of the range %d-%d", field, min, max)
+		}
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// dayMatches returns whether the schedule matches the day of t
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dayOfMonth := s.daysOfMonth&(1<<uint(t.Day())) != 0
+	dayOfWeek := s.daysOfWeek&(1<<uint(t.Weekday())) != 0
+	if s.anyDayOfMonth || s.anyDayOfWeek {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
+
+// Next returns the first minute after t the schedule matches, in UTC. It returns the zero time if the schedule
+// doesn't match within 5 years, like `0 0 31 2 *`.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case s.months&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case s.hours&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, time.UTC)
+		case s.minutes&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// maintenanceSchedule returns the schedule of the maintenance window of the tenant, nil without a window or with
+// an invalid schedule
+func (t *Tenant) maintenanceSchedule() *Schedule {
+	if t.Spec.MaintenanceWindow == nil {
+		return nil
+	}
+	schedule, err := ParseSchedule(t.Spec.MaintenanceWindow.Schedule)
+	if err != nil {
+		return nil
+	}
+	return schedule
+}
+
+// MaintenanceWindowOpen returns whether the changes restarting the MinIO pods can be applied at the time, which
+// is always the case without a maintenance window
+func (t *Tenant) MaintenanceWindowOpen(now time.Time) bool {
+	schedule := t.maintenanceSchedule()
+	if schedule == nil {
+		return true
+	}
+	// the last window opened after now - duration is still open
+	start := schedule.Next(now.Add(-t.Spec.MaintenanceWindow.Duration.Duration))
+	return !start.IsZero() && !start.After(now)
+}
+
+// NextMaintenanceWindow returns the time the next maintenance window opens after now, the zero time without a
+// maintenance window
+func (t *Tenant) NextMaintenanceWindow(now time.Time) time.Time {
+	schedule := t.maintenanceSchedule()
+	if schedule == nil {
+		return time.Time{}
+	}
+	return schedule.Next(now)
+}
@@ -0,0 +1,62 @@
+package v2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseSchedule(t *testing.T) {
+	for _, spec := range []string{"* * * * *", "0 2 * * 6", "*/15 1-5 1,15 * 1-5", "30 4 * * 7", "5/10 * * * *"} {
+		_, err := ParseSchedule(spec)
+		assert.NoError(t, err, spec)
+	}
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "*/0 * * * *", "a * * * *", "5-1 * * * *"} {
+		_, err := ParseSchedule(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	// Thursday
+	now := time.Date(2021, time.June, 3, 10, 30, 20, 0, time.UTC)
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2021, time.June, 3, 10, 31, 0, 0, time.UTC)},
+		{"0 2 * * 6", time.Date(2021, time.June, 5, 2, 0, 0, 0, time.UTC)},
+		{"30 4 * * 0", time.Date(2021, time.June, 6, 4, 30, 0, 0, time.UTC)},
+		{"30 4 * * 7", time.Date(2021, time.June, 6, 4, 30, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2021, time.June, 3, 10, 45, 0, 0, time.UTC)},
+		{"0 0 1 1 *", time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		// either the day of month or the day of week matches when both are restricted
+		{"0 0 10 * 5", time.Date(2021, time.June, 4, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, schedule.Next(now))
+		})
+	}
+}
+
+func TestTenant_MaintenanceWindow(t *testing.T) {
+	tenant := &Tenant{}
+	now := time.Date(2021, time.June, 5, 1, 0, 0, 0, time.UTC)
+	assert.True(t, tenant.MaintenanceWindowOpen(now))
+	assert.True(t, tenant.NextMaintenanceWindow(now).IsZero())
+
+	tenant.Spec.MaintenanceWindow = &MaintenanceWindow{Schedule: "0 2 * * 6", Duration: metav1.Duration{Duration: 2 * time.Hour}}
+	assert.False(t, tenant.MaintenanceWindowOpen(now))
+	assert.Equal(t, time.Date(2021, time.June, 5, 2, 0, 0, 0, time.UTC), tenant.NextMaintenanceWindow(now))
+	assert.True(t, tenant.MaintenanceWindowOpen(now.Add(time.Hour)))
+	assert.True(t, tenant.MaintenanceWindowOpen(now.Add(2*time.Hour+59*time.Minute)))
+	assert.False(t, tenant.MaintenanceWindowOpen(now.Add(3*time.Hour)))
+}
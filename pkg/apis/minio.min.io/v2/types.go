@@ -20,7 +20,9 @@ package v2
 import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // +genclient
@@ -99,6 +101,16 @@ type TenantSpec struct {
 	// Pod Management Policy for pod created by StatefulSet
 	// +optional
 	PodManagementPolicy appsv1.PodManagementPolicyType `json:"podManagementPolicy,omitempty"`
+	// *Optional* +
+	//
+	// How the MinIO pods are upgraded when `spec.image` changes, MinIO updates its binary in place by default. +
+	// +optional
+	UpgradeStrategy *UpgradeStrategy `json:"upgradeStrategy,omitempty"`
+	// *Optional* +
+	//
+	// Window the changes restarting the MinIO pods are applied in, like a new `spec.image` or new resources or affinity of a pool. The changes are deferred until the window opens, they are applied right away without a window. +
+	// +optional
+	MaintenanceWindow *MaintenanceWindow `json:"maintenanceWindow,omitempty"`
 	// *Required* +
 	//
 	// Specify a https://kubernetes.io/docs/concepts/configuration/secret/[Kubernetes opaque secret] to use for setting the MinIO root access key and secret key. Specify the secret as `name: <secret>`. The Kubernetes secret must contain the following fields: +
@@ -117,6 +129,21 @@ type TenantSpec struct {
 	Env []corev1.EnvVar `json:"env,omitempty"`
 	// *Optional* +
 	//
+	// Liveness probe of the MinIO containers, Kubernetes restarts a MinIO server failing it. Defaults to an HTTP probe of `/minio/health/live`. +
+	// +optional
+	Liveness *corev1.Probe `json:"liveness,omitempty"`
+	// *Optional* +
+	//
+	// Readiness probe of the MinIO containers, the services only route requests to MinIO servers passing it. Defaults to an HTTP probe of `/minio/health/ready`. +
+	// +optional
+	Readiness *corev1.Probe `json:"readiness,omitempty"`
+	// *Optional* +
+	//
+	// Startup probe of the MinIO containers, the liveness and readiness probes only start once it succeeds. Defaults to an HTTP probe of `/minio/health/live` allowing MinIO 10 minutes to start. +
+	// +optional
+	Startup *corev1.Probe `json:"startup,omitempty"`
+	// *Optional* +
+	//
 	// Enables TLS with SNI support on each MinIO pod in the tenant. If `externalCertSecret` is omitted *and* `requestAutoCert` is set to `false`, the MinIO Tenant deploys *without* TLS enabled. +
 	//
 	// Specify an array of https://kubernetes.io/docs/concepts/configuration/secret/[Kubernetes TLS secrets]. The MinIO Operator copies the specified certificates to every MinIO server pod in the tenant. When the MinIO pod/service responds to a TLS connection request, it uses SNI to select the certificate with matching `subjectAlternativeName`. +
@@ -188,6 +215,13 @@ type TenantSpec struct {
 	RequestAutoCert *bool `json:"requestAutoCert,omitempty"`
 	// *Optional* +
 	//
+	// Disables the verification of the MinIO TLS certificates when the Operator connects to the tenant. *Only* use it in lab environments. +
+	//
+	// The Operator otherwise trusts the Kubernetes cluster CA, the CA of the automatically generated certificates, the Certificate Authorities in `externalCaCertSecret` and the certificates in `externalCertSecret`, and verifies the certificates are valid for the MinIO service hostname.
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+	// *Optional* +
+	//
 	// S3 related features can be disabled or enabled such as `bucketDNS` etc.
 	S3 *S3Features `json:"s3,omitempty"`
 	// *Optional* +
@@ -289,6 +323,16 @@ type TenantSpec struct {
 	// Enable JSON, Anonymous logging for MinIO tenants.
 	// +optional
 	Logging *Logging `json:"logging,omitempty"`
+	// *Optional* +
+	//
+	// Configures the PodDisruptionBudget the Operator maintains for every pool. +
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetConfig `json:"podDisruptionBudget,omitempty"`
+	// *Optional* +
+	//
+	// Directs the Operator to create NetworkPolicies only allowing the traffic the components of the tenant need, along with the clients listed. +
+	// +optional
+	NetworkPolicy *NetworkPolicyConfig `json:"networkPolicy,omitempty"`
 }
 
 // Logging describes Logging for MinIO tenants.
@@ -298,6 +342,43 @@ type Logging struct {
 	Quiet     bool `json:"quiet,omitempty"`
 }
 
+// PodDisruptionBudgetConfig (`podDisruptionBudget`) defines the PodDisruptionBudget created for every pool of the tenant. +
+//
+// The `maxUnavailable` of each PodDisruptionBudget is the number of servers of the pool that can be evicted while every erasure set keeps its write quorum, as derived from the erasure set size and parity of the pool. +
+type PodDisruptionBudgetConfig struct {
+	// *Optional* +
+	//
+	// Set to `true` to not create PodDisruptionBudgets for the pools, removing any created before. +
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+	// *Optional* +
+	//
+	// Set to `true` to block every eviction while MinIO reports a server can't be taken down for maintenance, for example while drives are healing. The Operator checks `/minio/health/cluster?maintenance=true` every time it reconciles the tenant. +
+	// +optional
+	HealthGate bool `json:"healthGate,omitempty"`
+}
+
+// NetworkPolicyConfig (`networkPolicy`) defines the NetworkPolicies isolating the pods of the tenant. +
+//
+// MinIO pods accept connections from each other, the Console and Prometheus pods of the tenant and the Operator. KES only accepts connections from MinIO, PostgreSQL from the Log Search API, the Log Search API from MinIO, Console and the Operator and Prometheus from Console. +
+type NetworkPolicyConfig struct {
+	// *Optional* +
+	//
+	// Namespaces whose pods may connect to the MinIO and Console services, for example the namespace of an ingress controller. Matched with the `kubernetes.io/metadata.name` label. +
+	// +optional
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+	// *Optional* +
+	//
+	// IP ranges, in CIDR notation, allowed to connect to the MinIO and Console services. +
+	// +optional
+	AllowedCIDRs []string `json:"allowedCIDRs,omitempty"`
+	// *Optional* +
+	//
+	// Namespaces of the Prometheus servers scraping MinIO through the ServiceMonitor of `spec.prometheusOperator`. +
+	// +optional
+	PrometheusNamespaces []string `json:"prometheusNamespaces,omitempty"`
+}
+
 // ServiceMetadata (`serviceMetadata`) defines custom labels and annotations for the MinIO Object Storage service and/or MinIO Console service. +
 type ServiceMetadata struct {
 	// *Optional* +
@@ -346,6 +427,203 @@ type ExposeServices struct {
 	// Directs the Operator to expose the MinIO Console service. Defaults to `true`. +
 	// +optional
 	Console bool `json:"console,omitempty"`
+	// *Optional* +
+	//
+	// Directs the Operator to create Ingresses routing the given hostnames to the MinIO and Console services. +
+	// +optional
+	Ingress *IngressConfig `json:"ingress,omitempty"`
+	// *Optional* +
+	//
+	// Directs the Operator to create Gateway API routes attaching the MinIO and Console services to a Gateway. +
+	// +optional
+	Gateway *GatewayConfig `json:"gateway,omitempty"`
+}
+
+// IngressConfig (`ingress`) defines the Ingresses exposing the MinIO and Console services. +
+type IngressConfig struct {
+	// *Optional* +
+	//
+	// Hostname routed to the MinIO service. When `spec.s3.bucketDNS` is enabled the wildcard host `*.<minioHost>` is routed too. +
+	// +optional
+	MinIOHost string `json:"minioHost,omitempty"`
+	// *Optional* +
+	//
+	// Hostname routed to the Console service. +
+	// +optional
+	ConsoleHost string `json:"consoleHost,omitempty"`
+	// *Optional* +
+	//
+	// The name of the IngressClass implementing the Ingresses. Uses the default IngressClass of the cluster if not specified. +
+	// +optional
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+	// *Optional* +
+	//
+	// The name of the Kubernetes secret with the certificate served for the hostnames. The external URLs use `http` if not specified. +
+	// +optional
+	TLSSecret string `json:"tlsSecret,omitempty"`
+	// *Optional* +
+	//
+	// Annotations of the Ingresses, for example to tell the ingress controller the services serve HTTPS. +
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// GatewayConfig (`gateway`) defines the Gateway API routes exposing the MinIO and Console services. +
+type GatewayConfig struct {
+	// *Required* +
+	//
+	// The Gateway the routes attach to. +
+	ParentRef GatewayParentReference `json:"parentRef"`
+	// *Optional* +
+	//
+	// Hostname routed to the MinIO service. When `spec.s3.bucketDNS` is enabled the wildcard host `*.<minioHost>` is routed too. +
+	// +optional
+	MinIOHost string `json:"minioHost,omitempty"`
+	// *Optional* +
+	//
+	// Hostname routed to the Console service. +
+	// +optional
+	ConsoleHost string `json:"consoleHost,omitempty"`
+	// *Optional* +
+	//
+	// Create TLSRoutes passing the TLS connections through to MinIO and Console instead of HTTPRoutes. Requires TLS on the tenant and a Gateway listener in `Passthrough` mode. +
+	// +optional
+	TLSPassthrough bool `json:"tlsPassthrough,omitempty"`
+	// *Optional* +
+	//
+	// Set when the Gateway listener serves plain HTTP, so the external URLs of HTTPRoutes use `http`. +
+	// +optional
+	Insecure bool `json:"insecure,omitempty"`
+	// *Optional* +
+	//
+	// Annotations of the routes. +
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// GatewayParentReference (`parentRef`) identifies the Gateway, and optionally its listener, routes attach to. +
+type GatewayParentReference struct {
+	// *Required* +
+	//
+	// The name of the Gateway. +
+	Name string `json:"name"`
+	// *Optional* +
+	//
+	// The namespace of the Gateway. Defaults to the namespace of the tenant. +
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// *Optional* +
+	//
+	// The name of the Gateway listener. Routes attach to every listener accepting them if not specified. +
+	// +optional
+	SectionName string `json:"sectionName,omitempty"`
+}
+
+// UpgradeStrategyType is the way the MinIO pods of a tenant are upgraded
+type UpgradeStrategyType string
+
+const (
+	// InPlaceUpgradeStrategy has MinIO update its binary on every server at once, then restarts the pods with the new image
+	InPlaceUpgradeStrategy UpgradeStrategyType = "InPlace"
+	// RollingUpgradeStrategy restarts the pods with the new image one at a time, rolling back when MinIO loses its health
+	RollingUpgradeStrategy UpgradeStrategyType = "Rolling"
+)
+
+// UpgradeStrategy defines how the MinIO pods of a tenant are upgraded
+type UpgradeStrategy struct {
+	// *Optional* +
+	//
+	// Specify one of the following: +
+	//
+	// * `InPlace` (Default) - MinIO updates its binary on all the servers at once, then the pods restart with the new image +
+	//
+	// * `Rolling` - the pods restart with the new image one at a time, each pod waiting for the previous one to be ready and for the cluster to be healthy. The previous image is restored if the cluster health turns red or the upgrade stalls. +
+	// +kubebuilder:validation:Enum=InPlace;Rolling
+	// +optional
+	Type UpgradeStrategyType `json:"type,omitempty"`
+	// *Optional* +
+	//
+	// Time the cluster health of a `Rolling` upgrade is watched after every pod runs the new image, before the upgrade succeeds. Defaults to `5m`. +
+	// +optional
+	BakeTime *metav1.Duration `json:"bakeTime,omitempty"`
+	// *Optional* +
+	//
+	// Time a pod of a `Rolling` upgrade has to become ready and the cluster healthy before the upgrade is rolled back. Defaults to `10m`. +
+	// +optional
+	ProgressDeadline *metav1.Duration `json:"progressDeadline,omitempty"`
+}
+
+// MaintenanceWindow defines when the changes restarting the MinIO pods of a tenant are applied
+type MaintenanceWindow struct {
+	// *Required* +
+	//
+	// Cron schedule of the start of the window in UTC, with the minute, hour, day of month, month and day of week fields, for instance `0 2 * * 6` for Saturdays at 2:00. +
+	Schedule string `json:"schedule"`
+	// *Required* +
+	//
+	// Time the window stays open after it starts, for instance `2h`. +
+	Duration metav1.Duration `json:"duration"`
+}
+
+// UpgradeResult represents the outcome of an upgrade of the MinIO pods
+type UpgradeResult string
+
+const (
+	// UpgradeInProgress indicates the pods are being upgraded
+	UpgradeInProgress UpgradeResult = "InProgress"
+	// UpgradeRollingBack indicates the pods are being restored to the previous image
+	UpgradeRollingBack UpgradeResult = "RollingBack"
+	// UpgradeSucceeded indicates the pods run the new image
+	UpgradeSucceeded UpgradeResult = "Succeeded"
+	// UpgradeFailed indicates the upgrade failed without changing the pods
+	UpgradeFailed UpgradeResult = "Failed"
+	// UpgradeRolledBack indicates the pods were restored to the previous image, the upgrade is retried once `spec.image` changes
+	UpgradeRolledBack UpgradeResult = "RolledBack"
+	// UpgradeUpToDate indicates MinIO already ran the most recent version
+	UpgradeUpToDate UpgradeResult = "UpToDate"
+)
+
+// UpgradeRecord keeps track of an upgrade of the MinIO pods
+type UpgradeRecord struct {
+	// Image the pods ran before the upgrade
+	From string `json:"from"`
+	// Image the pods are upgraded to
+	To string `json:"to"`
+	// Strategy of the upgrade
+	Strategy UpgradeStrategyType `json:"strategy"`
+	// Time the upgrade started
+	StartTime metav1.Time `json:"startTime"`
+	// *Optional* +
+	//
+	// Time the upgrade ended, unset while it is in progress
+	// +nullable
+	EndTime *metav1.Time `json:"endTime,omitempty"`
+	// *Optional* +
+	//
+	// Time the last pod of a `Rolling` upgrade was restarted, or the rollback started
+	// +nullable
+	ProgressTime *metav1.Time `json:"progressTime,omitempty"`
+	// *Optional* +
+	//
+	// Time every pod of a `Rolling` upgrade ran the new image, the cluster health is watched for the bake time
+	// +nullable
+	BakeStartTime *metav1.Time `json:"bakeStartTime,omitempty"`
+	// Outcome of the upgrade
+	Result UpgradeResult `json:"result"`
+	// *Optional* +
+	//
+	// Details on the outcome of the upgrade
+	Message string `json:"message,omitempty"`
+}
+
+// ExternalURLs keeps track of the URLs the tenant is reachable at from outside the cluster
+type ExternalURLs struct {
+	// URL of the MinIO service
+	MinIO string `json:"minio,omitempty"`
+	// URL of the Console service
+	Console string `json:"console,omitempty"`
+	// URL of the buckets when `spec.s3.bucketDNS` is enabled, `*` standing for the bucket name
+	BucketDNS string `json:"bucketDNS,omitempty"`
 }
 
 // CertificateStatus keeps track of all the certificates managed by the operator
@@ -353,6 +631,28 @@ type CertificateStatus struct {
 	// AutoCertEnabled registers whether we know if the tenant has autocert enabled
 	// +nullable
 	AutoCertEnabled *bool `json:"autoCertEnabled,omitempty"`
+	// *Optional* +
+	//
+	// Validity of the AutoCert certificates issued for the tenant
+	// +nullable
+	Issued []IssuedCertificate `json:"issued,omitempty"`
+}
+
+// IssuedCertificate keeps track of the validity of a certificate issued by the operator
+type IssuedCertificate struct {
+	// Name of the secret storing the certificate
+	SecretName string `json:"secretName"`
+	// Serial number of the certificate, a new serial number rolls the pods mounting the certificate
+	SerialNumber string `json:"serialNumber"`
+	// Start of the validity of the certificate
+	NotBefore metav1.Time `json:"notBefore"`
+	// End of the validity of the certificate
+	NotAfter metav1.Time `json:"notAfter"`
+	// *Optional* +
+	//
+	// Time the operator renews the certificate, unset when cert-manager renews it
+	// +nullable
+	RenewalTime *metav1.Time `json:"renewalTime,omitempty"`
 }
 
 // PoolState represents the state of a pool
@@ -365,12 +665,84 @@ const (
 	PoolCreated PoolState = "PoolCreated"
 	// PoolInitialized indicates if a pool has been observed to be online
 	PoolInitialized PoolState = "PoolInitialized"
+	// PoolDecommissioning indicates MinIO is moving the objects of the pool to the remaining pools
+	PoolDecommissioning PoolState = "PoolDecommissioning"
+	// PoolDecommissioned indicates the pool is no longer part of the MinIO deployment
+	PoolDecommissioned PoolState = "PoolDecommissioned"
 )
 
 // PoolStatus keeps track of all the pools and their current state
 type PoolStatus struct {
 	SSName string    `json:"ssName"`
 	State  PoolState `json:"state"`
+	// *Optional* +
+	//
+	// Progress of the decommission of the pool as reported by MinIO
+	// +optional
+	Decommission *PoolDecommissionStatus `json:"decommission,omitempty"`
+	// *Optional* +
+	//
+	// Progress of the expansion of the volumes of the pool after the storage request of its `volumeClaimTemplate` grew
+	// +optional
+	VolumeExpansion *PoolVolumeExpansionStatus `json:"volumeExpansion,omitempty"`
+}
+
+// PoolVolumeExpansionStatus keeps track of the progress of the expansion of the volumes of a pool
+type PoolVolumeExpansionStatus struct {
+	// *Optional* +
+	//
+	// Storage requested for every volume of the pool
+	// +optional
+	RequestedSize string `json:"requestedSize,omitempty"`
+	// *Optional* +
+	//
+	// Number of Persistent Volume Claims of the pool
+	// +optional
+	TotalClaims int32 `json:"totalClaims,omitempty"`
+	// *Optional* +
+	//
+	// Number of Persistent Volume Claims of the pool already resized
+	// +optional
+	ResizedClaims int32 `json:"resizedClaims,omitempty"`
+	// *Optional* +
+	//
+	// Reason the expansion can't progress, if any
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// PoolDecommissionStatus keeps track of the progress of a pool decommission
+type PoolDecommissionStatus struct {
+	// *Optional* +
+	//
+	// Time the decommission was started
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// *Optional* +
+	//
+	// Used capacity of the pool in bytes when the decommission started
+	// +optional
+	StartSize int64 `json:"startSize,omitempty"`
+	// *Optional* +
+	//
+	// Used capacity of the pool in bytes
+	// +optional
+	CurrentSize int64 `json:"currentSize,omitempty"`
+	// *Optional* +
+	//
+	// Total capacity of the pool in bytes
+	// +optional
+	TotalSize int64 `json:"totalSize,omitempty"`
+	// *Optional* +
+	//
+	// Number of objects moved out of the pool
+	// +optional
+	ObjectsDecommissioned int64 `json:"objectsDecommissioned,omitempty"`
+	// *Optional* +
+	//
+	// Number of objects that failed to be moved out of the pool
+	// +optional
+	ObjectsDecommissionFailed int64 `json:"objectsDecommissionFailed,omitempty"`
 }
 
 // HealthStatus represents whether the tenant is healthy, with decreased service or offline
@@ -385,6 +757,116 @@ const (
 	HealthStatusRed HealthStatus = "red"
 )
 
+// ServerHealth reports the health of the drives of a MinIO server pod
+type ServerHealth struct {
+	// Endpoint of the server, `<host>:<port>`
+	Endpoint string `json:"endpoint"`
+	// *Optional* +
+	//
+	// Name of the pod running the server
+	Pod string `json:"pod,omitempty"`
+	// *Optional* +
+	//
+	// Name of the pool of the server
+	Pool string `json:"pool,omitempty"`
+	// *Optional* +
+	//
+	// Number of drives of the server online
+	DrivesOnline int32 `json:"drivesOnline,omitempty"`
+	// *Optional* +
+	//
+	// Number of drives of the server offline
+	DrivesOffline int32 `json:"drivesOffline,omitempty"`
+	// *Optional* +
+	//
+	// The drives of the server
+	// +nullable
+	Drives []DriveHealth `json:"drives,omitempty"`
+}
+
+// DriveHealth reports the health of a drive of a MinIO server pod
+type DriveHealth struct {
+	// Path of the drive in the pod
+	Path string `json:"path"`
+	// State of the drive reported by MinIO, `ok` when online
+	State string `json:"state"`
+	// *Optional* +
+	//
+	// Name of the PersistentVolumeClaim mounted at the path
+	PVC string `json:"pvc,omitempty"`
+	// Index of the erasure set of the drive in its pool, -1 until MinIO assigns it
+	Set int32 `json:"set"`
+	// *Optional* +
+	//
+	// Used capacity of the drive in bytes
+	UsedSpace int64 `json:"usedSpace,omitempty"`
+	// *Optional* +
+	//
+	// Total capacity of the drive in bytes
+	TotalSpace int64 `json:"totalSpace,omitempty"`
+	// *Optional* +
+	//
+	// Progress of the healing of the drive, only set while the drive heals
+	// +nullable
+	Healing *DriveHealing `json:"healing,omitempty"`
+}
+
+// DriveHealing reports the progress of the healing of a drive
+type DriveHealing struct {
+	// *Optional* +
+	//
+	// Time the healing started
+	// +nullable
+	Started *metav1.Time `json:"started,omitempty"`
+	// *Optional* +
+	//
+	// Time the healing progress was last reported
+	// +nullable
+	LastUpdate *metav1.Time `json:"lastUpdate,omitempty"`
+	// *Optional* +
+	//
+	// Number of objects healed
+	ObjectsHealed int64 `json:"objectsHealed,omitempty"`
+	// *Optional* +
+	//
+	// Number of objects that failed to heal
+	ObjectsFailed int64 `json:"objectsFailed,omitempty"`
+	// *Optional* +
+	//
+	// Number of bytes healed
+	BytesHealed int64 `json:"bytesHealed,omitempty"`
+	// *Optional* +
+	//
+	// Number of buckets healed
+	BucketsHealed int32 `json:"bucketsHealed,omitempty"`
+	// *Optional* +
+	//
+	// Number of buckets left to heal
+	BucketsQueued int32 `json:"bucketsQueued,omitempty"`
+}
+
+// Condition types reported in `status.conditions` of a Tenant
+const (
+	// TenantConditionReady indicates every enabled component of the tenant is provisioned and MinIO is online
+	TenantConditionReady = "Ready"
+	// TenantConditionProgressing indicates the operator is creating or updating resources of the tenant
+	TenantConditionProgressing = "Progressing"
+	// TenantConditionDegraded indicates the tenant could not be reconciled, the message carries the reason
+	TenantConditionDegraded = "Degraded"
+	// TenantConditionCertificatesReady indicates all the TLS certificates requested by the tenant are issued
+	TenantConditionCertificatesReady = "CertificatesReady"
+	// TenantConditionKESReady indicates the KES statefulset is provisioned
+	TenantConditionKESReady = "KESReady"
+	// TenantConditionConsoleReady indicates the Console deployment is provisioned
+	TenantConditionConsoleReady = "ConsoleReady"
+	// TenantConditionLogSearchReady indicates the Log Search database and API are provisioned
+	TenantConditionLogSearchReady = "LogSearchReady"
+	// TenantConditionPrometheusReady indicates the Prometheus statefulset and service monitor are provisioned
+	TenantConditionPrometheusReady = "PrometheusReady"
+	// TenantConditionUpgradeInProgress indicates a MinIO version update is being rolled out
+	TenantConditionUpgradeInProgress = "UpgradeInProgress"
+)
+
 // TenantStatus is the status for a Tenant resource
 type TenantStatus struct {
 	CurrentState      string `json:"currentState"`
@@ -417,6 +899,73 @@ type TenantStatus struct {
 	//
 	// Health State of the tenant
 	HealthStatus HealthStatus `json:"healthStatus,omitempty"`
+	// *Optional* +
+	//
+	// The MinIO server pods of the tenant with the health of their drives
+	// +nullable
+	Servers []ServerHealth `json:"servers,omitempty"`
+	// *Optional* +
+	//
+	// The `metadata.generation` of the Tenant last processed by the operator
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// *Optional* +
+	//
+	// HMAC of the root credentials in `spec.credsSecret` the MinIO pods run with, keyed with the `operator-hash-key` secret of the Operator
+	CredsHash string `json:"credsHash,omitempty"`
+	// *Optional* +
+	//
+	// HMAC of the root credentials being rolled out to the MinIO pods, only set while a rotation is in progress
+	PendingCredsHash string `json:"pendingCredsHash,omitempty"`
+	// *Optional* +
+	//
+	// ID of the `operator-hash-key` the `credsHash` and `pendingCredsHash` are keyed with, the hashes are recorded again without rotating the credentials when the key changes
+	CredsHashKeyID string `json:"credsHashKeyID,omitempty"`
+	// *Optional* +
+	//
+	// Time the last rotation of the root credentials completed
+	// +nullable
+	CredsRotationTime *metav1.Time `json:"credsRotationTime,omitempty"`
+	// *Optional* +
+	//
+	// HMAC of the credentials of the users in `spec.users` last created on MinIO, keyed with the `operator-hash-key` secret of the Operator
+	UsersHash string `json:"usersHash,omitempty"`
+	// *Optional* +
+	//
+	// ID of the `operator-hash-key` the `usersHash` is keyed with
+	UsersHashKeyID string `json:"usersHashKeyID,omitempty"`
+	// *Optional* +
+	//
+	// The latest upgrades of the MinIO pods, the most recent last
+	// +nullable
+	UpgradeHistory []UpgradeRecord `json:"upgradeHistory,omitempty"`
+	// *Optional* +
+	//
+	// Changes of the spec deferred until the maintenance window opens
+	// +nullable
+	PendingChanges []string `json:"pendingChanges,omitempty"`
+	// *Optional* +
+	//
+	// Time the next maintenance window opens, only set while changes are pending
+	// +nullable
+	NextMaintenanceWindow *metav1.Time `json:"nextMaintenanceWindow,omitempty"`
+	// *Optional* +
+	//
+	// URLs the tenant is reachable at through its Ingresses or Gateway API routes
+	// +nullable
+	ExternalURLs *ExternalURLs `json:"externalURLs,omitempty"`
+	// *Optional* +
+	//
+	// Set on tenants created before several tenants could share a namespace, their MinIO service keeps the `minio` name of the namespace
+	LegacyServiceName bool `json:"legacyServiceName,omitempty"`
+	// *Optional* +
+	//
+	// Conditions represent the latest observations of the tenant state
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
 // CertificateConfig (`certConfig`) defines controlling attributes associated to any TLS certificate automatically generated by the Operator as part of tenant creation. These fields have no effect if `spec.autoCert: false`.
@@ -433,6 +982,38 @@ type CertificateConfig struct {
 	//
 	// Specify one or more x.509 Subject Alternative Names (SAN) to associate to automatically generated TLS certificates. MinIO Server pods use SNI to determine which certificate to respond with based on the requested hostname.
 	DNSNames []string `json:"dnsNames,omitempty"`
+	// *Optional* +
+	//
+	// Issues the automatically generated TLS certificates with https://cert-manager.io[cert-manager] `Certificate` resources signed by the specified issuer instead of Kubernetes `CertificateSigningRequests`. cert-manager stores the certificates in secrets of type `kubernetes.io/tls` and renews them before they expire. +
+	// +optional
+	CertManager *CertManagerConfig `json:"certManager,omitempty"`
+}
+
+// CertManagerConfig (`certManager`) configures the cert-manager issuer signing the TLS certificates automatically generated for the tenant. +
+type CertManagerConfig struct {
+	// *Required* +
+	//
+	// The cert-manager `Issuer` or `ClusterIssuer` signing the certificates. An `Issuer` must be in the namespace of the tenant. +
+	IssuerRef CertManagerIssuerReference `json:"issuerRef"`
+}
+
+// CertManagerIssuerReference (`issuerRef`) identifies a cert-manager issuer. +
+type CertManagerIssuerReference struct {
+	// *Required* +
+	//
+	// The name of the issuer. +
+	Name string `json:"name"`
+	// *Optional* +
+	//
+	// The kind of the issuer, `Issuer` or `ClusterIssuer`. Defaults to `Issuer`. +
+	// +optional
+	// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	Kind string `json:"kind,omitempty"`
+	// *Optional* +
+	//
+	// The API group of the issuer. Defaults to `cert-manager.io`, external issuers use their own group. +
+	// +optional
+	Group string `json:"group,omitempty"`
 }
 
 // Pool (`pools`) defines a MinIO server pool on a Tenant. Each pool consists of a set of MinIO server pods which "pool" their storage resources for supporting object storage and retrieval requests. Each server pool is independent of all others and supports horizontal scaling of available storage resources in the MinIO Tenant. +
@@ -501,6 +1082,31 @@ type Pool struct {
 	//
 	// +optional
 	SecurityContext *corev1.PodSecurityContext `json:"securityContext,omitempty"`
+	// *Optional* +
+	//
+	// Liveness probe of the MinIO containers of the pool, overriding `spec.liveness`. +
+	// +optional
+	Liveness *corev1.Probe `json:"liveness,omitempty"`
+	// *Optional* +
+	//
+	// Readiness probe of the MinIO containers of the pool, overriding `spec.readiness`. +
+	// +optional
+	Readiness *corev1.Probe `json:"readiness,omitempty"`
+	// *Optional* +
+	//
+	// Startup probe of the MinIO containers of the pool, overriding `spec.startup`. +
+	// +optional
+	Startup *corev1.Probe `json:"startup,omitempty"`
+	// *Optional* +
+	//
+	// Directs the Operator to decommission the pool. MinIO moves all the objects stored in the pool to the remaining pools, once completed the pool is removed from the MinIO server arguments and the Operator deletes its StatefulSet. The pool entry must stay in `spec.pools` after it is decommissioned. A decommission cannot be reverted once started. +
+	// +optional
+	Decommission bool `json:"decommission,omitempty"`
+	// *Optional* +
+	//
+	// Directs the Operator to delete the Persistent Volume Claims of the pool once it is decommissioned. Defaults to `false`, the claims are retained. +
+	// +optional
+	DeletePVCsOnDecommission bool `json:"deletePVCsOnDecommission,omitempty"`
 }
 
 // ConsoleConfiguration (`console`) defines configuration of the https://github.com/minio/console[MinIO Console] deployed as part of the MinIO Tenant. The Operator automatically configures the Console for connectivity to MinIO server pods in the tenant. +
@@ -623,7 +1229,7 @@ type ConsoleConfiguration struct {
 
 // EqualImage returns true if config image and current input image are same
 func (c ConsoleConfiguration) EqualImage(currentImage string) bool {
-	return c.Image == currentImage
+	return RewriteImage(c.Image) == currentImage
 }
 
 // EqualImage returns true if image specified in `LogConfig` is equal to `image`
@@ -631,12 +1237,12 @@ func (lc *LogConfig) EqualImage(image string) bool {
 	if lc == nil {
 		return false
 	}
-	return lc.Image == image
+	return RewriteImage(lc.Image) == image
 }
 
 // EqualImage returns true if config image and current input image are same
 func (c KESConfig) EqualImage(currentImage string) bool {
-	return c.Image == currentImage
+	return RewriteImage(c.Image) == currentImage
 }
 
 // LogConfig (`log`) defines the configuration of the MinIO Log Search API deployed as part of the MinIO Tenant. The Operator deploys a PostgreSQL instance as part of the tenant to support storing and querying MinIO logs. +
@@ -1018,3 +1624,312 @@ type SideCars struct {
 	// +patchStrategy=merge,retainKeys
 	Volumes []corev1.Volume `json:"volumes,omitempty" patchStrategy:"merge,retainKeys" patchMergeKey:"name" protobuf:"bytes,1,rep,name=volumes"`
 }
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced,shortName=bucket,singular=bucket
+// +kubebuilder:printcolumn:name="Tenant",type="string",JSONPath=".spec.tenant.name"
+// +kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.currentState"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// Bucket is a https://kubernetes.io/docs/concepts/overview/working-with-objects/kubernetes-objects/[Kubernetes object] describing a bucket of a MinIO Tenant. +
+//
+// The Operator creates the bucket on the Tenant referenced in the same namespace and keeps its configuration in sync with the object.
+type Bucket struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// *Required* +
+	//
+	// The root field for the MinIO Bucket object.
+	Spec BucketSpec `json:"spec"`
+	// Status provides details of the state of the Bucket
+	// +optional
+	Status BucketStatus `json:"status,omitempty"`
+}
+
+// BucketDeletionPolicy decides what happens to a bucket in MinIO when its Bucket object is deleted
+type BucketDeletionPolicy string
+
+const (
+	// BucketDeletionPolicyRetain keeps the bucket and its objects in MinIO
+	BucketDeletionPolicyRetain BucketDeletionPolicy = "Retain"
+	// BucketDeletionPolicyDeleteIfEmpty removes the bucket from MinIO only if it holds no objects
+	BucketDeletionPolicyDeleteIfEmpty BucketDeletionPolicy = "DeleteIfEmpty"
+)
+
+// BucketSpec (`spec`) defines the configuration of a MinIO Bucket object. +
+type BucketSpec struct {
+	// *Required* +
+	//
+	// The Tenant in the namespace of the Bucket that hosts it.
+	Tenant corev1.LocalObjectReference `json:"tenant"`
+	// *Optional* +
+	//
+	// Name of the bucket in MinIO. Defaults to the name of the Bucket object and can't be changed once the bucket is created.
+	// +optional
+	Name string `json:"name,omitempty"`
+	// *Optional* +
+	//
+	// Region the bucket is created in.
+	// +optional
+	Region string `json:"region,omitempty"`
+	// *Optional* +
+	//
+	// Specify `Enabled` to keep every version of the objects or `Suspended` to stop versioning a previously versioned bucket. Buckets with object locking are always versioned.
+	// +kubebuilder:validation:Enum=Enabled;Suspended
+	// +optional
+	Versioning string `json:"versioning,omitempty"`
+	// *Optional* +
+	//
+	// Enables object locking on the bucket, optionally with a default retention for new objects. Object locking can only be enabled when the bucket is created.
+	// +optional
+	ObjectLock *BucketObjectLock `json:"objectLock,omitempty"`
+	// *Optional* +
+	//
+	// Hard limit on the amount of data the bucket can hold.
+	// +optional
+	Quota *BucketQuota `json:"quota,omitempty"`
+	// *Optional* +
+	//
+	// Default server side encryption applied to new objects.
+	// +optional
+	Encryption *BucketEncryption `json:"encryption,omitempty"`
+	// *Optional* +
+	//
+	// Access granted to anonymous clients: `none`, `download`, `upload` or `public`. Defaults to `none`.
+	// +kubebuilder:validation:Enum=none;download;upload;public
+	// +optional
+	AnonymousPolicy string `json:"anonymousPolicy,omitempty"`
+	// *Optional* +
+	//
+	// Tags set on the bucket.
+	// +optional
+	Tags map[string]string `json:"tags,omitempty"`
+	// *Optional* +
+	//
+	// What happens to the bucket when the Bucket object is deleted: `Retain` leaves it in MinIO, `DeleteIfEmpty` removes it if it holds no objects. Defaults to `Retain`.
+	// +kubebuilder:validation:Enum=Retain;DeleteIfEmpty
+	// +optional
+	DeletionPolicy BucketDeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// BucketObjectLock (`objectLock`) defines the object locking of a bucket
+type BucketObjectLock struct {
+	// *Optional* +
+	//
+	// Default retention mode of new objects, `GOVERNANCE` or `COMPLIANCE`. Leave empty to enable object locking without a default retention.
+	// +kubebuilder:validation:Enum=GOVERNANCE;COMPLIANCE
+	// +optional
+	Mode string `json:"mode,omitempty"`
+	// *Optional* +
+	//
+	// Duration of the default retention, in `unit`.
+	// +optional
+	Validity int32 `json:"validity,omitempty"`
+	// *Optional* +
+	//
+	// Unit of the default retention validity, `DAYS` or `YEARS`.
+	// +kubebuilder:validation:Enum=DAYS;YEARS
+	// +optional
+	Unit string `json:"unit,omitempty"`
+}
+
+// BucketQuota (`quota`) defines the quota of a bucket
+type BucketQuota struct {
+	// *Required* +
+	//
+	// Maximum amount of data the bucket can hold, for example `10Ti`.
+	Hard resource.Quantity `json:"hard"`
+}
+
+// BucketEncryption (`encryption`) defines the default server side encryption of a bucket
+type BucketEncryption struct {
+	// *Required* +
+	//
+	// Encryption algorithm, `AES256` for SSE-S3 or `aws:kms` for SSE-KMS.
+	// +kubebuilder:validation:Enum=AES256;"aws:kms"
+	Algorithm string `json:"algorithm"`
+	// *Optional* +
+	//
+	// Key used to encrypt the objects when the algorithm is `aws:kms`.
+	// +optional
+	KMSKeyID string `json:"kmsKeyID,omitempty"`
+}
+
+// BucketStatus is the status of a Bucket
+type BucketStatus struct {
+	// State of the reconciliation of the bucket
+	CurrentState string `json:"currentState,omitempty"`
+	// Details of the last error found reconciling the bucket
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Name of the bucket created in MinIO
+	// +optional
+	BucketName string `json:"bucketName,omitempty"`
+	// The generation of the Bucket last reconciled
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BucketList is a list of Bucket resources
+type BucketList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []Bucket `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced,singular=policy
+// +kubebuilder:printcolumn:name="Tenant",type="string",JSONPath=".spec.tenant.name"
+// +kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.currentState"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// Policy is a https://kubernetes.io/docs/concepts/overview/working-with-objects/kubernetes-objects/[Kubernetes object] describing a canned IAM policy of a MinIO Tenant. +
+//
+// The Operator adds the policy to the Tenant referenced in the same namespace, keeps its document in sync and removes it when the object is deleted.
+type Policy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// *Required* +
+	//
+	// The root field for the MinIO Policy object.
+	Spec PolicySpec `json:"spec"`
+	// Status provides details of the state of the Policy
+	// +optional
+	Status PolicyStatus `json:"status,omitempty"`
+}
+
+// PolicySpec (`spec`) defines the configuration of a MinIO Policy object. +
+type PolicySpec struct {
+	// *Required* +
+	//
+	// The Tenant in the namespace of the Policy that hosts it.
+	Tenant corev1.LocalObjectReference `json:"tenant"`
+	// *Optional* +
+	//
+	// Name of the policy in MinIO. Defaults to the name of the Policy object.
+	// +optional
+	Name string `json:"name,omitempty"`
+	// *Required* +
+	//
+	// The https://docs.min.io/minio/baremetal/security/minio-identity-management/policy-based-access-control.html[IAM policy document], with its `Version` and `Statement` fields.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Document runtime.RawExtension `json:"document"`
+}
+
+// PolicyStatus is the status of a Policy
+type PolicyStatus struct {
+	// State of the reconciliation of the policy
+	CurrentState string `json:"currentState,omitempty"`
+	// Details of the last error found reconciling the policy
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Name of the policy created in MinIO
+	// +optional
+	PolicyName string `json:"policyName,omitempty"`
+	// Differences found between MinIO and the spec on the last check, before they were corrected
+	// +optional
+	Drift []string `json:"drift,omitempty"`
+	// Last time the policy in MinIO was compared with the spec
+	// +optional
+	LastDriftCheck *metav1.Time `json:"lastDriftCheck,omitempty"`
+	// The generation of the Policy last reconciled
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PolicyList is a list of Policy resources
+type PolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []Policy `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced,singular=user
+// +kubebuilder:printcolumn:name="Tenant",type="string",JSONPath=".spec.tenant.name"
+// +kubebuilder:printcolumn:name="Access Key",type="string",JSONPath=".status.accessKey"
+// +kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.currentState"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// User is a https://kubernetes.io/docs/concepts/overview/working-with-objects/kubernetes-objects/[Kubernetes object] describing an IAM user of a MinIO Tenant. +
+//
+// The Operator adds the user to the Tenant referenced in the same namespace, keeps its credentials, policies and groups in sync and removes it when the object is deleted.
+type User struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// *Required* +
+	//
+	// The root field for the MinIO User object.
+	Spec UserSpec `json:"spec"`
+	// Status provides details of the state of the User
+	// +optional
+	Status UserStatus `json:"status,omitempty"`
+}
+
+// UserSpec (`spec`) defines the configuration of a MinIO User object. +
+type UserSpec struct {
+	// *Required* +
+	//
+	// The Tenant in the namespace of the User that hosts it.
+	Tenant corev1.LocalObjectReference `json:"tenant"`
+	// *Required* +
+	//
+	// Secret with the `accesskey` and `secretkey` of the user.
+	CredsSecret corev1.LocalObjectReference `json:"credsSecret"`
+	// *Optional* +
+	//
+	// Names of the policies attached to the user, either built-in MinIO policies or added with a Policy object.
+	// +optional
+	Policies []string `json:"policies,omitempty"`
+	// *Optional* +
+	//
+	// Names of the groups the user is a member of. Groups are created when their first member is added.
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+}
+
+// UserStatus is the status of a User
+type UserStatus struct {
+	// State of the reconciliation of the user
+	CurrentState string `json:"currentState,omitempty"`
+	// Details of the last error found reconciling the user
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Access key of the user created in MinIO
+	// +optional
+	AccessKey string `json:"accessKey,omitempty"`
+	// Differences found between MinIO and the spec on the last check, before they were corrected
+	// +optional
+	Drift []string `json:"drift,omitempty"`
+	// Last time the user in MinIO was compared with the spec
+	// +optional
+	LastDriftCheck *metav1.Time `json:"lastDriftCheck,omitempty"`
+	// The generation of the User last reconciled
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// UserList is a list of User resources
+type UserList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []User `json:"items"`
+}
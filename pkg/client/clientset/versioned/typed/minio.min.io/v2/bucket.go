@@ -0,0 +1,195 @@
+// This file is part of MinIO Operator
+// Copyright (c) 2020 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v2
+
+import (
+	"context"
+	"time"
+
+	v2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	scheme "github.com/minio/operator/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// BucketsGetter has a method to return a BucketInterface.
+// A group's client should implement this interface.
+type BucketsGetter interface {
+	Buckets(namespace string) BucketInterface
+}
+
+// BucketInterface has methods to work with Bucket resources.
+type BucketInterface interface {
+	Create(ctx context.Context, bucket *v2.Bucket, opts v1.CreateOptions) (*v2.Bucket, error)
+	Update(ctx context.Context, bucket *v2.Bucket, opts v1.UpdateOptions) (*v2.Bucket, error)
+	UpdateStatus(ctx context.Context, bucket *v2.Bucket, opts v1.UpdateOptions) (*v2.Bucket, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v2.Bucket, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v2.BucketList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v2.Bucket, err error)
+	BucketExpansion
+}
+
+// buckets implements BucketInterface
+type buckets struct {
+	client rest.Interface
+	ns     string
+}
+
+// newBuckets returns a Buckets
+func newBuckets(c *MinioV2Client, namespace string) *buckets {
+	return &buckets{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the bucket, and returns the corresponding bucket object, and an error if there is any.
+func (c *buckets) Get(ctx context.Context, name string, options v1.GetOptions) (result *v2.Bucket, err error) {
+	result = &v2.Bucket{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("buckets").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of Buckets that match those selectors.
+func (c *buckets) List(ctx context.Context, opts v1.ListOptions) (result *v2.BucketList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v2.BucketList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("buckets").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested buckets.
+func (c *buckets) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("buckets").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a bucket and creates it.  Returns the server's representation of the bucket, and an error, if there is any.
+func (c *buckets) Create(ctx context.Context, bucket *v2.Bucket, opts v1.CreateOptions) (result *v2.Bucket, err error) {
+	result = &v2.Bucket{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("buckets").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(bucket).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a bucket and updates it. Returns the server's representation of the bucket, and an error, if there is any.
+func (c *buckets) Update(ctx context.Context, bucket *v2.Bucket, opts v1.UpdateOptions) (result *v2.Bucket, err error) {
+	result = &v2.Bucket{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("buckets").
+		Name(bucket.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(bucket).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *buckets) UpdateStatus(ctx context.Context, bucket *v2.Bucket, opts v1.UpdateOptions) (result *v2.Bucket, err error) {
+	result = &v2.Bucket{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("buckets").
+		Name(bucket.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(bucket).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the bucket and deletes it. Returns an error if one occurs.
+func (c *buckets) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("buckets").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *buckets) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("buckets").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched bucket.
+func (c *buckets) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v2.Bucket, err error) {
+	result = &v2.Bucket{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("buckets").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -0,0 +1,142 @@
+// This file is part of MinIO Operator
+// Copyright (c) 2020 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeBuckets implements BucketInterface
+type FakeBuckets struct {
+	Fake *FakeMinioV2
+	ns   string
+}
+
+var bucketsResource = schema.GroupVersionResource{Group: "minio.min.io", Version: "v2", Resource: "buckets"}
+
+var bucketsKind = schema.GroupVersionKind{Group: "minio.min.io", Version: "v2", Kind: "Bucket"}
+
+// Get takes name of the bucket, and returns the corresponding bucket object, and an error if there is any.
+func (c *FakeBuckets) Get(ctx context.Context, name string, options v1.GetOptions) (result *v2.Bucket, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(bucketsResource, c.ns, name), &v2.Bucket{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2.Bucket), err
+}
+
+// List takes label and field selectors, and returns the list of Buckets that match those selectors.
+func (c *FakeBuckets) List(ctx context.Context, opts v1.ListOptions) (result *v2.BucketList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(bucketsResource, bucketsKind, c.ns, opts), &v2.BucketList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v2.BucketList{ListMeta: obj.(*v2.BucketList).ListMeta}
+	for _, item := range obj.(*v2.BucketList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested buckets.
+func (c *FakeBuckets) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(bucketsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a bucket and creates it.  Returns the server's representation of the bucket, and an error, if there is any.
+func (c *FakeBuckets) Create(ctx context.Context, bucket *v2.Bucket, opts v1.CreateOptions) (result *v2.Bucket, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(bucketsResource, c.ns, bucket), &v2.Bucket{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2.Bucket), err
+}
+
+// Update takes the representation of a bucket and updates it. Returns the server's representation of the bucket, and an error, if there is any.
+func (c *FakeBuckets) Update(ctx context.Context, bucket *v2.Bucket, opts v1.UpdateOptions) (result *v2.Bucket, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(bucketsResource, c.ns, bucket), &v2.Bucket{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2.Bucket), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeBuckets) UpdateStatus(ctx context.Context, bucket *v2.Bucket, opts v1.UpdateOptions) (*v2.Bucket, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(bucketsResource, "status", c.ns, bucket), &v2.Bucket{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2.Bucket), err
+}
+
+// Delete takes name of the bucket and deletes it. Returns an error if one occurs.
+func (c *FakeBuckets) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(bucketsResource, c.ns, name), &v2.Bucket{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeBuckets) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(bucketsResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v2.BucketList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched bucket.
+func (c *FakeBuckets) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v2.Bucket, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(bucketsResource, c.ns, name, pt, data, subresources...), &v2.Bucket{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2.Bucket), err
+}
@@ -28,10 +28,22 @@ type FakeMinioV2 struct {
 	*testing.Fake
 }
 
+func (c *FakeMinioV2) Buckets(namespace string) v2.BucketInterface {
+	return &FakeBuckets{c, namespace}
+}
+
+func (c *FakeMinioV2) Policies(namespace string) v2.PolicyInterface {
+	return &FakePolicies{c, namespace}
+}
+
 func (c *FakeMinioV2) Tenants(namespace string) v2.TenantInterface {
 	return &FakeTenants{c, namespace}
 }
 
+func (c *FakeMinioV2) Users(namespace string) v2.UserInterface {
+	return &FakeUsers{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeMinioV2) RESTClient() rest.Interface {
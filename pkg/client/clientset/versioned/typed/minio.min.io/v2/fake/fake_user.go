@@ -0,0 +1,142 @@
+// This file is part of MinIO Operator
+// Copyright (c) 2020 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeUsers implements UserInterface
+type FakeUsers struct {
+	Fake *FakeMinioV2
+	ns   string
+}
+
+var usersResource = schema.GroupVersionResource{Group: "minio.min.io", Version: "v2", Resource: "users"}
+
+var usersKind = schema.GroupVersionKind{Group: "minio.min.io", Version: "v2", Kind: "User"}
+
+// Get takes name of the user, and returns the corresponding user object, and an error if there is any.
+func (c *FakeUsers) Get(ctx context.Context, name string, options v1.GetOptions) (result *v2.User, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(usersResource, c.ns, name), &v2.User{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2.User), err
+}
+
+// List takes label and field selectors, and returns the list of Users that match those selectors.
+func (c *FakeUsers) List(ctx context.Context, opts v1.ListOptions) (result *v2.UserList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(usersResource, usersKind, c.ns, opts), &v2.UserList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v2.UserList{ListMeta: obj.(*v2.UserList).ListMeta}
+	for _, item := range obj.(*v2.UserList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested users.
+func (c *FakeUsers) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(usersResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a user and creates it.  Returns the server's representation of the user, and an error, if there is any.
+func (c *FakeUsers) Create(ctx context.Context, user *v2.User, opts v1.CreateOptions) (result *v2.User, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(usersResource, c.ns, user), &v2.User{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2.User), err
+}
+
+// Update takes the representation of a user and updates it. Returns the server's representation of the user, and an error, if there is any.
+func (c *FakeUsers) Update(ctx context.Context, user *v2.User, opts v1.UpdateOptions) (result *v2.User, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(usersResource, c.ns, user), &v2.User{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2.User), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeUsers) UpdateStatus(ctx context.Context, user *v2.User, opts v1.UpdateOptions) (*v2.User, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(usersResource, "status", c.ns, user), &v2.User{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2.User), err
+}
+
+// Delete takes name of the user and deletes it. Returns an error if one occurs.
+func (c *FakeUsers) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(usersResource, c.ns, name), &v2.User{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeUsers) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(usersResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v2.UserList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched user.
+func (c *FakeUsers) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v2.User, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(usersResource, c.ns, name, pt, data, subresources...), &v2.User{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2.User), err
+}
@@ -18,4 +18,10 @@
 
 package v2
 
+type BucketExpansion interface{}
+
+type PolicyExpansion interface{}
+
 type TenantExpansion interface{}
+
+type UserExpansion interface{}
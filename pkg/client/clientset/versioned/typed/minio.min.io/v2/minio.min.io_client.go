@@ -26,7 +26,10 @@ import (
 
 type MinioV2Interface interface {
 	RESTClient() rest.Interface
+	BucketsGetter
+	PoliciesGetter
 	TenantsGetter
+	UsersGetter
 }
 
 // MinioV2Client is used to interact with features provided by the minio.min.io group.
@@ -34,10 +37,22 @@ type MinioV2Client struct {
 	restClient rest.Interface
 }
 
+func (c *MinioV2Client) Buckets(namespace string) BucketInterface {
+	return newBuckets(c, namespace)
+}
+
+func (c *MinioV2Client) Policies(namespace string) PolicyInterface {
+	return newPolicies(c, namespace)
+}
+
 func (c *MinioV2Client) Tenants(namespace string) TenantInterface {
 	return newTenants(c, namespace)
 }
 
+func (c *MinioV2Client) Users(namespace string) UserInterface {
+	return newUsers(c, namespace)
+}
+
 // NewForConfig creates a new MinioV2Client for the given config.
 func NewForConfig(c *rest.Config) (*MinioV2Client, error) {
 	config := *c
@@ -0,0 +1,195 @@
+// This file is part of MinIO Operator
+// Copyright (c) 2020 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v2
+
+import (
+	"context"
+	"time"
+
+	v2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	scheme "github.com/minio/operator/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// UsersGetter has a method to return a UserInterface.
+// A group's client should implement this interface.
+type UsersGetter interface {
+	Users(namespace string) UserInterface
+}
+
+// UserInterface has methods to work with User resources.
+type UserInterface interface {
+	Create(ctx context.Context, user *v2.User, opts v1.CreateOptions) (*v2.User, error)
+	Update(ctx context.Context, user *v2.User, opts v1.UpdateOptions) (*v2.User, error)
+	UpdateStatus(ctx context.Context, user *v2.User, opts v1.UpdateOptions) (*v2.User, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v2.User, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v2.UserList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v2.User, err error)
+	UserExpansion
+}
+
+// users implements UserInterface
+type users struct {
+	client rest.Interface
+	ns     string
+}
+
+// newUsers returns a Users
+func newUsers(c *MinioV2Client, namespace string) *users {
+	return &users{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the user, and returns the corresponding user object, and an error if there is any.
+func (c *users) Get(ctx context.Context, name string, options v1.GetOptions) (result *v2.User, err error) {
+	result = &v2.User{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("users").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of Users that match those selectors.
+func (c *users) List(ctx context.Context, opts v1.ListOptions) (result *v2.UserList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v2.UserList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("users").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested users.
+func (c *users) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("users").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a user and creates it.  Returns the server's representation of the user, and an error, if there is any.
+func (c *users) Create(ctx context.Context, user *v2.User, opts v1.CreateOptions) (result *v2.User, err error) {
+	result = &v2.User{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("users").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(user).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a user and updates it. Returns the server's representation of the user, and an error, if there is any.
+func (c *users) Update(ctx context.Context, user *v2.User, opts v1.UpdateOptions) (result *v2.User, err error) {
+	result = &v2.User{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("users").
+		Name(user.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(user).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *users) UpdateStatus(ctx context.Context, user *v2.User, opts v1.UpdateOptions) (result *v2.User, err error) {
+	result = &v2.User{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("users").
+		Name(user.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(user).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the user and deletes it. Returns an error if one occurs.
+func (c *users) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("users").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *users) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("users").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched user.
+func (c *users) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v2.User, err error) {
+	result = &v2.User{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("users").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -58,8 +58,14 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Minio().V1().Tenants().Informer()}, nil
 
 		// Group=minio.min.io, Version=v2
+	case v2.SchemeGroupVersion.WithResource("buckets"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Minio().V2().Buckets().Informer()}, nil
+	case v2.SchemeGroupVersion.WithResource("policies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Minio().V2().Policies().Informer()}, nil
 	case v2.SchemeGroupVersion.WithResource("tenants"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Minio().V2().Tenants().Informer()}, nil
+	case v2.SchemeGroupVersion.WithResource("users"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Minio().V2().Users().Informer()}, nil
 
 	}
 
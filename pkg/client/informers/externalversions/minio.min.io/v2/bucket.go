@@ -0,0 +1,90 @@
+// This file is part of MinIO Operator
+// Copyright (c) 2020 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v2
+
+import (
+	"context"
+	time "time"
+
+	miniominiov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	versioned "github.com/minio/operator/pkg/client/clientset/versioned"
+	internalinterfaces "github.com/minio/operator/pkg/client/informers/externalversions/internalinterfaces"
+	v2 "github.com/minio/operator/pkg/client/listers/minio.min.io/v2"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// BucketInformer provides access to a shared informer and lister for
+// Buckets.
+type BucketInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v2.BucketLister
+}
+
+type bucketInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+	namespace        string
+}
+
+// NewBucketInformer constructs a new informer for Bucket type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewBucketInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredBucketInformer(client, namespace, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredBucketInformer constructs a new informer for Bucket type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredBucketInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.MinioV2().Buckets(namespace).List(context.TODO(), options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.MinioV2().Buckets(namespace).Watch(context.TODO(), options)
+			},
+		},
+		&miniominiov2.Bucket{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *bucketInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredBucketInformer(client, f.namespace, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *bucketInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&miniominiov2.Bucket{}, f.defaultInformer)
+}
+
+func (f *bucketInformer) Lister() v2.BucketLister {
+	return v2.NewBucketLister(f.Informer().GetIndexer())
+}
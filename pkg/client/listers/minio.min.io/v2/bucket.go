@@ -0,0 +1,94 @@
+// This file is part of MinIO Operator
+// Copyright (c) 2020 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v2
+
+import (
+	v2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// BucketLister helps list Buckets.
+type BucketLister interface {
+	// List lists all Buckets in the indexer.
+	List(selector labels.Selector) (ret []*v2.Bucket, err error)
+	// Buckets returns an object that can list and get Buckets.
+	Buckets(namespace string) BucketNamespaceLister
+	BucketListerExpansion
+}
+
+// bucketLister implements the BucketLister interface.
+type bucketLister struct {
+	indexer cache.Indexer
+}
+
+// NewBucketLister returns a new BucketLister.
+func NewBucketLister(indexer cache.Indexer) BucketLister {
+	return &bucketLister{indexer: indexer}
+}
+
+// List lists all Buckets in the indexer.
+func (s *bucketLister) List(selector labels.Selector) (ret []*v2.Bucket, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v2.Bucket))
+	})
+	return ret, err
+}
+
+// Buckets returns an object that can list and get Buckets.
+func (s *bucketLister) Buckets(namespace string) BucketNamespaceLister {
+	return bucketNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// BucketNamespaceLister helps list and get Buckets.
+type BucketNamespaceLister interface {
+	// List lists all Buckets in the indexer for a given namespace.
+	List(selector labels.Selector) (ret []*v2.Bucket, err error)
+	// Get retrieves the Bucket from the indexer for a given namespace and name.
+	Get(name string) (*v2.Bucket, error)
+	BucketNamespaceListerExpansion
+}
+
+// bucketNamespaceLister implements the BucketNamespaceLister
+// interface.
+type bucketNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all Buckets in the indexer for a given namespace.
+func (s bucketNamespaceLister) List(selector labels.Selector) (ret []*v2.Bucket, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v2.Bucket))
+	})
+	return ret, err
+}
+
+// Get retrieves the Bucket from the indexer for a given namespace and name.
+func (s bucketNamespaceLister) Get(name string) (*v2.Bucket, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v2.Resource("bucket"), name)
+	}
+	return obj.(*v2.Bucket), nil
+}
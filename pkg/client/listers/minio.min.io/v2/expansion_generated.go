@@ -18,6 +18,22 @@
 
 package v2
 
+// BucketListerExpansion allows custom methods to be added to
+// BucketLister.
+type BucketListerExpansion interface{}
+
+// BucketNamespaceListerExpansion allows custom methods to be added to
+// BucketNamespaceLister.
+type BucketNamespaceListerExpansion interface{}
+
+// PolicyListerExpansion allows custom methods to be added to
+// PolicyLister.
+type PolicyListerExpansion interface{}
+
+// PolicyNamespaceListerExpansion allows custom methods to be added to
+// PolicyNamespaceLister.
+type PolicyNamespaceListerExpansion interface{}
+
 // TenantListerExpansion allows custom methods to be added to
 // TenantLister.
 type TenantListerExpansion interface{}
@@ -25,3 +41,11 @@ type TenantListerExpansion interface{}
 // TenantNamespaceListerExpansion allows custom methods to be added to
 // TenantNamespaceLister.
 type TenantNamespaceListerExpansion interface{}
+
+// UserListerExpansion allows custom methods to be added to
+// UserLister.
+type UserListerExpansion interface{}
+
+// UserNamespaceListerExpansion allows custom methods to be added to
+// UserNamespaceLister.
+type UserNamespaceListerExpansion interface{}
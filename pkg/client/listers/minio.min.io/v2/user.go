@@ -0,0 +1,94 @@
+// This file is part of MinIO Operator
+// Copyright (c) 2020 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v2
+
+import (
+	v2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// UserLister helps list Users.
+type UserLister interface {
+	// List lists all Users in the indexer.
+	List(selector labels.Selector) (ret []*v2.User, err error)
+	// Users returns an object that can list and get Users.
+	Users(namespace string) UserNamespaceLister
+	UserListerExpansion
+}
+
+// userLister implements the UserLister interface.
+type userLister struct {
+	indexer cache.Indexer
+}
+
+// NewUserLister returns a new UserLister.
+func NewUserLister(indexer cache.Indexer) UserLister {
+	return &userLister{indexer: indexer}
+}
+
+// List lists all Users in the indexer.
+func (s *userLister) List(selector labels.Selector) (ret []*v2.User, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v2.User))
+	})
+	return ret, err
+}
+
+// Users returns an object that can list and get Users.
+func (s *userLister) Users(namespace string) UserNamespaceLister {
+	return userNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// UserNamespaceLister helps list and get Users.
+type UserNamespaceLister interface {
+	// List lists all Users in the indexer for a given namespace.
+	List(selector labels.Selector) (ret []*v2.User, err error)
+	// Get retrieves the User from the indexer for a given namespace and name.
+	Get(name string) (*v2.User, error)
+	UserNamespaceListerExpansion
+}
+
+// userNamespaceLister implements the UserNamespaceLister
+// interface.
+type userNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all Users in the indexer for a given namespace.
+func (s userNamespaceLister) List(selector labels.Selector) (ret []*v2.User, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v2.User))
+	})
+	return ret, err
+}
+
+// Get retrieves the User from the indexer for a given namespace and name.
+func (s userNamespaceLister) Get(name string) (*v2.User, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v2.Resource("user"), name)
+	}
+	return obj.(*v2.User), nil
+}
@@ -0,0 +1,356 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package bucket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/minio/madmin-go"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/sse"
+	"github.com/minio/minio-go/v7/pkg/tags"
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// Bucket states
+const (
+	StatusWaitingForTenant = "Waiting for Tenant"
+	StatusReady            = "Ready"
+	StatusError            = "Error"
+)
+
+// ErrTenantNotReady is returned while a Bucket with the DeleteIfEmpty policy waits for its Tenant to be deleted
+var ErrTenantNotReady = errors.New("Tenant is not ready")
+
+// ErrBucketNotEmpty is returned while a Bucket with the DeleteIfEmpty policy can't be deleted
+var ErrBucketNotEmpty = errors.New("bucket is not empty")
+
+// bucketName returns the name of the bucket in MinIO
+func bucketName(bucket *miniov2.Bucket) string {
+	if bucket.Spec.Name != "" {
+		return bucket.Spec.Name
+	}
+	return bucket.Name
+}
+
+// bucketSynced tells whether the last generation of the Bucket was applied successfully
+func bucketSynced(bucket *miniov2.Bucket) bool {
+	return bucket.Status.CurrentState == StatusReady && bucket.Status.ObservedGeneration == bucket.Generation
+}
+
+// hasFinalizer tells whether the Bucket carries the operator finalizer
+func hasFinalizer(bucket *miniov2.Bucket) bool {
+	for _, f := range bucket.Finalizers {
+		if f == miniov2.BucketFinalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// updateBucketStatus records the state of the Bucket for its current generation
+func (c *Controller) updateBucketStatus(ctx context.Context, bucket *miniov2.Bucket, state, message string) (*miniov2.Bucket, error) {
+	if bucket.Status.CurrentState == state && bucket.Status.Message == message && bucket.Status.ObservedGeneration == bucket.Generation {
+		return bucket, nil
+	}
+	bucketCopy := bucket.DeepCopy()
+	bucketCopy.Status.CurrentState = state
+	bucketCopy.Status.Message = message
+	bucketCopy.Status.ObservedGeneration = bucket.Generation
+	return c.minioClientSet.MinioV2().Buckets(bucket.Namespace).UpdateStatus(ctx, bucketCopy, metav1.UpdateOptions{})
+}
+
+// tenantClients returns the S3 and admin clients of the Tenant, authenticated with its root credentials
+func (c *Controller) tenantClients(ctx context.Context, tenant *miniov2.Tenant) (*minio.Client, *madmin.AdminClient, error) {
+	if tenant.Spec.CredsSecret == nil {
+		return nil, nil, fmt.Errorf("Tenant '%s/%s' has no credentials secret", tenant.Namespace, tenant.Name)
+	}
+	minioSecret, err := c.kubeClientSet.CoreV1().Secrets(tenant.Namespace).Get(ctx, tenant.Spec.CredsSecret.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	minioClnt, err := tenant.NewMinIOClient(minioSecret.Data, c.transports.Transport(tenant))
+	if err != nil {
+		return nil, nil, err
+	}
+	adminClnt, err := tenant.NewMinIOAdmin(minioSecret.Data, c.transports.Transport(tenant))
+	if err != nil {
+		return nil, nil, err
+	}
+	return minioClnt, adminClnt, nil
+}
+
+// readyTenant returns the Tenant hosting the Bucket, or nil if it doesn't exist or isn't ready yet
+func (c *Controller) readyTenant(bucket *miniov2.Bucket) (*miniov2.Tenant, error) {
+	tenant, err := c.tenantsLister.Tenants(bucket.Namespace).Get(bucket.Spec.Tenant.Name)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if tenant.DeletionTimestamp != nil || !meta.IsStatusConditionTrue(tenant.Status.Conditions, miniov2.TenantConditionReady) {
+		return nil, nil
+	}
+	return tenant, nil
+}
+
+// applyBucket creates the bucket in its Tenant if needed and converges its configuration with the spec, it
+// returns true once the bucket is Ready to check it again for drift later
+func (c *Controller) applyBucket(ctx context.Context, bucket *miniov2.Bucket) (bool, error) {
+	tenant, err := c.readyTenant(bucket)
+	if err != nil {
+		return false, err
+	}
+	if tenant == nil {
+		// The Tenant informer enqueues the Bucket again once the Tenant changes
+		_, err = c.updateBucketStatus(ctx, bucket, StatusWaitingForTenant, "")
+		return false, err
+	}
+
+	name := bucketName(bucket)
+	if bucket.Status.BucketName != "" && bucket.Status.BucketName != name {
+		// Nothing to retry until the name is reverted
+		msg := fmt.Sprintf("bucket name can't be changed from %s to %s", bucket.Status.BucketName, name)
+		_, err = c.updateBucketStatus(ctx, bucket, StatusError, msg)
+		return false, err
+	}
+
+	minioClnt, adminClnt, err := c.tenantClients(ctx, tenant)
+	if err == nil {
+		err = configureBucket(ctx, minioClnt, adminClnt, name, &bucket.Spec)
+	}
+	if err != nil {
+		if _, serr := c.updateBucketStatus(ctx, bucket, StatusError, err.Error()); serr != nil {
+			klog.Errorf("Unable to update the status of bucket '%s/%s': %v", bucket.Namespace, bucket.Name, serr)
+		}
+		return false, err
+	}
+
+	bucket.Status.BucketName = name
+	_, err = c.updateBucketStatus(ctx, bucket, StatusReady, "")
+	return err == nil, err
+}
+
+// configureBucket creates the bucket if it doesn't exist and applies every setting of the spec to it
+func configureBucket(ctx context.Context, minioClnt *minio.Client, adminClnt *madmin.AdminClient, name string, spec *miniov2.BucketSpec) error {
+	exists, err := minioClnt.BucketExists(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		klog.Infof("Creating bucket %s", name)
+		err = minioClnt.MakeBucket(ctx, name, minio.MakeBucketOptions{
+			Region:        spec.Region,
+			ObjectLocking: spec.ObjectLock != nil,
+		})
+		if err != nil {
+			return fmt.Errorf("unable to create bucket: %w", err)
+		}
+	}
+
+	switch spec.Versioning {
+	case "Enabled":
+		err = minioClnt.EnableVersioning(ctx, name)
+	case "Suspended":
+		err = minioClnt.SuspendVersioning(ctx, name)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to set versioning: %w", err)
+	}
+
+	if spec.ObjectLock != nil {
+		if spec.ObjectLock.Mode != "" {
+			mode := minio.RetentionMode(spec.ObjectLock.Mode)
+			validity := uint(spec.ObjectLock.Validity)
+			unit := minio.ValidityUnit(spec.ObjectLock.Unit)
+			err = minioClnt.SetBucketObjectLockConfig(ctx, name, &mode, &validity, &unit)
+		} else {
+			err = minioClnt.SetBucketObjectLockConfig(ctx, name, nil, nil, nil)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to set object lock retention: %w", err)
+		}
+	}
+
+	quota := &madmin.BucketQuota{}
+	if spec.Quota != nil {
+		quota.Quota = uint64(spec.Quota.Hard.Value())
+		quota.Type = madmin.HardQuota
+	}
+	if err = adminClnt.SetBucketQuota(ctx, name, quota); err != nil {
+		return fmt.Errorf("unable to set quota: %w", err)
+	}
+
+	if spec.Encryption != nil {
+		config := sse.NewConfigurationSSES3()
+		if spec.Encryption.Algorithm == "aws:kms" {
+			config = sse.NewConfigurationSSEKMS(spec.Encryption.KMSKeyID)
+		}
+		err = minioClnt.SetBucketEncryption(ctx, name, config)
+	} else {
+		err = minioClnt.RemoveBucketEncryption(ctx, name)
+		if minio.ToErrorResponse(err).Code == "ServerSideEncryptionConfigurationNotFoundError" {
+			err = nil
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("unable to set encryption: %w", err)
+	}
+
+	policy, err := anonymousPolicy(name, spec.AnonymousPolicy)
+	if err != nil {
+		return err
+	}
+	// An empty policy removes the current one
+	if err = minioClnt.SetBucketPolicy(ctx, name, policy); err != nil {
+		return fmt.Errorf("unable to set anonymous policy: %w", err)
+	}
+
+	if len(spec.Tags) > 0 {
+		var bucketTags *tags.Tags
+		if bucketTags, err = tags.NewTags(spec.Tags, false); err == nil {
+			err = minioClnt.SetBucketTagging(ctx, name, bucketTags)
+		}
+	} else {
+		err = minioClnt.RemoveBucketTagging(ctx, name)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to set tags: %w", err)
+	}
+	return nil
+}
+
+// policyStatement is a statement of an S3 bucket policy
+type policyStatement struct {
+	Effect    string              `json:"Effect"`
+	Principal map[string][]string `json:"Principal"`
+	Action    []string            `json:"Action"`
+	Resource  []string            `json:"Resource"`
+}
+
+// anonymousPolicy returns the bucket policy granting the access of the given canned anonymous
+// policy, the same ones `mc anonymous` sets. An empty policy is returned for `none`.
+func anonymousPolicy(bucket, access string) (string, error) {
+	var bucketActions, objectActions []string
+	switch access {
+	case "", "none":
+		return "", nil
+	case "download":
+		bucketActions = []string{"s3:GetBucketLocation", "s3:ListBucket"}
+		objectActions = []string{"s3:GetObject"}
+	case "upload":
+		bucketActions = []string{"s3:GetBucketLocation", "s3:ListBucketMultipartUploads"}
+		objectActions = []string{"s3:AbortMultipartUpload", "s3:DeleteObject", "s3:ListMultipartUploadParts", "s3:PutObject"}
+	case "public":
+		bucketActions = []string{"s3:GetBucketLocation", "s3:ListBucket", "s3:ListBucketMultipartUploads"}
+		objectActions = []string{"s3:AbortMultipartUpload", "s3:DeleteObject", "s3:GetObject", "s3:ListMultipartUploadParts", "s3:PutObject"}
+	default:
+		return "", fmt.Errorf("unknown anonymous policy %s", access)
+	}
+	anyone := map[string][]string{"AWS": {"*"}}
+	policy := struct {
+		Version   string            `json:"Version"`
+		Statement []policyStatement `json:"Statement"`
+	}{
+		Version: "2012-10-17",
+		Statement: []policyStatement{
+			{Effect: "Allow", Principal: anyone, Action: bucketActions, Resource: []string{"arn:aws:s3:::" + bucket}},
+			{Effect: "Allow", Principal: anyone, Action: objectActions, Resource: []string{"arn:aws:s3:::" + bucket + "/*"}},
+		},
+	}
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// bucketIsEmpty tells whether the bucket holds no objects nor object versions
+func bucketIsEmpty(ctx context.Context, minioClnt *minio.Client, name string) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for object := range minioClnt.ListObjects(ctx, name, minio.ListObjectsOptions{
+		WithVersions: true,
+		Recursive:    true,
+		MaxKeys:      1,
+	}) {
+		if object.Err != nil {
+			return false, object.Err
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+// deleteBucket applies the deletion policy of a Bucket being deleted and releases its finalizer
+func (c *Controller) deleteBucket(ctx context.Context, bucket *miniov2.Bucket) error {
+	if !hasFinalizer(bucket) {
+		return nil
+	}
+
+	if bucket.Spec.DeletionPolicy == miniov2.BucketDeletionPolicyDeleteIfEmpty && bucket.Status.BucketName != "" {
+		tenant, err := c.tenantsLister.Tenants(bucket.Namespace).Get(bucket.Spec.Tenant.Name)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+		// Without a Tenant there's no bucket left to remove
+		if err == nil && tenant.DeletionTimestamp == nil {
+			if !meta.IsStatusConditionTrue(tenant.Status.Conditions, miniov2.TenantConditionReady) {
+				return ErrTenantNotReady
+			}
+			minioClnt, _, err := c.tenantClients(ctx, tenant)
+			if err != nil {
+				return err
+			}
+			name := bucket.Status.BucketName
+			empty, err := bucketIsEmpty(ctx, minioClnt, name)
+			if err != nil && minio.ToErrorResponse(err).Code != "NoSuchBucket" {
+				return err
+			}
+			if err == nil && !empty {
+				if _, err = c.updateBucketStatus(ctx, bucket, StatusError, fmt.Sprintf("bucket %s is not empty", name)); err != nil {
+					return err
+				}
+				return ErrBucketNotEmpty
+			}
+			klog.Infof("Removing bucket %s of Tenant '%s/%s'", name, tenant.Namespace, tenant.Name)
+			err = minioClnt.RemoveBucket(ctx, name)
+			if err != nil && minio.ToErrorResponse(err).Code != "NoSuchBucket" {
+				return err
+			}
+		}
+	}
+
+	finalizers := bucket.Finalizers[:0]
+	for _, f := range bucket.Finalizers {
+		if f != miniov2.BucketFinalizer {
+			finalizers = append(finalizers, f)
+		}
+	}
+	bucket.Finalizers = finalizers
+	_, err := c.minioClientSet.MinioV2().Buckets(bucket.Namespace).Update(ctx, bucket, metav1.UpdateOptions{})
+	return err
+}
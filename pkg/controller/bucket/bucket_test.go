@@ -0,0 +1,103 @@
+// This file is part of MinIO Operator
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package bucket
+
+import (
+	"encoding/json"
+	"testing"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_anonymousPolicy(t *testing.T) {
+	tests := []struct {
+		name          string
+		access        string
+		wantEmpty     bool
+		wantObjectOps []string
+		wantErr       bool
+	}{
+		{name: "Unset", access: "", wantEmpty: true},
+		{name: "None", access: "none", wantEmpty: true},
+		{name: "Download", access: "download", wantObjectOps: []string{"s3:GetObject"}},
+		{name: "Public", access: "public", wantObjectOps: []string{"s3:AbortMultipartUpload", "s3:DeleteObject", "s3:GetObject", "s3:ListMultipartUploadParts", "s3:PutObject"}},
+		{name: "Unknown", access: "private", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := anonymousPolicy("reports", tt.access)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("anonymousPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if (got == "") != tt.wantEmpty {
+				t.Fatalf("anonymousPolicy() = %q, wantEmpty %v", got, tt.wantEmpty)
+			}
+			if tt.wantEmpty {
+				return
+			}
+			var policy struct {
+				Statement []policyStatement
+			}
+			if err = json.Unmarshal([]byte(got), &policy); err != nil {
+				t.Fatal(err)
+			}
+			if len(policy.Statement) != 2 {
+				t.Fatalf("anonymousPolicy() has %d statements, want 2", len(policy.Statement))
+			}
+			objects := policy.Statement[1]
+			if objects.Resource[0] != "arn:aws:s3:::reports/*" {
+				t.Errorf("object statement resource = %s", objects.Resource[0])
+			}
+			if len(objects.Action) != len(tt.wantObjectOps) {
+				t.Fatalf("object actions = %v, want %v", objects.Action, tt.wantObjectOps)
+			}
+			for i := range objects.Action {
+				if objects.Action[i] != tt.wantObjectOps[i] {
+					t.Errorf("object actions = %v, want %v", objects.Action, tt.wantObjectOps)
+				}
+			}
+		})
+	}
+}
+
+func Test_bucketSynced(t *testing.T) {
+	bucket := &miniov2.Bucket{
+		ObjectMeta: metav1.ObjectMeta{Name: "reports", Generation: 2},
+		Status: miniov2.BucketStatus{
+			CurrentState:       StatusReady,
+			ObservedGeneration: 1,
+		},
+	}
+	if bucketSynced(bucket) {
+		t.Error("bucket with a new generation should not be synced")
+	}
+	bucket.Status.ObservedGeneration = 2
+	if !bucketSynced(bucket) {
+		t.Error("bucket ready for its generation should be synced")
+	}
+	if bucketName(bucket) != "reports" {
+		t.Errorf("bucketName() = %s, want reports", bucketName(bucket))
+	}
+	bucket.Spec.Name = "finance-reports"
+	if bucketName(bucket) != "finance-reports" {
+		t.Errorf("bucketName() = %s, want finance-reports", bucketName(bucket))
+	}
+}
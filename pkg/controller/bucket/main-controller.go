@@ -0,0 +1,235 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package bucket
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	clientset "github.com/minio/operator/pkg/client/clientset/versioned"
+	informers "github.com/minio/operator/pkg/client/informers/externalversions/minio.min.io/v2"
+	listers "github.com/minio/operator/pkg/client/listers/minio.min.io/v2"
+	"github.com/minio/operator/pkg/controller/cluster"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	queue "k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+)
+
+// driftCheckInterval is how often the buckets in MinIO are converged again with their Ready Bucket objects
+const driftCheckInterval = 5 * time.Minute
+
+// Controller reconciles the Bucket objects against the buckets of their Tenant
+type Controller struct {
+	// kubeClientSet is a standard kubernetes clientset
+	kubeClientSet kubernetes.Interface
+	// minioClientSet is a clientset for our own API group
+	minioClientSet clientset.Interface
+
+	// bucketsLister lists Bucket from a shared informer's store
+	bucketsLister listers.BucketLister
+	// bucketsSynced returns true if the Bucket shared informer has synced at least once
+	bucketsSynced cache.InformerSynced
+
+	// tenantsLister lists Tenant from a shared informer's store
+	tenantsLister listers.TenantLister
+	// tenantsSynced returns true if the Tenant shared informer has synced at least once
+	tenantsSynced cache.InformerSynced
+
+	// transports the Operator connects to the Tenants with, shared with the Tenant controller
+	transports *cluster.TenantTransports
+
+	// workqueue is a rate limited work queue of Bucket keys
+	workqueue queue.RateLimitingInterface
+}
+
+// NewController returns a new Bucket controller
+func NewController(
+	kubeClientSet kubernetes.Interface,
+	minioClientSet clientset.Interface,
+	bucketInformer informers.BucketInformer,
+	tenantInformer informers.TenantInformer,
+	transports *cluster.TenantTransports) *Controller {
+
+	controller := &Controller{
+		kubeClientSet:  kubeClientSet,
+		minioClientSet: minioClientSet,
+		bucketsLister:  bucketInformer.Lister(),
+		bucketsSynced:  bucketInformer.Informer().HasSynced,
+		tenantsLister:  tenantInformer.Lister(),
+		tenantsSynced:  tenantInformer.Informer().HasSynced,
+		transports:     transports,
+		workqueue:      queue.NewNamedRateLimitingQueue(cluster.MinIOControllerRateLimiter(), "Buckets"),
+	}
+
+	klog.Info("Setting up Bucket event handlers")
+	bucketInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.enqueueBucket,
+		UpdateFunc: func(old, new interface{}) {
+			oldBucket := old.(*miniov2.Bucket)
+			newBucket := new.(*miniov2.Bucket)
+			if newBucket.ResourceVersion == oldBucket.ResourceVersion {
+				// Periodic resync will send update events for all known Buckets.
+				return
+			}
+			controller.enqueueBucket(new)
+		},
+	})
+	// Buckets waiting for their Tenant are picked up again once the Tenant changes
+	tenantInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.enqueueTenantBuckets,
+		UpdateFunc: func(old, new interface{}) {
+			oldTenant := old.(*miniov2.Tenant)
+			newTenant := new.(*miniov2.Tenant)
+			if newTenant.ResourceVersion == oldTenant.ResourceVersion {
+				return
+			}
+			controller.enqueueTenantBuckets(new)
+		},
+	})
+	return controller
+}
+
+// Start waits for the informer caches to sync and starts the workers. It doesn't
+// block, the workers stop once stopCh is closed.
+func (c *Controller) Start(threadiness int, stopCh <-chan struct{}) error {
+	klog.Info("Starting Bucket controller")
+
+	klog.Info("Waiting for Bucket informer caches to sync")
+	if ok := cache.WaitForCacheSync(stopCh, c.bucketsSynced, c.tenantsSynced, c.transports.HasSynced); !ok {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+
+	for i := 0; i < threadiness; i++ {
+		go wait.Until(c.runWorker, time.Second, stopCh)
+	}
+	return nil
+}
+
+// Stop is called to shutdown the controller
+func (c *Controller) Stop() {
+	klog.Info("Stopping the Bucket controller")
+	c.workqueue.ShutDown()
+}
+
+// runWorker processes the Bucket keys of the workqueue until it is shut down
+func (c *Controller) runWorker() {
+	defer runtime.HandleCrash()
+	for c.processNextWorkItem() {
+	}
+}
+
+// processNextWorkItem reads a single key off the workqueue and syncs its Bucket
+func (c *Controller) processNextWorkItem() bool {
+	obj, shutdown := c.workqueue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.workqueue.Done(obj)
+
+	key, ok := obj.(string)
+	if !ok {
+		c.workqueue.Forget(obj)
+		runtime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+		return true
+	}
+	if err := c.syncHandler(key); err != nil {
+		// Put the item back on the workqueue to handle any transient errors.
+		c.workqueue.AddRateLimited(key)
+		runtime.HandleError(fmt.Errorf("error syncing bucket '%s': %s", key, err.Error()))
+		return true
+	}
+	c.workqueue.Forget(obj)
+	klog.V(2).Infof("Successfully synced bucket '%s'", key)
+	return true
+}
+
+// syncHandler converges the bucket in MinIO with the Bucket object of the given key and schedules the next drift
+// check
+func (c *Controller) syncHandler(key string) error {
+	ctx := context.Background()
+
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("Invalid resource key: %s", key))
+		return nil
+	}
+
+	bucket, err := c.bucketsLister.Buckets(namespace).Get(name)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	// Never modify objects from the store
+	bucket = bucket.DeepCopy()
+
+	if bucket.DeletionTimestamp != nil {
+		return c.deleteBucket(ctx, bucket)
+	}
+	if !hasFinalizer(bucket) {
+		bucket.Finalizers = append(bucket.Finalizers, miniov2.BucketFinalizer)
+		if bucket, err = c.minioClientSet.MinioV2().Buckets(namespace).Update(ctx, bucket, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	recheck, err := c.applyBucket(ctx, bucket)
+	if err != nil {
+		return err
+	}
+	if recheck {
+		c.workqueue.AddAfter(key, driftCheckInterval)
+	}
+	return nil
+}
+
+// enqueueBucket puts the namespace/name key of a Bucket on the workqueue
+func (c *Controller) enqueueBucket(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	c.workqueue.AddRateLimited(key)
+}
+
+// enqueueTenantBuckets enqueues the Buckets hosted by the given Tenant that are not in sync yet
+func (c *Controller) enqueueTenantBuckets(obj interface{}) {
+	tenant, ok := obj.(*miniov2.Tenant)
+	if !ok {
+		return
+	}
+	buckets, err := c.bucketsLister.Buckets(tenant.Namespace).List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	for _, bucket := range buckets {
+		if bucket.Spec.Tenant.Name == tenant.Name && !bucketSynced(bucket) {
+			c.enqueueBucket(bucket)
+		}
+	}
+}
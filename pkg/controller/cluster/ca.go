@@ -0,0 +1,318 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"time"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	"github.com/minio/operator/pkg/resources/configmaps"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// caValidity is how long the CA generated by the Operator is valid
+	caValidity = 10 * 365 * 24 * time.Hour
+	// caCertificateValidity is how long the certificates signed by the CA of the Operator are valid
+	caCertificateValidity = 365 * 24 * time.Hour
+	// caClockSkew backdates the certificates signed by the CA, for the nodes running slightly behind
+	caClockSkew = 5 * time.Minute
+)
+
+// operatorCA is the CA of the Operator, signing certificates without the certificates.k8s.io API
+type operatorCA struct {
+	cert    *x509.Certificate
+	certPEM []byte
+	key     crypto.Signer
+}
+
+// randomSerialNumber returns a random 128 bits serial number for a new certificate
+func randomSerialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+// newOperatorCA returns the PEM encoded certificate and private key of a new self-signed CA
+func newOperatorCA() ([]byte, []byte, error) {
+	privateKey, err := newPrivateKey(miniov2.DefaultEllipticCurve)
+	if err != nil {
+		return nil, nil, err
+	}
+	serialNumber, err := randomSerialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	template := x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			CommonName:   "minio-operator-ca",
+			Organization: []string{"MinIO Operator"},
+		},
+		NotBefore:             now.Add(-caClockSkew),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, &template, &template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	privKeyBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes}),
+		pem.EncodeToMemory(&pem.Block{Type: privateKeyType, Bytes: privKeyBytes}), nil
+}
+
+// EnsureOperatorCA generates the CA of the Operator and stores it in the Operator CA secret, unless the
+// secret already exists. It's a no-op when the internal CA isn't enabled.
+func EnsureOperatorCA(ctx context.Context, kubeClient kubernetes.Interface) error {
+	if !miniov2.GetOperatorInternalCA() {
+		return nil
+	}
+	namespace := miniov2.GetNSFromFile()
+	_, err := kubeClient.CoreV1().Secrets(namespace).Get(ctx, miniov2.OperatorCATLSSecretName, metav1.GetOptions{})
+	if err == nil || !k8serrors.IsNotFound(err) {
+		return err
+	}
+	klog.Infof("Generating the CA of the Operator in secret/%s", miniov2.OperatorCATLSSecretName)
+	certPEM, keyPEM, err := newOperatorCA()
+	if err != nil {
+		return err
+	}
+	secret := &corev1.Secret{
+		Type: "Opaque",
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      miniov2.OperatorCATLSSecretName,
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			miniov2.CACertificateKey: certPEM,
+			miniov2.CAPrivateKeyKey:  keyPEM,
+		},
+	}
+	_, err = kubeClient.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+	if k8serrors.IsAlreadyExists(err) {
+		// Another replica of the Operator generated it first
+		return nil
+	}
+	return err
+}
+
+// loadOperatorCA returns the CA stored in the Operator CA secret
+func loadOperatorCA(ctx context.Context, kubeClient kubernetes.Interface) (*operatorCA, error) {
+	secret, err := kubeClient.CoreV1().Secrets(miniov2.GetNSFromFile()).Get(ctx, miniov2.OperatorCATLSSecretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	certPEM, ok := secret.Data[miniov2.CACertificateKey]
+	if !ok {
+		return nil, fmt.Errorf("secret/%s has no %s", miniov2.OperatorCATLSSecretName, miniov2.CACertificateKey)
+	}
+	keyPEM, ok := secret.Data[miniov2.CAPrivateKeyKey]
+	if !ok {
+		return nil, fmt.Errorf("secret/%s has no %s, the Operator can't sign certificates with its CA", miniov2.OperatorCATLSSecretName, miniov2.CAPrivateKeyKey)
+	}
+	cert, err := parseCertificate(bytes.NewReader(certPEM))
+	if err != nil {
+		return nil, err
+	}
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, fmt.Errorf("secret/%s has an invalid %s", miniov2.OperatorCATLSSecretName, miniov2.CAPrivateKeyKey)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported private key of the Operator CA")
+	}
+	return &operatorCA{cert: cert, certPEM: certPEM, key: signer}, nil
+}
+
+// sign returns the PEM encoded certificate of the request, followed by the CA certificate, and its
+// private key
+func (ca *operatorCA) sign(req *certificateRequest) ([]byte, []byte, error) {
+	privateKey, err := newPrivateKey(miniov2.DefaultEllipticCurve)
+	if err != nil {
+		return nil, nil, err
+	}
+	serialNumber, err := randomSerialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+	extKeyUsage := x509.ExtKeyUsageServerAuth
+	if req.client {
+		extKeyUsage = x509.ExtKeyUsageClientAuth
+	}
+	now := time.Now()
+	template := x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			CommonName:   req.commonName,
+			Organization: req.organization,
+		},
+		DNSNames:              req.dnsNames,
+		NotBefore:             now.Add(-caClockSkew),
+		NotAfter:              now.Add(caCertificateValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{extKeyUsage},
+		BasicConstraintsValid: true,
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, &template, ca.cert, &privateKey.PublicKey, ca.key)
+	if err != nil {
+		return nil, nil, err
+	}
+	privKeyBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Serving the chain lets the clients trusting the CA verify the certificate
+	certPEM := append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes}), ca.certPEM...)
+	return certPEM, pem.EncodeToMemory(&pem.Block{Type: privateKeyType, Bytes: privKeyBytes}), nil
+}
+
+// signed returns true if the first certificate of the given PEM data is signed by the CA
+func (ca *operatorCA) signed(certPEM []byte) bool {
+	cert, err := parseCertificate(bytes.NewReader(certPEM))
+	if err != nil {
+		return false
+	}
+	return cert.CheckSignatureFrom(ca.cert) == nil
+}
+
+// caProvider issues certificates signed by the CA of the Operator
+type caProvider struct {
+	controller *Controller
+}
+
+// check returns true if the secret of the request holds a certificate signed by the CA of the Operator. A
+// secret issued for the owner of the request by another provider or a previous CA is removed, a secret the owner
+// doesn't control, like a user-provisioned operator-tls, is left alone.
+func (p *caProvider) check(ctx context.Context, req *certificateRequest) (bool, error) {
+	c := p.controller
+	secret, err := c.kubeClientSet.CoreV1().Secrets(req.namespace).Get(ctx, req.secretName, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	ca, err := loadOperatorCA(ctx, c.kubeClientSet)
+	if err != nil {
+		return false, err
+	}
+	if ca.signed(secret.Data[miniov2.CSRCertificateKey]) {
+		return true, nil
+	}
+	if !metav1.IsControlledBy(secret, req.owner) {
+		return false, fmt.Errorf("secret/%s isn't signed by the CA of the Operator and isn't controlled by %s, refusing to replace it", req.secretName, req.owner.GetName())
+	}
+	klog.Infof("Removing secret/%s not signed by the CA of the Operator", req.secretName)
+	if err = c.kubeClientSet.CoreV1().Secrets(req.namespace).Delete(ctx, req.secretName, metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+		return false, err
+	}
+	return false, nil
+}
+
+// issue signs the certificate of the request with the CA of the Operator and stores it in the secret of
+// the request, along with its private key and the CA certificate
+func (p *caProvider) issue(ctx context.Context, req *certificateRequest) error {
+	c := p.controller
+	ca, err := loadOperatorCA(ctx, c.kubeClientSet)
+	if err != nil {
+		return err
+	}
+	certPEM, keyPEM, err := ca.sign(req)
+	if err != nil {
+		klog.Errorf("Unexpected error signing the certificate of secret/%s: %v", req.secretName, err)
+		return err
+	}
+	err = c.createCertificateSecret(ctx, req, map[string][]byte{
+		miniov2.CSRPrivateKeyKey:  keyPEM,
+		miniov2.CSRCertificateKey: certPEM,
+		miniov2.CACertificateKey:  ca.certPEM,
+	})
+	if err != nil {
+		klog.Errorf("Unexpected error during the creation of the secret/%s: %v", req.secretName, err)
+		return err
+	}
+	return nil
+}
+
+// checkCABundle publishes the CA of the Operator in a config map of the tenant when it signs the AutoCert
+// certificates of the tenant, and removes the config map otherwise
+func (c *Controller) checkCABundle(ctx context.Context, tenant *miniov2.Tenant) error {
+	client := c.kubeClientSet.CoreV1().ConfigMaps(tenant.Namespace)
+	existing, err := client.Get(ctx, tenant.CABundleConfigMapName(), metav1.GetOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	if err == nil && !metav1.IsControlledBy(existing, tenant) {
+		return nil
+	}
+
+	if _, ok := c.tenantCertificateProvider(tenant).(*caProvider); !ok || !tenant.AutoCert() {
+		if err != nil {
+			return nil
+		}
+		klog.Infof("Removing configmap/%s of Tenant '%s/%s'", existing.Name, tenant.Namespace, tenant.Name)
+		if err = client.Delete(ctx, existing.Name, metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	ca, caErr := loadOperatorCA(ctx, c.kubeClientSet)
+	if caErr != nil {
+		return caErr
+	}
+	expected := configmaps.CABundleConfigMap(tenant, ca.certPEM)
+	if err != nil {
+		klog.Infof("Creating configmap/%s for Tenant '%s/%s'", expected.Name, tenant.Namespace, tenant.Name)
+		_, err = client.Create(ctx, expected, metav1.CreateOptions{})
+		return err
+	}
+	if reflect.DeepEqual(existing.Data, expected.Data) {
+		return nil
+	}
+	klog.Infof("Updating configmap/%s of Tenant '%s/%s'", existing.Name, tenant.Namespace, tenant.Name)
+	existing = existing.DeepCopy()
+	existing.Data = expected.Data
+	_, err = client.Update(ctx, existing, metav1.UpdateOptions{})
+	return err
+}
@@ -0,0 +1,186 @@
+// This file is part of MinIO Operator
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"testing"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testOperatorCA(t *testing.T) *operatorCA {
+	certPEM, keyPEM, err := newOperatorCA()
+	if err != nil {
+		t.Fatalf("newOperatorCA() error = %v", err)
+	}
+	kubeClient := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      miniov2.OperatorCATLSSecretName,
+			Namespace: miniov2.GetNSFromFile(),
+		},
+		Data: map[string][]byte{
+			miniov2.CACertificateKey: certPEM,
+			miniov2.CAPrivateKeyKey:  keyPEM,
+		},
+	})
+	ca, err := loadOperatorCA(context.Background(), kubeClient)
+	if err != nil {
+		t.Fatalf("loadOperatorCA() error = %v", err)
+	}
+	return ca
+}
+
+func Test_operatorCA_sign(t *testing.T) {
+	ca := testOperatorCA(t)
+	if !ca.cert.IsCA {
+		t.Fatal("newOperatorCA() isn't a CA")
+	}
+	tenant := certificateTestTenant()
+
+	for _, req := range []*certificateRequest{minioCertificateRequest(tenant, ""), minioClientCertificateRequest(tenant, "")} {
+		certPEM, keyPEM, err := ca.sign(req)
+		if err != nil {
+			t.Fatalf("sign() error = %v", err)
+		}
+		if len(keyPEM) == 0 {
+			t.Error("sign() returned an empty private key")
+		}
+		if !bytes.HasSuffix(certPEM, ca.certPEM) {
+			t.Error("sign() certificate isn't followed by the CA certificate")
+		}
+		if !ca.signed(certPEM) {
+			t.Error("sign() certificate isn't signed by the CA")
+		}
+		cert, err := parseCertificate(bytes.NewReader(certPEM))
+		if err != nil {
+			t.Fatalf("sign() returned an invalid certificate: %v", err)
+		}
+		usage := x509.ExtKeyUsageServerAuth
+		if req.client {
+			usage = x509.ExtKeyUsageClientAuth
+		}
+		roots := x509.NewCertPool()
+		roots.AddCert(ca.cert)
+		if _, err = cert.Verify(x509.VerifyOptions{DNSName: req.dnsNames[0], Roots: roots, KeyUsages: []x509.ExtKeyUsage{usage}}); err != nil {
+			t.Errorf("sign() certificate of secret/%s doesn't verify: %v", req.secretName, err)
+		}
+	}
+
+	if other := testOperatorCA(t); other.signed(ca.certPEM) {
+		t.Error("signed() = true for a certificate of another CA")
+	}
+}
+
+func Test_caProvider_check(t *testing.T) {
+	caCertPEM, caKeyPEM, err := newOperatorCA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	caSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      miniov2.OperatorCATLSSecretName,
+			Namespace: miniov2.GetNSFromFile(),
+		},
+		Data: map[string][]byte{
+			miniov2.CACertificateKey: caCertPEM,
+			miniov2.CAPrivateKeyKey:  caKeyPEM,
+		},
+	}
+	ca, err := loadOperatorCA(context.Background(), fake.NewSimpleClientset(caSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := testOperatorCA(t)
+	tenant := certificateTestTenant()
+	req := minioCertificateRequest(tenant, "")
+	signedPEM, _, err := ca.sign(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPEM, _, err := other.sign(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := func(certPEM []byte, controlled bool) *corev1.Secret {
+		s := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: req.secretName, Namespace: req.namespace},
+			Data:       map[string][]byte{miniov2.CSRCertificateKey: certPEM},
+		}
+		if controlled {
+			s.OwnerReferences = []metav1.OwnerReference{req.ownerReference()}
+		}
+		return s
+	}
+	tests := []struct {
+		name        string
+		secret      *corev1.Secret
+		want        bool
+		wantErr     bool
+		wantDeleted bool
+	}{
+		{
+			name: "no secret",
+		},
+		{
+			name:   "signed by the CA of the operator",
+			secret: secret(signedPEM, true),
+			want:   true,
+		},
+		{
+			name:        "signed by another CA for the tenant",
+			secret:      secret(otherPEM, true),
+			wantDeleted: true,
+		},
+		{
+			name:    "provisioned by the user",
+			secret:  secret(otherPEM, false),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := []runtime.Object{caSecret}
+			if tt.secret != nil {
+				objects = append(objects, tt.secret)
+			}
+			kubeClient := fake.NewSimpleClientset(objects...)
+			p := &caProvider{controller: &Controller{kubeClientSet: kubeClient}}
+			got, err := p.check(context.Background(), req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("check() = %v, want %v", got, tt.want)
+			}
+			if tt.secret == nil {
+				return
+			}
+			_, err = kubeClient.CoreV1().Secrets(req.namespace).Get(context.Background(), req.secretName, metav1.GetOptions{})
+			if deleted := k8serrors.IsNotFound(err); deleted != tt.wantDeleted {
+				t.Errorf("check() deleted the secret = %v, want %v", deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
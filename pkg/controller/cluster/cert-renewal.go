@@ -0,0 +1,147 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// renewedByOperator returns true if the Operator renews the certificates of the provider, cert-manager renews
+// the certificates it issues by itself
+func renewedByOperator(provider certificateProvider) bool {
+	_, ok := provider.(*certManagerProvider)
+	return !ok
+}
+
+// lifetimeFraction returns the time the given fraction of the lifetime of the certificate elapsed
+func lifetimeFraction(cert *x509.Certificate, fraction float64) time.Time {
+	lifetime := cert.NotAfter.Sub(cert.NotBefore)
+	// the status only keeps seconds
+	return cert.NotBefore.Add(time.Duration(float64(lifetime) * fraction)).Round(time.Second)
+}
+
+// expiryWarningTime returns the time after which the expiry of the certificate is reported, halfway between
+// its renewal and its expiry. cert-manager renews certificates after two thirds of their lifetime by default.
+func expiryWarningTime(cert *x509.Certificate) time.Time {
+	fraction := miniov2.GetCertRenewalFraction()
+	return lifetimeFraction(cert, fraction+(1-fraction)/2)
+}
+
+// issuedCertificate returns the certificate stored in the secret of the request along with its status
+func (c *Controller) issuedCertificate(ctx context.Context, req *certificateRequest, provider certificateProvider) (*x509.Certificate, *miniov2.IssuedCertificate, error) {
+	secret, err := c.kubeClientSet.CoreV1().Secrets(req.namespace).Get(ctx, req.secretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	certBytes, ok := secret.Data[miniov2.TLSCertificateKey]
+	if !ok {
+		certBytes = secret.Data[miniov2.CSRCertificateKey]
+	}
+	cert, err := parseCertificate(bytes.NewReader(certBytes))
+	if err != nil {
+		klog.Errorf("Unexpected error during the parsing the secret/%s: %v", req.secretName, err)
+		return nil, nil, err
+	}
+	issued := &miniov2.IssuedCertificate{
+		SecretName:   req.secretName,
+		SerialNumber: cert.SerialNumber.Text(16),
+		NotBefore:    metav1.NewTime(cert.NotBefore),
+		NotAfter:     metav1.NewTime(cert.NotAfter),
+	}
+	if renewedByOperator(provider) {
+		renewalTime := metav1.NewTime(lifetimeFraction(cert, miniov2.GetCertRenewalFraction()))
+		issued.RenewalTime = &renewalTime
+	}
+	return cert, issued, nil
+}
+
+// renewCertificate renews the certificate of the request once the renewal fraction of its lifetime elapsed,
+// unless cert-manager renews it. It returns the certificate stored in the secret of the request along with
+// its status.
+func (c *Controller) renewCertificate(ctx context.Context, req *certificateRequest, provider certificateProvider) (*x509.Certificate, *miniov2.IssuedCertificate, error) {
+	cert, issued, err := c.issuedCertificate(ctx, req, provider)
+	if err != nil || issued.RenewalTime == nil || time.Now().Before(issued.RenewalTime.Time) {
+		return cert, issued, err
+	}
+	klog.Infof("Renewing the certificate of secret/%s expiring on %s", req.secretName, cert.NotAfter.Format(time.RFC3339))
+	if err = provider.issue(ctx, req); err != nil {
+		return nil, nil, err
+	}
+	return c.issuedCertificate(ctx, req, provider)
+}
+
+// trackTenantCertificate renews the AutoCert certificate of the request when needed, records its validity in
+// the status of the tenant and exports its expiry. A renewed certificate rolls the pods of the tenant.
+func (c *Controller) trackTenantCertificate(ctx context.Context, tenant *miniov2.Tenant, req *certificateRequest, provider certificateProvider) error {
+	cert, issued, err := c.renewCertificate(ctx, req, provider)
+	if err != nil {
+		return err
+	}
+	c.metrics.setCertificateExpiry(tenant.Namespace, tenant.Name, req.secretName, cert.NotAfter)
+	if time.Now().After(expiryWarningTime(cert)) {
+		c.recorder.Event(tenant, corev1.EventTypeWarning, "CertificateExpiring",
+			fmt.Sprintf("The certificate of secret %s expires on %s", req.secretName, cert.NotAfter.Format(time.RFC3339)))
+	}
+
+	previous := tenant.IssuedCertificate(req.secretName)
+	if previous != nil && equality.Semantic.DeepEqual(*previous, *issued) {
+		return nil
+	}
+	if previous == nil {
+		c.recorder.Event(tenant, corev1.EventTypeNormal, "CertificateIssued",
+			fmt.Sprintf("The certificate of secret %s was issued, it expires on %s", req.secretName, cert.NotAfter.Format(time.RFC3339)))
+	}
+	// pods don't reload the certificates, restart them with the new one
+	roll := previous != nil && previous.SerialNumber != issued.SerialNumber
+	if roll {
+		klog.Infof("Certificate of secret/%s of Tenant '%s/%s' renewed, restarting the pods", req.secretName, tenant.Namespace, tenant.Name)
+		c.recorder.Event(tenant, corev1.EventTypeNormal, "CertificateRenewed",
+			fmt.Sprintf("The certificate of secret %s was renewed, it expires on %s", req.secretName, cert.NotAfter.Format(time.RFC3339)))
+	}
+	t, err := c.updateIssuedCertificateStatus(ctx, tenant, issued, roll)
+	if err != nil {
+		return err
+	}
+	*tenant = *t
+	return nil
+}
+
+// updateCertificateSecret replaces the data of the existing secret of the request with a renewed certificate
+func (c *Controller) updateCertificateSecret(ctx context.Context, req *certificateRequest, data map[string][]byte) error {
+	secret, err := c.kubeClientSet.CoreV1().Secrets(req.namespace).Get(ctx, req.secretName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if !metav1.IsControlledBy(secret, req.owner) {
+		return fmt.Errorf("secret/%s isn't controlled by %s, refusing to replace its certificate", req.secretName, req.owner.GetName())
+	}
+	secret = secret.DeepCopy()
+	secret.Data = data
+	_, err = c.kubeClientSet.CoreV1().Secrets(req.namespace).Update(ctx, secret, metav1.UpdateOptions{})
+	return err
+}
@@ -0,0 +1,58 @@
+// This file is part of MinIO Operator
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cluster
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+)
+
+func Test_lifetimeFraction(t *testing.T) {
+	notBefore := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	cert := &x509.Certificate{
+		NotBefore: notBefore,
+		NotAfter:  notBefore.Add(90 * 24 * time.Hour),
+	}
+	tests := []struct {
+		fraction float64
+		want     time.Time
+	}{
+		{fraction: 0, want: notBefore},
+		{fraction: 2.0 / 3.0, want: notBefore.Add(60 * 24 * time.Hour)},
+		{fraction: 1, want: cert.NotAfter},
+	}
+	for _, tt := range tests {
+		if got := lifetimeFraction(cert, tt.fraction); !got.Equal(tt.want) {
+			t.Errorf("lifetimeFraction(%f) = %v, want %v", tt.fraction, got, tt.want)
+		}
+	}
+
+	// the default renewal after two thirds of the lifetime warns after five sixths of it
+	if got, want := expiryWarningTime(cert), notBefore.Add(75*24*time.Hour); !got.Equal(want) {
+		t.Errorf("expiryWarningTime() = %v, want %v", got, want)
+	}
+}
+
+func Test_renewedByOperator(t *testing.T) {
+	if !renewedByOperator(&csrProvider{}) || !renewedByOperator(&caProvider{}) {
+		t.Error("renewedByOperator() = false for certificates issued by the Operator")
+	}
+	if renewedByOperator(&certManagerProvider{}) {
+		t.Error("renewedByOperator() = true for certificates renewed by cert-manager")
+	}
+}
@@ -0,0 +1,223 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+)
+
+var errCertificatePending = errors.New("waiting for the certificate to be issued")
+
+// certificateRequest describes a certificate the Operator generates and the secret storing it
+type certificateRequest struct {
+	// name of the cluster wide CertificateSigningRequest, cert-manager Certificates are named after the secret
+	name       string
+	namespace  string
+	secretName string
+	labels     map[string]string
+	// owner of the request and the secret, along with its kind
+	owner        metav1.Object
+	ownerKind    string
+	commonName   string
+	organization []string
+	dnsNames     []string
+	// client certificates authenticate MinIO against KES, all others are serving certificates
+	client bool
+}
+
+// ownerReference returns the controller reference of the resources created for the request
+func (r *certificateRequest) ownerReference() metav1.OwnerReference {
+	return *metav1.NewControllerRef(r.owner, schema.GroupVersionKind{
+		Group:   miniov2.SchemeGroupVersion.Group,
+		Version: miniov2.SchemeGroupVersion.Version,
+		Kind:    r.ownerKind,
+	})
+}
+
+// certificateProvider issues the certificates the Operator generates for the tenants and itself
+type certificateProvider interface {
+	// check returns true if the secret of the request holds a certificate issued by the provider
+	check(ctx context.Context, req *certificateRequest) (bool, error)
+	// issue requests the certificate and stores it along with its private key in the secret of the
+	// request. Providers issuing certificates asynchronously return errCertificatePending.
+	issue(ctx context.Context, req *certificateRequest) error
+}
+
+// tenantCertificateProvider returns the provider of the AutoCert certificates of the tenant
+func (c *Controller) tenantCertificateProvider(tenant *miniov2.Tenant) certificateProvider {
+	if tenant.HasCertManagerEnabled() {
+		return &certManagerProvider{
+			controller: c,
+			issuerRef:  tenant.Spec.CertConfig.CertManager.IssuerRef,
+		}
+	}
+	if miniov2.GetOperatorInternalCA() {
+		return &caProvider{controller: c}
+	}
+	return &csrProvider{controller: c}
+}
+
+// operatorCertificateProvider returns the provider of the TLS certificate of the Operator
+func (c *Controller) operatorCertificateProvider() certificateProvider {
+	if issuerRef := miniov2.GetOperatorCertManagerIssuer(); issuerRef != nil {
+		return &certManagerProvider{
+			controller: c,
+			issuerRef:  *issuerRef,
+		}
+	}
+	if miniov2.GetOperatorInternalCA() {
+		return &caProvider{controller: c}
+	}
+	return &csrProvider{controller: c}
+}
+
+// checkTenantCertificate makes sure the AutoCert certificate of the request is issued and renewed, reporting
+// the given state on the tenant while it is requested
+func (c *Controller) checkTenantCertificate(ctx context.Context, tenant *miniov2.Tenant, req *certificateRequest, waitingState string) error {
+	provider := c.tenantCertificateProvider(tenant)
+	issued, err := provider.check(ctx, req)
+	if err != nil {
+		return err
+	}
+	if !issued {
+		if _, err = c.updateTenantStatus(ctx, tenant, waitingState, 0); err != nil {
+			return err
+		}
+		klog.V(2).Infof("Requesting the certificate of secret/%s for Tenant '%s/%s'", req.secretName, tenant.Namespace, tenant.Name)
+		c.recorder.Event(tenant, corev1.EventTypeNormal, "CertificateRequested",
+			fmt.Sprintf("Requesting the certificate of secret %s", req.secretName))
+		if err = provider.issue(ctx, req); err != nil {
+			return err
+		}
+	}
+	return c.trackTenantCertificate(ctx, tenant, req, provider)
+}
+
+// createCertificateSecret creates the secret of the request with the given data, or updates it when the
+// certificate is renewed
+func (c *Controller) createCertificateSecret(ctx context.Context, req *certificateRequest, data map[string][]byte) error {
+	secret := &corev1.Secret{
+		Type: "Opaque",
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            req.secretName,
+			Namespace:       req.namespace,
+			Labels:          req.labels,
+			OwnerReferences: []metav1.OwnerReference{req.ownerReference()},
+		},
+		Data: data,
+	}
+	_, err := c.kubeClientSet.CoreV1().Secrets(req.namespace).Create(ctx, secret, metav1.CreateOptions{})
+	if k8serrors.IsAlreadyExists(err) {
+		return c.updateCertificateSecret(ctx, req, data)
+	}
+	return err
+}
+
+// minioCertificateRequest returns the request of the serving certificate of the MinIO pods
+func minioCertificateRequest(tenant *miniov2.Tenant, hostsTemplate string) *certificateRequest {
+	var dnsNames []string
+	hosts := tenant.AllMinIOHosts()
+	if hostsTemplate != "" {
+		hosts = tenant.TemplatedMinIOHosts(hostsTemplate)
+	}
+	if isEqual(tenant.Spec.CertConfig.DNSNames, hosts) {
+		dnsNames = tenant.Spec.CertConfig.DNSNames
+	} else {
+		dnsNames = append(tenant.Spec.CertConfig.DNSNames, hosts...)
+	}
+	dnsNames = append(dnsNames, tenant.MinIOBucketBaseWildcardDomain())
+
+	return &certificateRequest{
+		name:         tenant.MinIOCSRName(),
+		namespace:    tenant.Namespace,
+		secretName:   tenant.MinIOTLSSecretName(),
+		labels:       tenant.MinIOPodLabels(),
+		owner:        tenant,
+		ownerKind:    miniov2.MinIOCRDResourceKind,
+		commonName:   tenant.Spec.CertConfig.CommonName,
+		organization: tenant.Spec.CertConfig.OrganizationName,
+		dnsNames:     dnsNames,
+	}
+}
+
+// minioClientCertificateRequest returns the request of the certificate MinIO authenticates with against KES
+func minioClientCertificateRequest(tenant *miniov2.Tenant, hostsTemplate string) *certificateRequest {
+	req := minioCertificateRequest(tenant, hostsTemplate)
+	req.name = tenant.MinIOClientCSRName()
+	req.secretName = tenant.MinIOClientTLSSecretName()
+	req.client = true
+	return req
+}
+
+// kesCertificateRequest returns the request of the serving certificate of the KES pods
+func kesCertificateRequest(tenant *miniov2.Tenant) *certificateRequest {
+	return &certificateRequest{
+		name:         tenant.KESCSRName(),
+		namespace:    tenant.Namespace,
+		secretName:   tenant.KESTLSSecretName(),
+		labels:       tenant.KESPodLabels(),
+		owner:        tenant,
+		ownerKind:    miniov2.MinIOCRDResourceKind,
+		commonName:   tenant.KESWildCardName(),
+		organization: tenant.Spec.CertConfig.OrganizationName,
+		dnsNames:     tenant.KESHosts(),
+	}
+}
+
+// consoleCertificateRequest returns the request of the serving certificate of the Console pods
+func consoleCertificateRequest(tenant *miniov2.Tenant) *certificateRequest {
+	return &certificateRequest{
+		name:         tenant.ConsoleCSRName(),
+		namespace:    tenant.Namespace,
+		secretName:   tenant.ConsoleTLSSecretName(),
+		labels:       tenant.ConsolePodLabels(),
+		owner:        tenant,
+		ownerKind:    miniov2.MinIOCRDResourceKind,
+		commonName:   tenant.ConsoleCommonName(),
+		organization: tenant.Spec.CertConfig.OrganizationName,
+		dnsNames:     []string{tenant.ConsoleCIServiceName()},
+	}
+}
+
+// operatorCertificateRequest returns the request of the serving certificate of the Operator webhooks
+func operatorCertificateRequest(operator metav1.Object) *certificateRequest {
+	namespace := miniov2.GetNSFromFile()
+	opCommon := fmt.Sprintf("operator.%s.svc.%s", namespace, miniov2.GetClusterDomain())
+	opCommonNoDomain := fmt.Sprintf("operator.%s.svc", namespace)
+
+	return &certificateRequest{
+		name:         fmt.Sprintf("operator-%s-csr", namespace),
+		namespace:    namespace,
+		secretName:   miniov2.OperatorTLSSecretName,
+		labels:       map[string]string{},
+		owner:        operator,
+		ownerKind:    miniov2.OperatorCRDResourceKind,
+		commonName:   opCommonNoDomain,
+		organization: []string{"system:nodes"},
+		dnsNames:     []string{"operator", opCommonNoDomain, opCommon},
+	}
+}
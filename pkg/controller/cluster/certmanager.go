@@ -0,0 +1,167 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+)
+
+// The cert-manager types aren't vendored, Certificates are handled as unstructured objects
+var certManagerCertificateGVR = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+
+// certManagerProvider issues certificates with cert-manager Certificates signed by an issuer
+type certManagerProvider struct {
+	controller *Controller
+	issuerRef  miniov2.CertManagerIssuerReference
+}
+
+// newCertManagerCertificate returns the cert-manager Certificate of the request. cert-manager stores the
+// certificate in a `kubernetes.io/tls` secret and renews it before it expires.
+func newCertManagerCertificate(req *certificateRequest, issuerRef miniov2.CertManagerIssuerReference) *unstructured.Unstructured {
+	usages := []interface{}{"digital signature", "key encipherment", "server auth"}
+	if req.client {
+		usages = []interface{}{"digital signature", "key encipherment", "client auth"}
+	}
+	dnsNames := make([]interface{}, 0, len(req.dnsNames))
+	for _, dnsName := range req.dnsNames {
+		dnsNames = append(dnsNames, dnsName)
+	}
+	issuer := map[string]interface{}{
+		"name": issuerRef.Name,
+	}
+	if issuerRef.Kind != "" {
+		issuer["kind"] = issuerRef.Kind
+	}
+	if issuerRef.Group != "" {
+		issuer["group"] = issuerRef.Group
+	}
+	labels := make(map[string]interface{}, len(req.labels))
+	for key, value := range req.labels {
+		labels[key] = value
+	}
+
+	spec := map[string]interface{}{
+		"secretName": req.secretName,
+		"secretTemplate": map[string]interface{}{
+			"labels": labels,
+		},
+		"commonName": req.commonName,
+		"dnsNames":   dnsNames,
+		"usages":     usages,
+		"privateKey": map[string]interface{}{
+			"algorithm": "ECDSA",
+		},
+		"issuerRef": issuer,
+	}
+	if len(req.organization) > 0 {
+		organizations := make([]interface{}, 0, len(req.organization))
+		for _, organization := range req.organization {
+			organizations = append(organizations, organization)
+		}
+		spec["subject"] = map[string]interface{}{
+			"organizations": organizations,
+		}
+	}
+
+	certificate := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": spec,
+		},
+	}
+	certificate.SetAPIVersion(certManagerCertificateGVR.GroupVersion().String())
+	certificate.SetKind("Certificate")
+	certificate.SetName(req.secretName)
+	certificate.SetNamespace(req.namespace)
+	certificate.SetLabels(req.labels)
+	certificate.SetOwnerReferences([]metav1.OwnerReference{req.ownerReference()})
+	return certificate
+}
+
+// check updates the Certificate of the request if its spec changed, and returns true if cert-manager
+// stored the certificate in the secret of the request
+func (p *certManagerProvider) check(ctx context.Context, req *certificateRequest) (bool, error) {
+	client := p.controller.dynamicClient.Resource(certManagerCertificateGVR).Namespace(req.namespace)
+	certificate, err := client.Get(ctx, req.secretName, metav1.GetOptions{})
+	if err != nil {
+		// cert-manager not being installed is reported as not found too
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	expected := newCertManagerCertificate(req, p.issuerRef)
+	// DNS names can be removed from the spec, the derivative comparison doesn't detect it
+	expectedDNSNames, _, _ := unstructured.NestedStringSlice(expected.Object, "spec", "dnsNames")
+	dnsNames, _, _ := unstructured.NestedStringSlice(certificate.Object, "spec", "dnsNames")
+	if !equality.Semantic.DeepDerivative(expected.Object["spec"], certificate.Object["spec"]) ||
+		!reflect.DeepEqual(expectedDNSNames, dnsNames) {
+		// cert-manager issues a new certificate for the updated spec
+		klog.Infof("Updating Certificate %s/%s", req.namespace, req.secretName)
+		certificate = certificate.DeepCopy()
+		certificate.Object["spec"] = expected.Object["spec"]
+		if _, err = client.Update(ctx, certificate, metav1.UpdateOptions{}); err != nil {
+			return false, err
+		}
+	}
+
+	secret, err := p.controller.kubeClientSet.CoreV1().Secrets(req.namespace).Get(ctx, req.secretName, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	_, ok := secret.Data[miniov2.TLSCertificateKey]
+	return ok, nil
+}
+
+// issue creates the Certificate of the request, replacing a secret previously issued with a
+// CertificateSigningRequest. It returns errCertificatePending until cert-manager stores the certificate.
+func (p *certManagerProvider) issue(ctx context.Context, req *certificateRequest) error {
+	c := p.controller
+	secret, err := c.kubeClientSet.CoreV1().Secrets(req.namespace).Get(ctx, req.secretName, metav1.GetOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	if err == nil && metav1.IsControlledBy(secret, req.owner) {
+		if _, ok := secret.Data[miniov2.CSRCertificateKey]; ok {
+			klog.Infof("Removing secret/%s issued by another certificate provider", req.secretName)
+			if err = c.kubeClientSet.CoreV1().Secrets(req.namespace).Delete(ctx, req.secretName, metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+
+	client := c.dynamicClient.Resource(certManagerCertificateGVR).Namespace(req.namespace)
+	klog.Infof("Creating Certificate %s/%s", req.namespace, req.secretName)
+	_, err = client.Create(ctx, newCertManagerCertificate(req, p.issuerRef), metav1.CreateOptions{})
+	if err != nil && !k8serrors.IsAlreadyExists(err) {
+		return fmt.Errorf("Unable to create Certificate %s, is cert-manager installed? %v", req.secretName, err)
+	}
+	return errCertificatePending
+}
@@ -0,0 +1,102 @@
+// This file is part of MinIO Operator
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cluster
+
+import (
+	"crypto/x509"
+	"reflect"
+	"testing"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func certificateTestTenant() *miniov2.Tenant {
+	return &miniov2.Tenant{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "tenant",
+			Namespace: "ns",
+			UID:       "uid",
+		},
+		Spec: miniov2.TenantSpec{
+			CertConfig: &miniov2.CertificateConfig{
+				CommonName:       "*.tenant-hl.ns.svc.cluster.local",
+				OrganizationName: []string{"Acme Co"},
+				CertManager: &miniov2.CertManagerConfig{
+					IssuerRef: miniov2.CertManagerIssuerReference{Name: "ca", Kind: "ClusterIssuer"},
+				},
+			},
+			Pools: []miniov2.Pool{{Name: "pool-0", Servers: 4, VolumesPerServer: 1}},
+			KES:   &miniov2.KESConfig{Replicas: 2},
+		},
+	}
+}
+
+func Test_newCertManagerCertificate(t *testing.T) {
+	tenant := certificateTestTenant()
+	req := kesCertificateRequest(tenant)
+	certificate := newCertManagerCertificate(req, tenant.Spec.CertConfig.CertManager.IssuerRef)
+
+	if certificate.GetName() != tenant.KESTLSSecretName() || certificate.GetNamespace() != "ns" {
+		t.Errorf("newCertManagerCertificate() = %s/%s, want ns/%s", certificate.GetNamespace(), certificate.GetName(), tenant.KESTLSSecretName())
+	}
+	if !metav1.IsControlledBy(certificate, tenant) {
+		t.Error("newCertManagerCertificate() isn't controlled by the tenant")
+	}
+	if secretName, _, _ := unstructured.NestedString(certificate.Object, "spec", "secretName"); secretName != tenant.KESTLSSecretName() {
+		t.Errorf("newCertManagerCertificate() secretName = %s, want %s", secretName, tenant.KESTLSSecretName())
+	}
+	if dnsNames, _, _ := unstructured.NestedStringSlice(certificate.Object, "spec", "dnsNames"); !reflect.DeepEqual(dnsNames, tenant.KESHosts()) {
+		t.Errorf("newCertManagerCertificate() dnsNames = %v, want %v", dnsNames, tenant.KESHosts())
+	}
+	issuerRef, _, _ := unstructured.NestedStringMap(certificate.Object, "spec", "issuerRef")
+	if !reflect.DeepEqual(issuerRef, map[string]string{"name": "ca", "kind": "ClusterIssuer"}) {
+		t.Errorf("newCertManagerCertificate() issuerRef = %v", issuerRef)
+	}
+	usages, _, _ := unstructured.NestedStringSlice(certificate.Object, "spec", "usages")
+	if !reflect.DeepEqual(usages, []string{"digital signature", "key encipherment", "server auth"}) {
+		t.Errorf("newCertManagerCertificate() usages = %v", usages)
+	}
+
+	certificate = newCertManagerCertificate(minioClientCertificateRequest(tenant, ""), tenant.Spec.CertConfig.CertManager.IssuerRef)
+	usages, _, _ = unstructured.NestedStringSlice(certificate.Object, "spec", "usages")
+	if !reflect.DeepEqual(usages, []string{"digital signature", "key encipherment", "client auth"}) {
+		t.Errorf("newCertManagerCertificate() usages = %v for a client certificate", usages)
+	}
+}
+
+func Test_generateCryptoData(t *testing.T) {
+	req := consoleCertificateRequest(certificateTestTenant())
+	privKey, csrBytes, err := generateCryptoData(req)
+	if err != nil {
+		t.Fatalf("generateCryptoData() error = %v", err)
+	}
+	if len(privKey) == 0 {
+		t.Error("generateCryptoData() returned an empty private key")
+	}
+	csr, err := x509.ParseCertificateRequest(csrBytes)
+	if err != nil {
+		t.Fatalf("generateCryptoData() returned an invalid CSR: %v", err)
+	}
+	if csr.Subject.CommonName != "system:node:"+req.commonName {
+		t.Errorf("generateCryptoData() CN = %s, want system:node:%s", csr.Subject.CommonName, req.commonName)
+	}
+	if !reflect.DeepEqual(csr.DNSNames, req.dnsNames) {
+		t.Errorf("generateCryptoData() DNS names = %v, want %v", csr.DNSNames, req.dnsNames)
+	}
+}
@@ -19,12 +19,10 @@ package cluster
 
 import (
 	"context"
-	"crypto/rand"
-	"crypto/x509"
-	"crypto/x509/pkix"
-	"encoding/pem"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"hash"
 
 	"github.com/minio/madmin-go"
 
@@ -45,26 +43,10 @@ import (
 	"k8s.io/klog/v2"
 )
 
-func (c *Controller) checkConsoleCertificatesStatus(ctx context.Context, tenant *miniov2.Tenant, nsName types.NamespacedName) error {
-	if tenant.AutoCert() {
-		// AutoCert will generate Console server certificates if user didn't provide any
-		if !tenant.ConsoleExternalCert() {
-			// check if there's already a TLS secret for console
-			_, err := c.kubeClientSet.CoreV1().Secrets(tenant.Namespace).Get(ctx, tenant.ConsoleTLSSecretName(), metav1.GetOptions{})
-			if err != nil {
-				if k8serrors.IsNotFound(err) {
-					if err := c.checkAndCreateConsoleCSR(ctx, nsName, tenant); err != nil {
-						return err
-					}
-					// TLS secret not found, delete CSR if exists and start certificate generation process again
-					if err = c.certClient.CertificateSigningRequests().Delete(ctx, tenant.ConsoleCSRName(), metav1.DeleteOptions{}); err != nil {
-						return err
-					}
-				} else {
-					return err
-				}
-			}
-		}
+func (c *Controller) checkConsoleCertificatesStatus(ctx context.Context, tenant *miniov2.Tenant) error {
+	// AutoCert will generate Console server certificates if user didn't provide any
+	if tenant.AutoCert() && !tenant.ConsoleExternalCert() {
+		return c.checkTenantCertificate(ctx, tenant, consoleCertificateRequest(tenant), StatusWaitingConsoleCert)
 	}
 	return nil
 }
@@ -79,7 +61,7 @@ func (c *Controller) checkConsoleStatus(ctx context.Context, tenant *miniov2.Ten
 		}
 	}
 	if tenant.HasConsoleEnabled() {
-		if err := c.checkConsoleCertificatesStatus(ctx, tenant, nsName); err != nil {
+		if err := c.checkConsoleCertificatesStatus(ctx, tenant); err != nil {
 			return err
 		}
 		// Get the Deployment with the name specified in MirrorInstace.spec
@@ -163,7 +145,7 @@ func (c *Controller) checkConsoleStatus(ctx context.Context, tenant *miniov2.Ten
 
 	var err error
 	// Make sure that MinIO is up and running to enable MinIO console user.
-	if !tenant.MinIOHealthCheck() {
+	if !tenant.MinIOHealthCheck(c.transports.Transport(tenant)) {
 		if _, err = c.updateTenantStatus(ctx, tenant, StatusWaitingForReadyState, totalReplicas); err != nil {
 			return err
 		}
@@ -186,83 +168,45 @@ func (c *Controller) checkConsoleStatus(ctx context.Context, tenant *miniov2.Ten
 
 	if err := tenant.CreateUsers(adminClnt, userCredentials, skipCreateUsers); err != nil {
 		klog.V(2).Infof("Unable to create MinIO users: %v", err)
+		c.recorder.Event(tenant, v1.EventTypeWarning, "UsersSyncFailed", fmt.Sprintf("Unable to create the MinIO users: %v", err))
 		return err
 	}
 
-	return nil
-}
-
-func generateConsoleCryptoData(tenant *miniov2.Tenant) ([]byte, []byte, error) {
-	privateKey, err := newPrivateKey(miniov2.DefaultEllipticCurve)
-	if err != nil {
-		klog.Errorf("Unexpected error during the ECDSA Key generation: %v", err)
-		return nil, nil, err
-	}
-
-	privKeyBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	// the users are created on every sync, only report the credentials that changed
+	h, keyID, err := c.newCredsHMAC(ctx)
 	if err != nil {
-		klog.Errorf("Unexpected error during encoding the ECDSA Private Key: %v", err)
-		return nil, nil, err
-	}
-
-	csrTemplate := x509.CertificateRequest{
-		Subject: pkix.Name{
-			CommonName:   fmt.Sprintf("system:node:%s", tenant.ConsoleCommonName()),
-			Organization: tenant.Spec.CertConfig.OrganizationName,
-		},
-		SignatureAlgorithm: x509.ECDSAWithSHA512,
-		DNSNames:           []string{tenant.ConsoleCIServiceName()},
-		Extensions: []pkix.Extension{
-			{
-				Id:       nil,
-				Critical: false,
-				Value:    []byte(tenant.ConsoleCIServiceName()),
-			},
-		},
-	}
-
-	csrBytes, err := x509.CreateCertificateRequest(rand.Reader, &csrTemplate, privateKey)
-	if err != nil {
-		klog.Errorf("Unexpected error during creating the CSR: %v", err)
-		return nil, nil, err
-	}
-	return privKeyBytes, csrBytes, nil
-}
-
-// createConsoleCSR handles all the steps required to create the CSR: from creation of keys, submitting CSR and
-// finally creating a secret that Console deployment will use to mount private key and certificate for TLS
-// This Method Blocks till the CSR Request is approved via kubectl approve
-func (c *Controller) createConsoleCSR(ctx context.Context, tenant *miniov2.Tenant) error {
-	privKeysBytes, csrBytes, err := generateConsoleCryptoData(tenant)
-	if err != nil {
-		klog.Errorf("Private Key and CSR generation failed with error: %v", err)
 		return err
 	}
-
-	err = c.createCertificateSigningRequest(ctx, tenant.ConsolePodLabels(), tenant.ConsoleCSRName(), tenant.Namespace, csrBytes, tenant, "server")
-	if err != nil {
-		klog.Errorf("Unexpected error during the creation of the csr/%s: %v", tenant.ConsoleCSRName(), err)
-		return err
-	}
-
-	// fetch certificate from CSR
-	certbytes, err := c.fetchCertificate(ctx, tenant.ConsoleCSRName())
-	if err != nil {
-		klog.Errorf("Unexpected error during the creation of the csr/%s: %v", tenant.ConsoleCSRName(), err)
-		return err
+	if hash := usersHash(h, userCredentials, skipCreateUsers); hash != tenant.Status.UsersHash || keyID != tenant.Status.UsersHashKeyID {
+		// a hash recorded with another hash key can't be compared, record it again without reporting a change
+		if keyID == tenant.Status.UsersHashKeyID || tenant.Status.UsersHash == "" {
+			c.recorder.Event(tenant, v1.EventTypeNormal, "UsersSynced", fmt.Sprintf("Created %d MinIO users", len(userCredentials)))
+		}
+		t, err := c.updateStatus(ctx, tenant, func(status *miniov2.TenantStatus) {
+			status.UsersHash = hash
+			status.UsersHashKeyID = keyID
+		})
+		if err != nil {
+			return err
+		}
+		*tenant = *t
 	}
 
-	// PEM encode private ECDSA key
-	encodedPrivKey := pem.EncodeToMemory(&pem.Block{Type: privateKeyType, Bytes: privKeysBytes})
+	return nil
+}
 
-	// Create secret for Console Deployment to use
-	err = c.createSecret(ctx, tenant, tenant.ConsolePodLabels(), tenant.ConsoleTLSSecretName(), encodedPrivKey, certbytes)
-	if err != nil {
-		klog.Errorf("Unexpected error during the creation of the secret/%s: %v", tenant.ConsoleTLSSecretName(), err)
-		return err
+// usersHash returns the HMAC of the credentials of the users created on MinIO
+func usersHash(h hash.Hash, userCredentials []*v1.Secret, skipCreateUsers bool) string {
+	fmt.Fprintf(h, "%t", skipCreateUsers)
+	for _, secret := range userCredentials {
+		h.Write([]byte{0})
+		h.Write([]byte(secret.Name))
+		h.Write([]byte{0})
+		h.Write(secret.Data["CONSOLE_ACCESS_KEY"])
+		h.Write([]byte{0})
+		h.Write(secret.Data["CONSOLE_SECRET_KEY"])
 	}
-
-	return nil
+	return hex.EncodeToString(h.Sum(nil))
 }
 
 // consoleDeploymentMatchesSpec checks if the deployment for console matches what is expected and described from the Tenant
@@ -288,24 +232,6 @@ func consoleDeploymentMatchesSpec(tenant *miniov2.Tenant, consoleDeployment *app
 	return true, nil
 }
 
-func (c *Controller) checkAndCreateConsoleCSR(ctx context.Context, nsName types.NamespacedName, tenant *miniov2.Tenant) error {
-	if _, err := c.certClient.CertificateSigningRequests().Get(ctx, tenant.ConsoleCSRName(), metav1.GetOptions{}); err != nil {
-		if k8serrors.IsNotFound(err) {
-			if tenant, err = c.updateTenantStatus(ctx, tenant, StatusWaitingConsoleCert, 0); err != nil {
-				return err
-			}
-			klog.V(2).Infof("Creating a new Certificate Signing Request for Console Server Certs, cluster %q", nsName)
-			if err = c.createConsoleCSR(ctx, tenant); err != nil {
-				return err
-			}
-			// we want to re-queue this tenant so we can re-check for the console certificate
-			return errors.New("waiting for console cert")
-		}
-		return err
-	}
-	return nil
-}
-
 func (c *Controller) checkConsoleSvc(ctx context.Context, tenant *miniov2.Tenant, nsName types.NamespacedName) error {
 	// check the status of the console service
 	consoleSvc, err := c.serviceLister.Services(tenant.Namespace).Get(tenant.ConsoleCIServiceName())
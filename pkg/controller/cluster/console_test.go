@@ -25,8 +25,26 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// consoleTestProbe returns the probe of a Console container
+func consoleTestProbe() *corev1.Probe {
+	return &corev1.Probe{
+		Handler: corev1.Handler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path:   "/",
+				Port:   intstr.FromInt(miniov2.ConsolePort),
+				Scheme: corev1.URISchemeHTTP,
+			},
+		},
+		TimeoutSeconds:   miniov2.MinIOProbeTimeoutSeconds,
+		PeriodSeconds:    miniov2.MinIOProbePeriodSeconds,
+		SuccessThreshold: 1,
+		FailureThreshold: miniov2.MinIOReadinessFailureThreshold,
+	}
+}
+
 func Test_consoleDeploymentMatchesSpec(t *testing.T) {
 	type args struct {
 		tenant            *miniov2.Tenant
@@ -64,6 +82,9 @@ func Test_consoleDeploymentMatchesSpec(t *testing.T) {
 								Labels: map[string]string{
 									miniov2.ConsoleTenantLabel: "tenant-a-console",
 								},
+								Annotations: map[string]string{
+									miniov2.Revision: "0",
+								},
 							},
 							Spec: corev1.PodSpec{
 								RestartPolicy: corev1.RestartPolicyAlways,
@@ -83,7 +104,7 @@ func Test_consoleDeploymentMatchesSpec(t *testing.T) {
 										Env: []corev1.EnvVar{
 											{
 												Name:  "CONSOLE_MINIO_SERVER",
-												Value: "https://minio..svc.cluster.local:443",
+												Value: "https://tenant-a-minio..svc.cluster.local:443",
 											},
 											{
 												Name:  "x",
@@ -104,6 +125,8 @@ func Test_consoleDeploymentMatchesSpec(t *testing.T) {
 											"server",
 											"--certs-dir=/tmp/certs",
 										},
+										LivenessProbe:  consoleTestProbe(),
+										ReadinessProbe: consoleTestProbe(),
 										VolumeMounts: []corev1.VolumeMount{
 											{
 												Name:      "tenant-a-console",
@@ -184,6 +207,9 @@ func Test_consoleDeploymentMatchesSpec(t *testing.T) {
 								Labels: map[string]string{
 									miniov2.ConsoleTenantLabel: "tenant-a-console",
 								},
+								Annotations: map[string]string{
+									miniov2.Revision: "0",
+								},
 							},
 							Spec: corev1.PodSpec{
 								RestartPolicy: corev1.RestartPolicyAlways,
@@ -203,7 +229,7 @@ func Test_consoleDeploymentMatchesSpec(t *testing.T) {
 										Env: []corev1.EnvVar{
 											{
 												Name:  "CONSOLE_MINIO_SERVER",
-												Value: "https://minio..svc.cluster.local:443",
+												Value: "https://tenant-a-minio..svc.cluster.local:443",
 											},
 										},
 										Ports: []corev1.ContainerPort{
@@ -220,6 +246,8 @@ func Test_consoleDeploymentMatchesSpec(t *testing.T) {
 											"server",
 											"--certs-dir=/tmp/certs",
 										},
+										LivenessProbe:  consoleTestProbe(),
+										ReadinessProbe: consoleTestProbe(),
 										VolumeMounts: []corev1.VolumeMount{
 											{
 												Name:      "tenant-a-console",
@@ -306,6 +334,9 @@ func Test_consoleDeploymentMatchesSpec(t *testing.T) {
 								Labels: map[string]string{
 									miniov2.ConsoleTenantLabel: "tenant-a-console",
 								},
+								Annotations: map[string]string{
+									miniov2.Revision: "0",
+								},
 							},
 							Spec: corev1.PodSpec{
 								RestartPolicy: corev1.RestartPolicyAlways,
@@ -331,7 +362,7 @@ func Test_consoleDeploymentMatchesSpec(t *testing.T) {
 										Env: []corev1.EnvVar{
 											{
 												Name:  "CONSOLE_MINIO_SERVER",
-												Value: "https://minio..svc.cluster.local:443",
+												Value: "https://tenant-a-minio..svc.cluster.local:443",
 											},
 											{
 												Name:  "x",
@@ -352,6 +383,8 @@ func Test_consoleDeploymentMatchesSpec(t *testing.T) {
 											"server",
 											"--certs-dir=/tmp/certs",
 										},
+										LivenessProbe:  consoleTestProbe(),
+										ReadinessProbe: consoleTestProbe(),
 										VolumeMounts: []corev1.VolumeMount{
 											{
 												Name:      "tenant-a-console",
@@ -481,6 +514,9 @@ func Test_consoleDeploymentMatchesSpec(t *testing.T) {
 								Labels: map[string]string{
 									miniov2.ConsoleTenantLabel: "tenant-a-console",
 								},
+								Annotations: map[string]string{
+									miniov2.Revision: "0",
+								},
 							},
 							Spec: corev1.PodSpec{
 								RestartPolicy: corev1.RestartPolicyAlways,
@@ -500,7 +536,7 @@ func Test_consoleDeploymentMatchesSpec(t *testing.T) {
 										Env: []corev1.EnvVar{
 											{
 												Name:  "CONSOLE_MINIO_SERVER",
-												Value: "https://minio..svc.cluster.local:443",
+												Value: "https://tenant-a-minio..svc.cluster.local:443",
 											},
 											{
 												Name:  "x",
@@ -521,6 +557,8 @@ func Test_consoleDeploymentMatchesSpec(t *testing.T) {
 											"server",
 											"--certs-dir=/tmp/certs",
 										},
+										LivenessProbe:  consoleTestProbe(),
+										ReadinessProbe: consoleTestProbe(),
 										VolumeMounts: []corev1.VolumeMount{
 											{
 												Name:      "tenant-a-console",
@@ -607,6 +645,9 @@ func Test_consoleDeploymentMatchesSpec(t *testing.T) {
 								Labels: map[string]string{
 									miniov2.ConsoleTenantLabel: "tenant-a-console",
 								},
+								Annotations: map[string]string{
+									miniov2.Revision: "0",
+								},
 							},
 							Spec: corev1.PodSpec{
 								RestartPolicy: corev1.RestartPolicyAlways,
@@ -626,7 +667,7 @@ func Test_consoleDeploymentMatchesSpec(t *testing.T) {
 										Env: []corev1.EnvVar{
 											{
 												Name:  "CONSOLE_MINIO_SERVER",
-												Value: "https://minio..svc.cluster.local:443",
+												Value: "https://tenant-a-minio..svc.cluster.local:443",
 											},
 											{
 												Name:  "x",
@@ -647,6 +688,8 @@ func Test_consoleDeploymentMatchesSpec(t *testing.T) {
 											"server",
 											"--certs-dir=/tmp/certs",
 										},
+										LivenessProbe:  consoleTestProbe(),
+										ReadinessProbe: consoleTestProbe(),
 										VolumeMounts: []corev1.VolumeMount{
 											{
 												Name:      "tenant-a-console",
@@ -736,6 +779,9 @@ func Test_consoleDeploymentMatchesSpec(t *testing.T) {
 								Labels: map[string]string{
 									miniov2.ConsoleTenantLabel: "tenant-a-console",
 								},
+								Annotations: map[string]string{
+									miniov2.Revision: "0",
+								},
 							},
 							Spec: corev1.PodSpec{
 								RestartPolicy: corev1.RestartPolicyAlways,
@@ -755,7 +801,7 @@ func Test_consoleDeploymentMatchesSpec(t *testing.T) {
 										Env: []corev1.EnvVar{
 											{
 												Name:  "CONSOLE_MINIO_SERVER",
-												Value: "https://minio..svc.cluster.local:443",
+												Value: "https://tenant-a-minio..svc.cluster.local:443",
 											},
 											{
 												Name:  "x",
@@ -776,6 +822,8 @@ func Test_consoleDeploymentMatchesSpec(t *testing.T) {
 											"server",
 											"--certs-dir=/tmp/certs",
 										},
+										LivenessProbe:  consoleTestProbe(),
+										ReadinessProbe: consoleTestProbe(),
 										VolumeMounts: []corev1.VolumeMount{
 											{
 												Name:      "tenant-a-console",
@@ -847,3 +895,31 @@ func Test_consoleDeploymentMatchesSpec(t *testing.T) {
 func intToPtr(x int32) *int32 {
 	return &x
 }
+
+func Test_usersHash(t *testing.T) {
+	user := func(name, accessKey, secretKey string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Data: map[string][]byte{
+				"CONSOLE_ACCESS_KEY": []byte(accessKey),
+				"CONSOLE_SECRET_KEY": []byte(secretKey),
+			},
+		}
+	}
+	users := []*corev1.Secret{user("console-user", "console", "console123")}
+	if usersHash(testCredsHMAC("key"), users, false) != usersHash(testCredsHMAC("key"), []*corev1.Secret{user("console-user", "console", "console123")}, false) {
+		t.Error("usersHash() should be stable")
+	}
+	if usersHash(testCredsHMAC("key"), users, false) == usersHash(testCredsHMAC("key"), []*corev1.Secret{user("console-user", "console", "console456")}, false) {
+		t.Error("usersHash() should change with the secret key")
+	}
+	if usersHash(testCredsHMAC("key"), users, false) == usersHash(testCredsHMAC("key"), append(users, user("other-user", "other", "other123")), false) {
+		t.Error("usersHash() should change with the users")
+	}
+	if usersHash(testCredsHMAC("key"), users, false) == usersHash(testCredsHMAC("key"), users, true) {
+		t.Error("usersHash() should change when the users are no longer created")
+	}
+	if usersHash(testCredsHMAC("key"), users, false) == usersHash(testCredsHMAC("other key"), users, false) {
+		t.Error("usersHash() should change with the hash key")
+	}
+}
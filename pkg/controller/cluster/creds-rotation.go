@@ -0,0 +1,228 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package cluster
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/minio/madmin-go"
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/klog/v2"
+)
+
+// ErrCredsRotationInProgress is returned while the pools are restarted with new root credentials
+var ErrCredsRotationInProgress = errors.New("Waiting for the pools to restart with the new root credentials")
+
+// credsHash returns the HMAC of the root credentials stored in the credentials secret of a tenant
+func credsHash(h hash.Hash, data map[string][]byte) string {
+	h.Write(data["accesskey"])
+	h.Write([]byte{0})
+	h.Write(data["secretkey"])
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// statefulSetRolledOut tells whether every replica of the statefulset runs its latest revision and is ready
+func statefulSetRolledOut(ss *appsv1.StatefulSet) bool {
+	replicas := int32(1)
+	if ss.Spec.Replicas != nil {
+		replicas = *ss.Spec.Replicas
+	}
+	return ss.Status.ObservedGeneration >= ss.Generation &&
+		ss.Status.UpdateRevision == ss.Status.CurrentRevision &&
+		ss.Status.UpdatedReplicas == replicas &&
+		ss.Status.ReadyReplicas == replicas
+}
+
+// checkCredsRotation starts a rotation when the root credentials in the credentials secret no longer match the
+// ones the pools run with. The revision of the tenant is increased so the pod templates of every pool are updated
+// with them, completeCredsRotation then restarts the pods.
+func (c *Controller) checkCredsRotation(ctx context.Context, tenant *miniov2.Tenant, minioSecret *corev1.Secret) (*miniov2.Tenant, error) {
+	h, keyID, err := c.newCredsHMAC(ctx)
+	if err != nil {
+		return tenant, err
+	}
+	hash := credsHash(h, minioSecret.Data)
+	switch {
+	case tenant.Status.CredsHashKeyID != keyID:
+		// new tenants, and tenants deployed before the credentials were tracked, already run with these. The hashes
+		// recorded with another hash key can't be compared, the credentials are assumed unchanged rather than
+		// restarting every tenant when the key is generated again.
+		return c.updateStatus(ctx, tenant, func(status *miniov2.TenantStatus) {
+			if status.PendingCredsHash != "" {
+				status.PendingCredsHash = hash
+			} else {
+				status.CredsHash = hash
+			}
+			status.CredsHashKeyID = keyID
+		})
+	case tenant.Status.PendingCredsHash == hash:
+		// rotation already in progress
+		return tenant, nil
+	case tenant.Status.PendingCredsHash == "" && tenant.Status.CredsHash == hash:
+		return tenant, nil
+	}
+
+	klog.Infof("Root credentials of Tenant '%s/%s' changed, restarting all pools", tenant.Namespace, tenant.Name)
+	c.recorder.Event(tenant, corev1.EventTypeNormal, "CredentialsRotationStarted",
+		fmt.Sprintf("Secret %s changed, restarting all pools with the new root credentials", minioSecret.Name))
+	return c.updateStatus(ctx, tenant, func(status *miniov2.TenantStatus) {
+		status.PendingCredsHash = hash
+		status.Revision++
+	})
+}
+
+// restartStalePods deletes at once the pods of the statefulset not running its latest revision. The pods of a
+// tenant authenticate each other with the root credentials, a pod restarted alone with the new ones would never
+// rejoin its peers, so they aren't left to the rolling update of the statefulset.
+func (c *Controller) restartStalePods(ctx context.Context, ss *appsv1.StatefulSet) error {
+	pods, err := c.kubeClientSet.CoreV1().Pods(ss.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: metav1.FormatLabelSelector(ss.Spec.Selector),
+	})
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp != nil || pod.Labels[appsv1.ControllerRevisionHashLabelKey] == ss.Status.UpdateRevision {
+			continue
+		}
+		klog.Infof("Restarting pod %s/%s with the new root credentials", pod.Namespace, pod.Name)
+		if err = c.kubeClientSet.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// completeCredsRotation restarts all the pods of the tenant together once every pool has been updated with the
+// rotated root credentials, then regenerates everything derived from them and records the rotation.
+func (c *Controller) completeCredsRotation(ctx context.Context, tenant *miniov2.Tenant, adminClnt *madmin.AdminClient, minioSecret *corev1.Secret, totalReplicas int32) (*miniov2.Tenant, error) {
+	if tenant.Status.PendingCredsHash == "" {
+		return tenant, nil
+	}
+
+	var pools []*appsv1.StatefulSet
+	rolledOut := true
+	for pi := range tenant.Spec.Pools {
+		if tenant.PoolDecommissioned(pi) {
+			continue
+		}
+		ss, err := c.statefulSetLister.StatefulSets(tenant.Namespace).Get(tenant.Status.Pools[pi].SSName)
+		if err != nil {
+			return tenant, err
+		}
+		// the statefulset may not have been updated with the revision restarting the pool yet
+		if ss.Spec.Template.Annotations[miniov2.Revision] != fmt.Sprintf("%d", tenant.Status.Revision) || ss.Status.ObservedGeneration < ss.Generation {
+			if _, err = c.updateTenantStatus(ctx, tenant, StatusRotatingCredentials, totalReplicas); err != nil {
+				return tenant, err
+			}
+			return tenant, ErrCredsRotationInProgress
+		}
+		pools = append(pools, ss)
+		rolledOut = rolledOut && statefulSetRolledOut(ss)
+	}
+	if !rolledOut {
+		for _, ss := range pools {
+			if err := c.restartStalePods(ctx, ss); err != nil {
+				return tenant, err
+			}
+		}
+		if _, err := c.updateTenantStatus(ctx, tenant, StatusRotatingCredentials, totalReplicas); err != nil {
+			return tenant, err
+		}
+		return tenant, ErrCredsRotationInProgress
+	}
+	if !tenant.MinIOHealthCheck(c.transports.Transport(tenant)) {
+		return tenant, ErrMinIONotReady
+	}
+
+	accessKey, secretKey := string(minioSecret.Data["accesskey"]), string(minioSecret.Data["secretkey"])
+	if tenant.HasPrometheusEnabled() {
+		if _, err := c.checkAndCreatePrometheusConfigMap(ctx, tenant, accessKey, secretKey); err != nil {
+			return tenant, err
+		}
+	}
+	if tenant.HasPrometheusSMEnabled() {
+		if err := c.checkAndCreatePrometheusServiceMonitorSecret(ctx, tenant, accessKey, secretKey); err != nil {
+			return tenant, err
+		}
+	}
+	if tenant.HasLogEnabled() {
+		if err := c.reconfigureLogSearchAPI(ctx, tenant, adminClnt); err != nil {
+			return tenant, err
+		}
+	}
+
+	klog.Infof("Root credentials of Tenant '%s/%s' rotated", tenant.Namespace, tenant.Name)
+	c.recorder.Event(tenant, corev1.EventTypeNormal, "CredentialsRotated", "All pools run with the new root credentials")
+	return c.updateStatus(ctx, tenant, func(status *miniov2.TenantStatus) {
+		now := metav1.Now()
+		status.CredsHash = status.PendingCredsHash
+		status.PendingCredsHash = ""
+		status.CredsRotationTime = &now
+	})
+}
+
+// reconfigureLogSearchAPI sets the audit webhook of the tenant again, replacing any configuration
+// MinIO may have lost with the previous root credentials
+func (c *Controller) reconfigureLogSearchAPI(ctx context.Context, tenant *miniov2.Tenant, adminClnt *madmin.AdminClient) error {
+	logSecret, err := c.checkAndCreateLogSecret(ctx, tenant)
+	if err != nil {
+		return err
+	}
+	if err = c.checkLogSearchAPIReady(tenant); err != nil {
+		klog.V(2).Info(err)
+		return ErrLogSearchNotReady
+	}
+	restart, err := adminClnt.SetConfigKV(ctx, newAuditWebhookConfig(tenant, logSecret).args)
+	if err != nil {
+		return err
+	}
+	if restart {
+		// Restart MinIO for config update to take effect
+		return adminClnt.ServiceRestart(ctx)
+	}
+	return nil
+}
+
+// handleCredsSecret enqueues the tenants using the given secret as their credentials secret
+func (c *Controller) handleCredsSecret(obj interface{}) {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		return
+	}
+	tenants, err := c.tenantsLister.Tenants(secret.Namespace).List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	for _, tenant := range tenants {
+		if tenant.HasCredsSecret() && tenant.Spec.CredsSecret.Name == secret.Name {
+			c.enqueueTenant(tenant)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+// This file is part of MinIO Operator
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	fakeminio "github.com/minio/operator/pkg/client/clientset/versioned/fake"
+	"github.com/minio/operator/pkg/resources/secrets"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func Test_credsHash(t *testing.T) {
+	creds := map[string][]byte{"accesskey": []byte("minio"), "secretkey": []byte("minio123")}
+	if credsHash(testCredsHMAC("key"), creds) != credsHash(testCredsHMAC("key"), map[string][]byte{"accesskey": []byte("minio"), "secretkey": []byte("minio123")}) {
+		t.Error("credsHash() should be stable")
+	}
+	if credsHash(testCredsHMAC("key"), creds) == credsHash(testCredsHMAC("key"), map[string][]byte{"accesskey": []byte("minio"), "secretkey": []byte("minio456")}) {
+		t.Error("credsHash() should change with the secret key")
+	}
+	if credsHash(testCredsHMAC("key"), creds) == credsHash(testCredsHMAC("key"), map[string][]byte{"accesskey": []byte("minio1"), "secretkey": []byte("23minio")}) {
+		t.Error("credsHash() should not depend on the concatenation of the keys")
+	}
+	if credsHash(testCredsHMAC("key"), creds) == credsHash(testCredsHMAC("other key"), creds) {
+		t.Error("credsHash() should change with the hash key")
+	}
+}
+
+func Test_checkCredsRotation(t *testing.T) {
+	oldKey := "0123456789abcdef0123456789abcdef"
+	newKey := "fedcba9876543210fedcba9876543210"
+	creds := map[string][]byte{"accesskey": []byte("minio"), "secretkey": []byte("minio123")}
+	rotated := map[string][]byte{"accesskey": []byte("minio"), "secretkey": []byte("rotated123")}
+	tests := []struct {
+		name        string
+		status      miniov2.TenantStatus
+		data        map[string][]byte
+		wantPending bool
+		wantHash    string
+	}{
+		{
+			name:     "Record the credentials of a new tenant",
+			data:     creds,
+			wantHash: credsHash(testCredsHMAC(newKey), creds),
+		},
+		{
+			name:     "Unchanged credentials",
+			status:   miniov2.TenantStatus{CredsHash: credsHash(testCredsHMAC(newKey), creds), CredsHashKeyID: hashKeyID([]byte(newKey))},
+			data:     creds,
+			wantHash: credsHash(testCredsHMAC(newKey), creds),
+		},
+		{
+			name:        "Rotate changed credentials",
+			status:      miniov2.TenantStatus{CredsHash: credsHash(testCredsHMAC(newKey), creds), CredsHashKeyID: hashKeyID([]byte(newKey))},
+			data:        rotated,
+			wantPending: true,
+			wantHash:    credsHash(testCredsHMAC(newKey), creds),
+		},
+		{
+			name:     "Record the credentials again without rotating them when the hash key is regenerated",
+			status:   miniov2.TenantStatus{CredsHash: credsHash(testCredsHMAC(oldKey), creds), CredsHashKeyID: hashKeyID([]byte(oldKey))},
+			data:     creds,
+			wantHash: credsHash(testCredsHMAC(newKey), creds),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tenant := &miniov2.Tenant{ObjectMeta: metav1.ObjectMeta{Name: "tenant", Namespace: "ns"}, Status: tt.status}
+			c := &Controller{
+				kubeClientSet: fake.NewSimpleClientset(&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: miniov2.OperatorHashKeySecretName, Namespace: miniov2.GetNSFromFile()},
+					Data:       map[string][]byte{miniov2.HashKeyKey: []byte(newKey)},
+				}),
+				minioClientSet: fakeminio.NewSimpleClientset(tenant),
+				recorder:       record.NewFakeRecorder(10),
+			}
+			got, err := c.checkCredsRotation(context.Background(), tenant, &corev1.Secret{Data: tt.data})
+			if err != nil {
+				t.Fatalf("checkCredsRotation() error = %v", err)
+			}
+			if pending := got.Status.PendingCredsHash != ""; pending != tt.wantPending {
+				t.Errorf("checkCredsRotation() started a rotation = %v, want %v", pending, tt.wantPending)
+			}
+			if tt.wantPending != (got.Status.Revision != 0) {
+				t.Errorf("checkCredsRotation() revision = %d", got.Status.Revision)
+			}
+			if got.Status.CredsHash != tt.wantHash {
+				t.Errorf("checkCredsRotation() CredsHash = %s, want %s", got.Status.CredsHash, tt.wantHash)
+			}
+			if got.Status.CredsHashKeyID != hashKeyID([]byte(newKey)) {
+				t.Errorf("checkCredsRotation() CredsHashKeyID = %s, want %s", got.Status.CredsHashKeyID, hashKeyID([]byte(newKey)))
+			}
+		})
+	}
+}
+
+func Test_statefulSetRolledOut(t *testing.T) {
+	replicas := int32(4)
+	rolledOut := func(mutate func(ss *appsv1.StatefulSet)) bool {
+		ss := &appsv1.StatefulSet{
+			Spec: appsv1.StatefulSetSpec{Replicas: &replicas},
+			Status: appsv1.StatefulSetStatus{
+				CurrentRevision: "rev-2",
+				UpdateRevision:  "rev-2",
+				UpdatedReplicas: 4,
+				ReadyReplicas:   4,
+			},
+		}
+		mutate(ss)
+		return statefulSetRolledOut(ss)
+	}
+
+	if !rolledOut(func(ss *appsv1.StatefulSet) {}) {
+		t.Error("statefulSetRolledOut() = false for an up to date statefulset")
+	}
+	if rolledOut(func(ss *appsv1.StatefulSet) { ss.Generation = 3; ss.Status.ObservedGeneration = 2 }) {
+		t.Error("statefulSetRolledOut() = true for a statefulset not observed yet")
+	}
+	if rolledOut(func(ss *appsv1.StatefulSet) { ss.Status.CurrentRevision = "rev-1"; ss.Status.UpdatedReplicas = 2 }) {
+		t.Error("statefulSetRolledOut() = true while the pods are updated")
+	}
+	if rolledOut(func(ss *appsv1.StatefulSet) { ss.Status.ReadyReplicas = 3 }) {
+		t.Error("statefulSetRolledOut() = true while a pod is not ready")
+	}
+}
+
+func TestUpdatePromServiceMonitorSecret(t *testing.T) {
+	tenant := &miniov2.Tenant{}
+	tenant.Name = "tenant-a"
+	existing := secrets.PromServiceMonitorSecret(tenant, "minio", "minio123")
+
+	if secrets.UpdatePromServiceMonitorSecret(tenant, "minio", "minio123", existing) != nil {
+		t.Error("UpdatePromServiceMonitorSecret() should not update a token signed with the current credentials")
+	}
+	updated := secrets.UpdatePromServiceMonitorSecret(tenant, "minio", "rotated123", existing)
+	if updated == nil {
+		t.Fatal("UpdatePromServiceMonitorSecret() should update a token signed with rotated credentials")
+	}
+	if secrets.UpdatePromServiceMonitorSecret(tenant, "minio", "rotated123", updated) != nil {
+		t.Error("UpdatePromServiceMonitorSecret() token should be signed with the rotated credentials")
+	}
+	if secrets.UpdatePromServiceMonitorSecret(tenant, "admin", "rotated123", updated) == nil {
+		t.Error("UpdatePromServiceMonitorSecret() should update a token issued for another access key")
+	}
+}
+
+func Test_restartStalePods(t *testing.T) {
+	ss := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant-pool-0", Namespace: "ns"},
+		Spec: appsv1.StatefulSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{miniov2.PoolLabel: "pool-0"}},
+		},
+		Status: appsv1.StatefulSetStatus{UpdateRevision: "tenant-pool-0-2"},
+	}
+	pod := func(name, revision, pool string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "ns",
+			Labels:    map[string]string{miniov2.PoolLabel: pool, appsv1.ControllerRevisionHashLabelKey: revision},
+		}}
+	}
+	c := &Controller{kubeClientSet: fake.NewSimpleClientset(
+		pod("tenant-pool-0-0", "tenant-pool-0-1", "pool-0"),
+		pod("tenant-pool-0-1", "tenant-pool-0-1", "pool-0"),
+		pod("tenant-pool-0-2", "tenant-pool-0-2", "pool-0"),
+		pod("tenant-pool-1-0", "tenant-pool-1-1", "pool-1"),
+	)}
+	if err := c.restartStalePods(context.Background(), ss); err != nil {
+		t.Fatalf("restartStalePods() error = %v", err)
+	}
+	pods, err := c.kubeClientSet.CoreV1().Pods("ns").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, pod := range pods.Items {
+		names = append(names, pod.Name)
+	}
+	if len(names) != 2 || names[0] != "tenant-pool-0-2" || names[1] != "tenant-pool-1-0" {
+		t.Errorf("restartStalePods() left pods %v, want tenant-pool-0-2 and tenant-pool-1-0", names)
+	}
+}
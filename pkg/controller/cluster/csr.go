@@ -23,6 +23,7 @@ import (
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/pem"
 	"errors"
 	"fmt"
@@ -38,11 +39,9 @@ import (
 	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
 
 	certificates "k8s.io/api/certificates/v1"
-	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 const (
@@ -67,15 +66,132 @@ func isEqual(a, b []string) bool {
 	return true
 }
 
-// createCertificateSigningRequest is equivalent to kubectl create <csr-name> and kubectl approve csr <csr-name>
-func (c *Controller) createCertificateSigningRequest(ctx context.Context, labels map[string]string, name, namespace string, csrBytes []byte, owner metav1.Object, usage string) error {
+// csrProvider issues certificates with Kubernetes CertificateSigningRequests approved by the Operator
+type csrProvider struct {
+	controller *Controller
+}
+
+// check returns true if the secret of the request holds a certificate issued with a CertificateSigningRequest.
+// A secret issued by cert-manager for the owner of the request is removed, it uses different keys, a secret the
+// owner doesn't control is left alone.
+func (p *csrProvider) check(ctx context.Context, req *certificateRequest) (bool, error) {
+	secret, err := p.controller.kubeClientSet.CoreV1().Secrets(req.namespace).Get(ctx, req.secretName, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if _, ok := secret.Data[miniov2.CSRCertificateKey]; ok {
+		return true, nil
+	}
+	if !metav1.IsControlledBy(secret, req.owner) {
+		return false, fmt.Errorf("secret/%s has no %s and isn't controlled by %s, refusing to replace it", req.secretName, miniov2.CSRCertificateKey, req.owner.GetName())
+	}
+	klog.Infof("Removing secret/%s issued by another certificate provider", req.secretName)
+	if err = p.controller.kubeClientSet.CoreV1().Secrets(req.namespace).Delete(ctx, req.secretName, metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+		return false, err
+	}
+	return false, nil
+}
+
+// issue handles all the steps required to create the CSR: from creation of keys, submitting CSR and
+// finally creating the secret of the request with the private key and certificate.
+// This Method Blocks till the CSR Request is approved
+func (p *csrProvider) issue(ctx context.Context, req *certificateRequest) error {
+	c := p.controller
+	// A CSR left over by an interrupted request is useless without its private key, start again
+	if err := c.certClient.CertificateSigningRequests().Delete(ctx, req.name, metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+
+	privKeysBytes, csrBytes, err := generateCryptoData(req)
+	if err != nil {
+		klog.Errorf("Private Key and CSR generation failed with error: %v", err)
+		return err
+	}
+
+	err = c.createCertificateSigningRequest(ctx, req, csrBytes)
+	if err != nil {
+		klog.Errorf("Unexpected error during the creation of the csr/%s: %v", req.name, err)
+		return err
+	}
+
+	// fetch certificate from CSR
+	certBytes, err := c.fetchCertificate(ctx, req.name)
+	if err != nil {
+		klog.Errorf("Unexpected error during the creation of the csr/%s: %v", req.name, err)
+		return err
+	}
+
+	// PEM encode private ECDSA key
+	encodedPrivKey := pem.EncodeToMemory(&pem.Block{Type: privateKeyType, Bytes: privKeysBytes})
+
+	err = c.createCertificateSecret(ctx, req, map[string][]byte{
+		miniov2.CSRPrivateKeyKey:  encodedPrivKey,
+		miniov2.CSRCertificateKey: certBytes,
+	})
+	if err != nil {
+		klog.Errorf("Unexpected error during the creation of the secret/%s: %v", req.secretName, err)
+		return err
+	}
+	return nil
+}
+
+// generateCryptoData returns a new private key and the CSR of the request signed with it
+func generateCryptoData(req *certificateRequest) ([]byte, []byte, error) {
+	privateKey, err := newPrivateKey(miniov2.DefaultEllipticCurve)
+	if err != nil {
+		klog.Errorf("Unexpected error during the ECDSA Key generation: %v", err)
+		return nil, nil, err
+	}
+
+	privKeyBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		klog.Errorf("Unexpected error during encoding the ECDSA Private Key: %v", err)
+		return nil, nil, err
+	}
+
+	klog.V(0).Infof("Generating CSR with CN=%s", req.commonName)
+
+	var csrExtensions []pkix.Extension
+	for _, dnsName := range req.dnsNames {
+		csrExtensions = append(csrExtensions, pkix.Extension{
+			Id:       nil,
+			Critical: false,
+			Value:    []byte(dnsName),
+		})
+	}
+
+	csrTemplate := x509.CertificateRequest{
+		Subject: pkix.Name{
+			CommonName:   fmt.Sprintf("system:node:%s", req.commonName),
+			Organization: req.organization,
+		},
+		SignatureAlgorithm: x509.ECDSAWithSHA512,
+		DNSNames:           req.dnsNames,
+		Extensions:         csrExtensions,
+	}
+
+	csrBytes, err := x509.CreateCertificateRequest(rand.Reader, &csrTemplate, privateKey)
+	if err != nil {
+		klog.Errorf("Unexpected error during creating the CSR: %v", err)
+		return nil, nil, err
+	}
+	return privKeyBytes, csrBytes, nil
+}
+
+// createCertificateSigningRequest is equivalent to kubectl create <csr-name> and kubectl approve csr <csr-name>.
+// The CSR is addressed to the signer configured for the Operator, the Kubernetes signers of serving and client
+// certificates by default.
+func (c *Controller) createCertificateSigningRequest(ctx context.Context, req *certificateRequest, csrBytes []byte) error {
 	csrSignerName := "kubernetes.io/kubelet-serving"
 	csrKeyUsage := []certificates.KeyUsage{
 		certificates.UsageDigitalSignature,
 		certificates.UsageKeyEncipherment,
 		certificates.UsageServerAuth,
 	}
-	if usage == "client" {
+	if req.client {
 		csrSignerName = "kubernetes.io/kube-apiserver-client"
 		csrKeyUsage = []certificates.KeyUsage{
 			certificates.UsageDigitalSignature,
@@ -83,6 +199,10 @@ func (c *Controller) createCertificateSigningRequest(ctx context.Context, labels
 			certificates.UsageClientAuth,
 		}
 	}
+	if signerName := miniov2.GetCSRSignerName(); signerName != "" {
+		csrSignerName = signerName
+	}
+	name := req.name
 	encodedBytes := pem.EncodeToMemory(&pem.Block{Type: csrType, Bytes: csrBytes})
 	kubeCSR := &certificates.CertificateSigningRequest{
 		TypeMeta: v1.TypeMeta{
@@ -90,16 +210,10 @@ func (c *Controller) createCertificateSigningRequest(ctx context.Context, labels
 			Kind:       "CertificateSigningRequest",
 		},
 		ObjectMeta: v1.ObjectMeta{
-			Name:      name,
-			Labels:    labels,
-			Namespace: namespace,
-			OwnerReferences: []metav1.OwnerReference{
-				*metav1.NewControllerRef(owner, schema.GroupVersionKind{
-					Group:   miniov2.SchemeGroupVersion.Group,
-					Version: miniov2.SchemeGroupVersion.Version,
-					Kind:    miniov2.MinIOCRDResourceKind,
-				}),
-			},
+			Name:            name,
+			Labels:          req.labels,
+			Namespace:       req.namespace,
+			OwnerReferences: []metav1.OwnerReference{req.ownerReference()},
 		},
 		Spec: certificates.CertificateSigningRequestSpec{
 			SignerName: csrSignerName,
@@ -185,30 +299,6 @@ func (c *Controller) fetchCertificate(ctx context.Context, csrName string) ([]by
 	}
 }
 
-func (c *Controller) createSecret(ctx context.Context, tenant *miniov2.Tenant, labels map[string]string, secretName string, pkBytes, certBytes []byte) error {
-	secret := &corev1.Secret{
-		Type: "Opaque",
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      secretName,
-			Namespace: tenant.Namespace,
-			Labels:    labels,
-			OwnerReferences: []metav1.OwnerReference{
-				*metav1.NewControllerRef(tenant, schema.GroupVersionKind{
-					Group:   miniov2.SchemeGroupVersion.Group,
-					Version: miniov2.SchemeGroupVersion.Version,
-					Kind:    miniov2.MinIOCRDResourceKind,
-				}),
-			},
-		},
-		Data: map[string][]byte{
-			"private.key": pkBytes,
-			"public.crt":  certBytes,
-		},
-	}
-	_, err := c.kubeClientSet.CoreV1().Secrets(tenant.Namespace).Create(ctx, secret, metav1.CreateOptions{})
-	return err
-}
-
 func parseCertificate(r io.Reader) (*x509.Certificate, error) {
 	certPEMBlock, err := ioutil.ReadAll(r)
 	if err != nil {
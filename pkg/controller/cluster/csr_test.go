@@ -0,0 +1,95 @@
+// This file is part of MinIO Operator
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_csrProvider_check(t *testing.T) {
+	tenant := certificateTestTenant()
+	req := minioCertificateRequest(tenant, "")
+	secret := func(data map[string][]byte, controlled bool) *corev1.Secret {
+		s := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: req.secretName, Namespace: req.namespace},
+			Data:       data,
+		}
+		if controlled {
+			s.OwnerReferences = []metav1.OwnerReference{req.ownerReference()}
+		}
+		return s
+	}
+	certManagerData := map[string][]byte{miniov2.TLSCertificateKey: []byte("cert"), miniov2.TLSPrivateKeyKey: []byte("key")}
+	tests := []struct {
+		name        string
+		secret      *corev1.Secret
+		want        bool
+		wantErr     bool
+		wantDeleted bool
+	}{
+		{
+			name: "no secret",
+		},
+		{
+			name:   "issued with a CSR",
+			secret: secret(map[string][]byte{miniov2.CSRCertificateKey: []byte("cert")}, false),
+			want:   true,
+		},
+		{
+			name:        "issued by cert-manager for the tenant",
+			secret:      secret(certManagerData, true),
+			wantDeleted: true,
+		},
+		{
+			name:    "provisioned by the user",
+			secret:  secret(certManagerData, false),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var objects []runtime.Object
+			if tt.secret != nil {
+				objects = append(objects, tt.secret)
+			}
+			kubeClient := fake.NewSimpleClientset(objects...)
+			p := &csrProvider{controller: &Controller{kubeClientSet: kubeClient}}
+			got, err := p.check(context.Background(), req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("check() = %v, want %v", got, tt.want)
+			}
+			if tt.secret == nil {
+				return
+			}
+			_, err = kubeClient.CoreV1().Secrets(req.namespace).Get(context.Background(), req.secretName, metav1.GetOptions{})
+			if deleted := k8serrors.IsNotFound(err); deleted != tt.wantDeleted {
+				t.Errorf("check() deleted the secret = %v, want %v", deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package cluster
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/minio/madmin-go"
+	"github.com/minio/minio-go/v7/pkg/s3utils"
+	"github.com/minio/minio-go/v7/pkg/signer"
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	"github.com/minio/operator/pkg/resources/statefulsets"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+)
+
+// MinIO admin API for pools, not available yet on the madmin-go release in use
+const (
+	minioAdminPoolsPath        = "/minio/admin/v3/pools"
+	minioAdminPoolDecommission = minioAdminPoolsPath + "/decommission"
+	minioAdminPoolStatus       = minioAdminPoolsPath + "/status"
+)
+
+// minioPoolStatus is the status of a pool as reported by the MinIO admin API
+type minioPoolStatus struct {
+	ID           int                        `json:"id"`
+	CmdLine      string                     `json:"cmdline"`
+	LastUpdate   time.Time                  `json:"lastUpdate"`
+	Decommission *minioPoolDecommissionInfo `json:"decommissionInfo,omitempty"`
+}
+
+// minioPoolDecommissionInfo is the progress of the decommission of a pool
+type minioPoolDecommissionInfo struct {
+	StartTime                 time.Time `json:"startTime"`
+	StartSize                 int64     `json:"startSize"`
+	TotalSize                 int64     `json:"totalSize"`
+	CurrentSize               int64     `json:"currentSize"`
+	Complete                  bool      `json:"complete"`
+	Failed                    bool      `json:"failed"`
+	Canceled                  bool      `json:"canceled"`
+	ObjectsDecommissioned     int64     `json:"objectsDecommissioned"`
+	ObjectsDecommissionFailed int64     `json:"objectsDecommissionedFailed"`
+}
+
+// minioPoolAdminRequest performs a signed request against the pools admin API of the tenant
+func minioPoolAdminRequest(ctx context.Context, tenant *miniov2.Tenant, tr *http.Transport, minioSecret map[string][]byte, method, apiPath, pool string) ([]byte, error) {
+	accessKey, ok := minioSecret["accesskey"]
+	if !ok {
+		return nil, errors.New("MinIO server accesskey not set")
+	}
+	secretKey, ok := minioSecret["secretkey"]
+	if !ok {
+		return nil, errors.New("MinIO server secretkey not set")
+	}
+
+	target := tenant.MinIOServerEndpoint() + apiPath + "?" + s3utils.QueryEncode(url.Values{"pool": []string{pool}})
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(nil)
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(sum[:]))
+	req = signer.SignV4(*req, string(accessKey), string(secretKey), "", "")
+
+	httpClient := &http.Client{
+		Transport: tr,
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		apiErr := madmin.ErrorResponse{}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Message != "" {
+			return nil, apiErr
+		}
+		return nil, fmt.Errorf("MinIO pools API %s returned %s", apiPath, resp.Status)
+	}
+	return body, nil
+}
+
+// startPoolDecommission asks MinIO to start moving the objects out of the pool
+func startPoolDecommission(ctx context.Context, tenant *miniov2.Tenant, tr *http.Transport, minioSecret map[string][]byte, pool string) error {
+	_, err := minioPoolAdminRequest(ctx, tenant, tr, minioSecret, http.MethodPost, minioAdminPoolDecommission, pool)
+	return err
+}
+
+// getPoolDecommissionStatus returns the status of the pool as reported by MinIO
+func getPoolDecommissionStatus(ctx context.Context, tenant *miniov2.Tenant, tr *http.Transport, minioSecret map[string][]byte, pool string) (*minioPoolStatus, error) {
+	body, err := minioPoolAdminRequest(ctx, tenant, tr, minioSecret, http.MethodGet, minioAdminPoolStatus, pool)
+	if err != nil {
+		return nil, err
+	}
+	status := &minioPoolStatus{}
+	if err = json.Unmarshal(body, status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// checkPoolsDecommission drives the decommission of the pools flagged in the tenant spec. Once MinIO
+// finishes moving the objects out of a pool, MinIO is restarted without it and its StatefulSet is removed.
+func (c *Controller) checkPoolsDecommission(ctx context.Context, tenant *miniov2.Tenant, adminClnt *madmin.AdminClient, minioSecret map[string][]byte) (*miniov2.Tenant, error) {
+	var err error
+	for pi, pool := range tenant.Spec.Pools {
+		if !pool.Decommission || len(tenant.Status.Pools) <= pi {
+			continue
+		}
+		switch tenant.Status.Pools[pi].State {
+		case miniov2.PoolInitialized:
+			poolArg := statefulsets.GetPoolContainerArg(tenant, pi, c.hostsTemplate)
+			klog.Infof("Starting decommission of pool %s for Tenant '%s/%s'", pool.Name, tenant.Namespace, tenant.Name)
+			if err = startPoolDecommission(ctx, tenant, c.transports.Transport(tenant), minioSecret, poolArg); err != nil {
+				return tenant, fmt.Errorf("unable to start decommission of pool %s: %w", pool.Name, err)
+			}
+			now := metav1.Now()
+			tenant.Status.Pools[pi].State = miniov2.PoolDecommissioning
+			tenant.Status.Pools[pi].Decommission = &miniov2.PoolDecommissionStatus{
+				StartTime: &now,
+			}
+			if tenant, err = c.updatePoolStatus(ctx, tenant); err != nil {
+				return tenant, err
+			}
+		case miniov2.PoolDecommissioning:
+			poolArg := statefulsets.GetPoolContainerArg(tenant, pi, c.hostsTemplate)
+			status, err := getPoolDecommissionStatus(ctx, tenant, c.transports.Transport(tenant), minioSecret, poolArg)
+			if err != nil {
+				return tenant, fmt.Errorf("unable to get decommission status of pool %s: %w", pool.Name, err)
+			}
+			info := status.Decommission
+			if info == nil {
+				// MinIO lost track of the decommission, start over
+				info = &minioPoolDecommissionInfo{Canceled: true}
+			}
+			startTime := &metav1.Time{Time: info.StartTime}
+			if previous := tenant.Status.Pools[pi].Decommission; previous != nil && previous.StartTime != nil {
+				startTime = previous.StartTime
+			}
+			tenant.Status.Pools[pi].Decommission = &miniov2.PoolDecommissionStatus{
+				StartTime:                 startTime,
+				StartSize:                 info.StartSize,
+				CurrentSize:               info.CurrentSize,
+				TotalSize:                 info.TotalSize,
+				ObjectsDecommissioned:     info.ObjectsDecommissioned,
+				ObjectsDecommissionFailed: info.ObjectsDecommissionFailed,
+			}
+			switch {
+			case info.Complete:
+				klog.Infof("Pool %s of Tenant '%s/%s' decommissioned", pool.Name, tenant.Namespace, tenant.Name)
+				tenant.Status.Pools[pi].State = miniov2.PoolDecommissioned
+			case info.Failed || info.Canceled:
+				// Going back to initialized will request the decommission again on the next sync
+				klog.Warningf("Decommission of pool %s of Tenant '%s/%s' did not complete, retrying", pool.Name, tenant.Namespace, tenant.Name)
+				tenant.Status.Pools[pi].State = miniov2.PoolInitialized
+			}
+			if tenant, err = c.updatePoolStatus(ctx, tenant); err != nil {
+				return tenant, err
+			}
+			if tenant.Status.Pools[pi].State == miniov2.PoolDecommissioned {
+				// Restart the services to fetch the new args without the pool, ignore any error.
+				adminClnt.ServiceRestart(ctx) //nolint:errcheck
+			}
+		}
+	}
+	return tenant, nil
+}
+
+// removeDecommissionedPool deletes the StatefulSet of a decommissioned pool and, if requested, its volumes
+func (c *Controller) removeDecommissionedPool(ctx context.Context, tenant *miniov2.Tenant, pi int) error {
+	pool := tenant.Spec.Pools[pi]
+	ssName := tenant.Status.Pools[pi].SSName
+	if _, err := c.statefulSetLister.StatefulSets(tenant.Namespace).Get(ssName); err == nil {
+		klog.Infof("Deleting StatefulSet %s of decommissioned pool %s", ssName, pool.Name)
+		err = c.kubeClientSet.AppsV1().StatefulSets(tenant.Namespace).Delete(ctx, ssName, metav1.DeleteOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	} else if !k8serrors.IsNotFound(err) {
+		return err
+	}
+	if err := c.removePoolPDB(ctx, tenant, ssName); err != nil {
+		return err
+	}
+
+	if !pool.DeletePVCsOnDecommission {
+		return nil
+	}
+	selector := labels.SelectorFromSet(map[string]string{
+		miniov2.TenantLabel: tenant.Name,
+		miniov2.PoolLabel:   pool.Name,
+	})
+	pvcs, err := c.kubeClientSet.CoreV1().PersistentVolumeClaims(tenant.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return err
+	}
+	for _, pvc := range pvcs.Items {
+		klog.Infof("Deleting PersistentVolumeClaim %s of decommissioned pool %s", pvc.Name, pool.Name)
+		err = c.kubeClientSet.CoreV1().PersistentVolumeClaims(tenant.Namespace).Delete(ctx, pvc.Name, metav1.DeleteOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
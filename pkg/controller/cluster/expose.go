@@ -0,0 +1,190 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	"github.com/minio/operator/pkg/resources/ingresses"
+	"github.com/minio/operator/pkg/resources/routes"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+)
+
+// checkExternalExposure reconciles the Ingresses and Gateway API routes exposing the MinIO and Console
+// services of the tenant, and publishes the resulting external URLs in its status
+func (c *Controller) checkExternalExposure(ctx context.Context, tenant *miniov2.Tenant) (*miniov2.Tenant, error) {
+	// Tenants that never published external URLs have nothing to remove either
+	if !tenant.HasIngressEnabled() && !tenant.HasGatewayEnabled() && tenant.Status.ExternalURLs == nil {
+		return tenant, nil
+	}
+	if err := c.checkIngress(ctx, tenant, tenant.MinIOExternalName(), ingresses.NewForMinIO(tenant)); err != nil {
+		return tenant, err
+	}
+	if err := c.checkIngress(ctx, tenant, tenant.ConsoleExternalName(), ingresses.NewForConsole(tenant)); err != nil {
+		return tenant, err
+	}
+	if err := c.checkRoutes(ctx, tenant, tenant.MinIOExternalName(), routes.NewForMinIO(tenant)); err != nil {
+		return tenant, err
+	}
+	if err := c.checkRoutes(ctx, tenant, tenant.ConsoleExternalName(), routes.NewForConsole(tenant)); err != nil {
+		return tenant, err
+	}
+
+	urls := tenant.ExternalURLs()
+	if reflect.DeepEqual(urls, tenant.Status.ExternalURLs) {
+		return tenant, nil
+	}
+	return c.updateExternalURLs(ctx, tenant, urls)
+}
+
+// annotationsApplied returns whether an Ingress or a route carries the annotations of the tenant, the annotations
+// ingress controllers or cert-manager add aren't compared
+func annotationsApplied(expected, annotations map[string]string) bool {
+	for key, value := range expected {
+		if current, ok := annotations[key]; !ok || current != value {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeAnnotations returns the annotations of an Ingress or a route with the annotations of the tenant set, keeping
+// the ones ingress controllers or cert-manager add
+func mergeAnnotations(annotations, expected map[string]string) map[string]string {
+	merged := make(map[string]string, len(annotations)+len(expected))
+	for key, value := range annotations {
+		merged[key] = value
+	}
+	for key, value := range expected {
+		merged[key] = value
+	}
+	return merged
+}
+
+// checkIngress creates or updates the Ingress of the given name to match the expected one. A nil
+// expected Ingress removes the one previously created for the tenant.
+func (c *Controller) checkIngress(ctx context.Context, tenant *miniov2.Tenant, name string, expected *networkingv1.Ingress) error {
+	ingress, err := c.kubeClientSet.NetworkingV1().Ingresses(tenant.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return err
+		}
+		if expected == nil {
+			return nil
+		}
+		klog.Infof("Creating Ingress %s for Tenant '%s/%s'", name, tenant.Namespace, tenant.Name)
+		_, err = c.kubeClientSet.NetworkingV1().Ingresses(tenant.Namespace).Create(ctx, expected, metav1.CreateOptions{})
+		return err
+	}
+	// Never touch an Ingress of the same name the tenant doesn't own
+	if !metav1.IsControlledBy(ingress, tenant) {
+		if expected != nil {
+			return fmt.Errorf("Ingress %s already exists and isn't owned by Tenant %s", name, tenant.Name)
+		}
+		return nil
+	}
+	if expected == nil {
+		klog.Infof("Removing Ingress %s of Tenant '%s/%s'", name, tenant.Namespace, tenant.Name)
+		return c.kubeClientSet.NetworkingV1().Ingresses(tenant.Namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	}
+	// Hosts and TLS can be removed from the spec, the derivative comparison doesn't detect it
+	if equality.Semantic.DeepDerivative(expected.Spec, ingress.Spec) &&
+		len(expected.Spec.Rules) == len(ingress.Spec.Rules) &&
+		reflect.DeepEqual(expected.Spec.TLS, ingress.Spec.TLS) &&
+		annotationsApplied(expected.Annotations, ingress.Annotations) {
+		return nil
+	}
+	klog.Infof("Updating Ingress %s of Tenant '%s/%s'", name, tenant.Namespace, tenant.Name)
+	ingress = ingress.DeepCopy()
+	ingress.Spec = expected.Spec
+	ingress.Annotations = mergeAnnotations(ingress.Annotations, expected.Annotations)
+	_, err = c.kubeClientSet.NetworkingV1().Ingresses(tenant.Namespace).Update(ctx, ingress, metav1.UpdateOptions{})
+	return err
+}
+
+// checkRoutes creates or updates the Gateway API route of the given name to match the expected one,
+// removing the route of the other kind when the tenant switches between HTTPRoute and TLSRoute. A nil
+// expected route removes the ones previously created for the tenant.
+func (c *Controller) checkRoutes(ctx context.Context, tenant *miniov2.Tenant, name string, expected *unstructured.Unstructured) error {
+	for _, gvr := range []schema.GroupVersionResource{routes.HTTPRouteGVR, routes.TLSRouteGVR} {
+		if expected != nil && gvr == routes.GVR(tenant) {
+			if err := c.checkRoute(ctx, tenant, gvr, name, expected); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := c.checkRoute(ctx, tenant, gvr, name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkRoute creates or updates the route of the given resource and name to match the expected one.
+// A nil expected route removes the one previously created for the tenant.
+func (c *Controller) checkRoute(ctx context.Context, tenant *miniov2.Tenant, gvr schema.GroupVersionResource, name string, expected *unstructured.Unstructured) error {
+	client := c.dynamicClient.Resource(gvr).Namespace(tenant.Namespace)
+	route, err := client.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		// The Gateway API not being installed is reported as not found too
+		if !k8serrors.IsNotFound(err) {
+			return err
+		}
+		if expected == nil {
+			return nil
+		}
+		klog.Infof("Creating %s %s for Tenant '%s/%s'", expected.GetKind(), name, tenant.Namespace, tenant.Name)
+		if _, err = client.Create(ctx, expected, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("Unable to create %s %s, is the Gateway API installed? %v", expected.GetKind(), name, err)
+		}
+		return nil
+	}
+	if !metav1.IsControlledBy(route, tenant) {
+		if expected != nil {
+			return fmt.Errorf("%s %s already exists and isn't owned by Tenant %s", route.GetKind(), name, tenant.Name)
+		}
+		return nil
+	}
+	if expected == nil {
+		klog.Infof("Removing %s %s of Tenant '%s/%s'", route.GetKind(), name, tenant.Namespace, tenant.Name)
+		return client.Delete(ctx, name, metav1.DeleteOptions{})
+	}
+	// Hostnames can be removed from the spec, the derivative comparison doesn't detect it
+	expectedHosts, _, _ := unstructured.NestedStringSlice(expected.Object, "spec", "hostnames")
+	hosts, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+	if equality.Semantic.DeepDerivative(expected.Object["spec"], route.Object["spec"]) &&
+		reflect.DeepEqual(expectedHosts, hosts) &&
+		annotationsApplied(expected.GetAnnotations(), route.GetAnnotations()) {
+		return nil
+	}
+	klog.Infof("Updating %s %s of Tenant '%s/%s'", route.GetKind(), name, tenant.Namespace, tenant.Name)
+	route = route.DeepCopy()
+	route.Object["spec"] = expected.Object["spec"]
+	route.SetAnnotations(mergeAnnotations(route.GetAnnotations(), expected.GetAnnotations()))
+	_, err = client.Update(ctx, route, metav1.UpdateOptions{})
+	return err
+}
@@ -0,0 +1,226 @@
+// This file is part of MinIO Operator
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	"github.com/minio/operator/pkg/resources/ingresses"
+	"github.com/minio/operator/pkg/resources/routes"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newExposeTestTenant() *miniov2.Tenant {
+	return &miniov2.Tenant{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant-a", Namespace: "ns-a", UID: "tenant-a"},
+		Spec: miniov2.TenantSpec{
+			ExposeServices: &miniov2.ExposeServices{
+				Ingress: &miniov2.IngressConfig{
+					MinIOHost:   "minio.example.com",
+					Annotations: map[string]string{"nginx.ingress.kubernetes.io/backend-protocol": "HTTPS"},
+				},
+				Gateway: &miniov2.GatewayConfig{
+					ParentRef:   miniov2.GatewayParentReference{Name: "gateway"},
+					MinIOHost:   "minio.example.com",
+					Annotations: map[string]string{"example.com/annotation": "value"},
+				},
+			},
+		},
+	}
+}
+
+func Test_checkIngress(t *testing.T) {
+	ctx := context.Background()
+	tenant := newExposeTestTenant()
+	name := tenant.MinIOExternalName()
+	kubeClient := fake.NewSimpleClientset()
+	c := &Controller{kubeClientSet: kubeClient}
+	get := func() *networkingv1.Ingress {
+		ingress, err := c.kubeClientSet.NetworkingV1().Ingresses("ns-a").Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ingress
+	}
+
+	// create
+	if err := c.checkIngress(ctx, tenant, name, ingresses.NewForMinIO(tenant)); err != nil {
+		t.Fatalf("checkIngress() error = %v", err)
+	}
+	if ingress := get(); ingress.Spec.Rules[0].Host != "minio.example.com" {
+		t.Errorf("checkIngress() created rules %v", ingress.Spec.Rules)
+	}
+
+	// the annotations other controllers add are kept, and don't trigger updates
+	ingress := get()
+	ingress.Annotations["cert-manager.io/issuer"] = "issuer"
+	if _, err := c.kubeClientSet.NetworkingV1().Ingresses("ns-a").Update(ctx, ingress, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	kubeClient.ClearActions()
+	if err := c.checkIngress(ctx, tenant, name, ingresses.NewForMinIO(tenant)); err != nil {
+		t.Fatalf("checkIngress() error = %v", err)
+	}
+	for _, action := range kubeClient.Actions() {
+		if action.GetVerb() != "get" {
+			t.Errorf("checkIngress() %s an Ingress already matching the tenant", action.GetVerb())
+		}
+	}
+
+	// update
+	tenant.Spec.ExposeServices.Ingress.MinIOHost = "s3.example.com"
+	tenant.Spec.ExposeServices.Ingress.Annotations["nginx.ingress.kubernetes.io/proxy-body-size"] = "0"
+	if err := c.checkIngress(ctx, tenant, name, ingresses.NewForMinIO(tenant)); err != nil {
+		t.Fatalf("checkIngress() error = %v", err)
+	}
+	ingress = get()
+	if ingress.Spec.Rules[0].Host != "s3.example.com" {
+		t.Errorf("checkIngress() updated rules %v, want s3.example.com", ingress.Spec.Rules)
+	}
+	for _, key := range []string{"cert-manager.io/issuer", "nginx.ingress.kubernetes.io/backend-protocol", "nginx.ingress.kubernetes.io/proxy-body-size"} {
+		if _, ok := ingress.Annotations[key]; !ok {
+			t.Errorf("checkIngress() updated annotations %v, missing %s", ingress.Annotations, key)
+		}
+	}
+
+	// delete
+	if err := c.checkIngress(ctx, tenant, name, nil); err != nil {
+		t.Fatalf("checkIngress() error = %v", err)
+	}
+	if _, err := c.kubeClientSet.NetworkingV1().Ingresses("ns-a").Get(ctx, name, metav1.GetOptions{}); !k8serrors.IsNotFound(err) {
+		t.Errorf("checkIngress() didn't remove the Ingress: %v", err)
+	}
+
+	// an Ingress of the same name the tenant doesn't own is never touched
+	foreign := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns-a"}}
+	if _, err := c.kubeClientSet.NetworkingV1().Ingresses("ns-a").Create(ctx, foreign, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.checkIngress(ctx, tenant, name, ingresses.NewForMinIO(tenant)); err == nil {
+		t.Error("checkIngress() replaced an Ingress the tenant doesn't own")
+	}
+	if err := c.checkIngress(ctx, tenant, name, nil); err != nil {
+		t.Errorf("checkIngress() error = %v", err)
+	}
+	if ingress = get(); len(ingress.Spec.Rules) != 0 {
+		t.Errorf("checkIngress() changed an Ingress the tenant doesn't own")
+	}
+}
+
+func Test_checkRoute(t *testing.T) {
+	ctx := context.Background()
+	tenant := newExposeTestTenant()
+	name := tenant.MinIOExternalName()
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	c := &Controller{dynamicClient: dynamicClient}
+	client := c.dynamicClient.Resource(routes.HTTPRouteGVR).Namespace("ns-a")
+	get := func() *unstructured.Unstructured {
+		route, err := client.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return route
+	}
+	hostnames := func(route *unstructured.Unstructured) []string {
+		hosts, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+		return hosts
+	}
+
+	// create
+	if err := c.checkRoute(ctx, tenant, routes.HTTPRouteGVR, name, routes.NewForMinIO(tenant)); err != nil {
+		t.Fatalf("checkRoute() error = %v", err)
+	}
+	if hosts := hostnames(get()); len(hosts) != 1 || hosts[0] != "minio.example.com" {
+		t.Errorf("checkRoute() created hostnames %v", hosts)
+	}
+
+	// the annotations other controllers add are kept, and don't trigger updates
+	route := get()
+	annotations := route.GetAnnotations()
+	annotations["example.com/controller"] = "added"
+	route.SetAnnotations(annotations)
+	if _, err := client.Update(ctx, route, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	dynamicClient.ClearActions()
+	if err := c.checkRoute(ctx, tenant, routes.HTTPRouteGVR, name, routes.NewForMinIO(tenant)); err != nil {
+		t.Fatalf("checkRoute() error = %v", err)
+	}
+	for _, action := range dynamicClient.Actions() {
+		if action.GetVerb() != "get" {
+			t.Errorf("checkRoute() %s a route already matching the tenant", action.GetVerb())
+		}
+	}
+
+	// update
+	tenant.Spec.ExposeServices.Gateway.MinIOHost = "s3.example.com"
+	if err := c.checkRoute(ctx, tenant, routes.HTTPRouteGVR, name, routes.NewForMinIO(tenant)); err != nil {
+		t.Fatalf("checkRoute() error = %v", err)
+	}
+	route = get()
+	if hosts := hostnames(route); len(hosts) != 1 || hosts[0] != "s3.example.com" {
+		t.Errorf("checkRoute() updated hostnames %v, want s3.example.com", hosts)
+	}
+	if annotations = route.GetAnnotations(); annotations["example.com/controller"] != "added" || annotations["example.com/annotation"] != "value" {
+		t.Errorf("checkRoute() updated annotations %v", annotations)
+	}
+
+	// switching to TLS passthrough replaces the HTTPRoute with a TLSRoute
+	tenant.Spec.ExposeServices.Gateway.TLSPassthrough = true
+	if err := c.checkRoutes(ctx, tenant, name, routes.NewForMinIO(tenant)); err != nil {
+		t.Fatalf("checkRoutes() error = %v", err)
+	}
+	if _, err := client.Get(ctx, name, metav1.GetOptions{}); !k8serrors.IsNotFound(err) {
+		t.Errorf("checkRoutes() didn't remove the HTTPRoute: %v", err)
+	}
+	if _, err := c.dynamicClient.Resource(routes.TLSRouteGVR).Namespace("ns-a").Get(ctx, name, metav1.GetOptions{}); err != nil {
+		t.Errorf("checkRoutes() didn't create the TLSRoute: %v", err)
+	}
+
+	// delete
+	if err := c.checkRoutes(ctx, tenant, name, nil); err != nil {
+		t.Fatalf("checkRoutes() error = %v", err)
+	}
+	if _, err := c.dynamicClient.Resource(routes.TLSRouteGVR).Namespace("ns-a").Get(ctx, name, metav1.GetOptions{}); !k8serrors.IsNotFound(err) {
+		t.Errorf("checkRoutes() didn't remove the TLSRoute: %v", err)
+	}
+
+	// a route of the same name the tenant doesn't own is never touched
+	tenant.Spec.ExposeServices.Gateway.TLSPassthrough = false
+	foreign := routes.NewForMinIO(tenant)
+	foreign.SetOwnerReferences(nil)
+	if _, err := client.Create(ctx, foreign, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.checkRoute(ctx, tenant, routes.HTTPRouteGVR, name, routes.NewForMinIO(tenant)); err == nil {
+		t.Error("checkRoute() replaced a route the tenant doesn't own")
+	}
+	if err := c.checkRoute(ctx, tenant, routes.HTTPRouteGVR, name, nil); err != nil {
+		t.Errorf("checkRoute() error = %v", err)
+	}
+	if _, err := client.Get(ctx, name, metav1.GetOptions{}); err != nil {
+		t.Errorf("checkRoute() removed a route the tenant doesn't own: %v", err)
+	}
+}
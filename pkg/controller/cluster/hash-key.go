@@ -0,0 +1,94 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package cluster
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// hashKeySize is the size in bytes of the key of the HMACs of the credentials
+const hashKeySize = 32
+
+// EnsureHashKey generates the key of the HMACs of the credentials the Operator records in the status of the tenants
+// and stores it in the Operator hash key secret, unless the secret already exists. The status of a tenant is
+// readable by users who can't read its secrets, a plain hash would let them guess the credentials offline.
+func EnsureHashKey(ctx context.Context, kubeClient kubernetes.Interface) error {
+	namespace := miniov2.GetNSFromFile()
+	_, err := kubeClient.CoreV1().Secrets(namespace).Get(ctx, miniov2.OperatorHashKeySecretName, metav1.GetOptions{})
+	if err == nil || !k8serrors.IsNotFound(err) {
+		return err
+	}
+	klog.Infof("Generating the hash key of the Operator in secret/%s", miniov2.OperatorHashKeySecretName)
+	key := make([]byte, hashKeySize)
+	if _, err = rand.Read(key); err != nil {
+		return err
+	}
+	secret := &corev1.Secret{
+		Type: "Opaque",
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      miniov2.OperatorHashKeySecretName,
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			miniov2.HashKeyKey: key,
+		},
+	}
+	_, err = kubeClient.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+	if k8serrors.IsAlreadyExists(err) {
+		// Another replica of the Operator generated it first
+		return nil
+	}
+	return err
+}
+
+// hashKeyID identifies a hash key without revealing it, the status of a tenant records the ID of the key its
+// HMACs are keyed with
+func hashKeyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// newCredsHMAC returns a new HMAC of credentials keyed with the hash key of the Operator, and the ID of the key
+func (c *Controller) newCredsHMAC(ctx context.Context) (hash.Hash, string, error) {
+	if key, ok := c.hashKey.Load().([]byte); ok {
+		return hmac.New(sha256.New, key), hashKeyID(key), nil
+	}
+	secret, err := c.kubeClientSet.CoreV1().Secrets(miniov2.GetNSFromFile()).Get(ctx, miniov2.OperatorHashKeySecretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, "", err
+	}
+	key := secret.Data[miniov2.HashKeyKey]
+	if len(key) < hashKeySize {
+		return nil, "", fmt.Errorf("secret/%s has no %s of at least %d bytes", miniov2.OperatorHashKeySecretName, miniov2.HashKeyKey, hashKeySize)
+	}
+	c.hashKey.Store(key)
+	return hmac.New(sha256.New, key), hashKeyID(key), nil
+}
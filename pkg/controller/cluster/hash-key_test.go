@@ -0,0 +1,73 @@
+// This file is part of MinIO Operator
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"hash"
+	"testing"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testCredsHMAC(key string) hash.Hash {
+	return hmac.New(sha256.New, []byte(key))
+}
+
+func Test_newCredsHMAC(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	c := &Controller{kubeClientSet: kubeClient}
+	if _, _, err := c.newCredsHMAC(context.Background()); err == nil {
+		t.Fatal("newCredsHMAC() should fail without the hash key secret")
+	}
+	if err := EnsureHashKey(context.Background(), kubeClient); err != nil {
+		t.Fatalf("EnsureHashKey() error = %v", err)
+	}
+	secret, err := kubeClient.CoreV1().Secrets(miniov2.GetNSFromFile()).Get(context.Background(), miniov2.OperatorHashKeySecretName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := secret.Data[miniov2.HashKeyKey]
+	if len(key) != hashKeySize {
+		t.Fatalf("EnsureHashKey() generated a key of %d bytes, want %d", len(key), hashKeySize)
+	}
+	if err = EnsureHashKey(context.Background(), kubeClient); err != nil {
+		t.Fatalf("EnsureHashKey() error = %v", err)
+	}
+	if secret, _ = kubeClient.CoreV1().Secrets(miniov2.GetNSFromFile()).Get(context.Background(), miniov2.OperatorHashKeySecretName, metav1.GetOptions{}); !bytes.Equal(secret.Data[miniov2.HashKeyKey], key) {
+		t.Error("EnsureHashKey() replaced the existing key")
+	}
+
+	h, keyID, err := c.newCredsHMAC(context.Background())
+	if err != nil {
+		t.Fatalf("newCredsHMAC() error = %v", err)
+	}
+	if keyID != hashKeyID(key) {
+		t.Errorf("newCredsHMAC() key ID = %s, want %s", keyID, hashKeyID(key))
+	}
+	want := hmac.New(sha256.New, key)
+	h.Write([]byte("minio123"))
+	want.Write([]byte("minio123"))
+	if !hmac.Equal(h.Sum(nil), want.Sum(nil)) {
+		t.Error("newCredsHMAC() isn't keyed with the hash key")
+	}
+}
@@ -21,9 +21,12 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
+	admissionv1 "k8s.io/api/admission/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 
@@ -60,8 +63,8 @@ func (c *Controller) BucketSrvHandler(w http.ResponseWriter, r *http.Request) {
 	name := vars["name"]
 	deleteBucket := v.Get("delete")
 
-	secret, err := c.kubeClientSet.CoreV1().Secrets(namespace).Get(r.Context(),
-		miniov2.WebhookSecret, metav1.GetOptions{})
+	webhookSecretName := (&miniov2.Tenant{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}).WebhookSecretName()
+	secret, err := c.kubeClientSet.CoreV1().Secrets(namespace).Get(r.Context(), webhookSecretName, metav1.GetOptions{})
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusForbidden)
 		return
@@ -136,8 +139,8 @@ func (c *Controller) GetenvHandler(w http.ResponseWriter, r *http.Request) {
 	name := vars["name"]
 	key := vars["key"]
 
-	secret, err := c.kubeClientSet.CoreV1().Secrets(namespace).Get(r.Context(),
-		miniov2.WebhookSecret, metav1.GetOptions{})
+	webhookSecretName := (&miniov2.Tenant{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}).WebhookSecretName()
+	secret, err := c.kubeClientSet.CoreV1().Secrets(namespace).Get(r.Context(), webhookSecretName, metav1.GetOptions{})
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusForbidden)
 		return
@@ -313,3 +316,239 @@ func (c *Controller) CRDConversionHandler(w http.ResponseWriter, r *http.Request
 	}
 
 }
+
+// ValidateTenantHandler - POST /webhook/v1/validate-tenant
+func (c *Controller) ValidateTenantHandler(w http.ResponseWriter, r *http.Request) {
+	var review admissionv1.AdmissionReview
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "admission review without a request", http.StatusBadRequest)
+		return
+	}
+
+	review.Response = &admissionv1.AdmissionResponse{
+		UID:     review.Request.UID,
+		Allowed: true,
+	}
+	if err := validateTenantAdmission(review.Request); err != nil {
+		klog.V(2).Infof("Rejecting Tenant '%s/%s': %v", review.Request.Namespace, review.Request.Name, err)
+		review.Response.Allowed = false
+		review.Response.Result = &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Message: err.Error(),
+			Reason:  metav1.StatusReasonInvalid,
+			Code:    http.StatusUnprocessableEntity,
+		}
+	}
+	writeAdmissionReview(w, review)
+}
+
+// validateTenantAdmission runs the Tenant validations on the object being admitted, and for
+// updates makes sure the changes can be applied to the pools that already exist.
+func validateTenantAdmission(req *admissionv1.AdmissionRequest) error {
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return nil
+	}
+	tenant := &miniov2.Tenant{}
+	if err := json.Unmarshal(req.Object.Raw, tenant); err != nil {
+		return err
+	}
+	// Don't block finalizers or metadata changes of a tenant being deleted
+	if tenant.DeletionTimestamp != nil {
+		return nil
+	}
+	tenant.EnsureDefaults()
+	if err := tenant.Validate(); err != nil {
+		return err
+	}
+	if req.Operation == admissionv1.Update {
+		oldTenant := &miniov2.Tenant{}
+		if err := json.Unmarshal(req.OldObject.Raw, oldTenant); err != nil {
+			return err
+		}
+		oldTenant.EnsureDefaults()
+		if err := tenant.ValidateUpdate(oldTenant); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DefaultTenantHandler - POST /webhook/v1/default-tenant
+func (c *Controller) DefaultTenantHandler(w http.ResponseWriter, r *http.Request) {
+	var review admissionv1.AdmissionReview
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "admission review without a request", http.StatusBadRequest)
+		return
+	}
+
+	review.Response = &admissionv1.AdmissionResponse{
+		UID:     review.Request.UID,
+		Allowed: true,
+	}
+	patch, err := defaultTenantAdmission(review.Request, c.pinImageDefaults)
+	if err != nil {
+		klog.V(2).Infof("Unable to set defaults on Tenant '%s/%s': %v", review.Request.Namespace, review.Request.Name, err)
+		review.Response.Allowed = false
+		review.Response.Result = &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Message: err.Error(),
+			Reason:  metav1.StatusReasonBadRequest,
+			Code:    http.StatusBadRequest,
+		}
+	} else if len(patch) > 0 {
+		patchType := admissionv1.PatchTypeJSONPatch
+		review.Response.Patch = patch
+		review.Response.PatchType = &patchType
+	}
+	writeAdmissionReview(w, review)
+}
+
+// defaultTenantAdmission returns the JSON patch that persists the Tenant defaults in the object being admitted
+func defaultTenantAdmission(req *admissionv1.AdmissionRequest, pinImages bool) ([]byte, error) {
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return nil, nil
+	}
+	tenant := &miniov2.Tenant{}
+	if err := json.Unmarshal(req.Object.Raw, tenant); err != nil {
+		return nil, err
+	}
+	// Defaults like the certificate DNS names derive from the name of the tenant,
+	// objects using generateName are left to be defaulted on sync
+	if tenant.DeletionTimestamp != nil || tenant.Name == "" {
+		return nil, nil
+	}
+	if tenant.Namespace == "" {
+		tenant.Namespace = req.Namespace
+	}
+	var original map[string]interface{}
+	if err := json.Unmarshal(req.Object.Raw, &original); err != nil {
+		return nil, err
+	}
+
+	rawSpec, err := json.Marshal(defaultTenant(tenant, pinImages).Spec)
+	if err != nil {
+		return nil, err
+	}
+	var defaulted interface{}
+	if err = json.Unmarshal(rawSpec, &defaulted); err != nil {
+		return nil, err
+	}
+
+	ops := defaultsPatch("/spec", original["spec"], defaulted)
+	if len(ops) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(ops)
+}
+
+// defaultTenant returns a copy of the Tenant with the defaults set, images are only set
+// when pinned, otherwise they keep following the defaults of the running operator.
+func defaultTenant(tenant *miniov2.Tenant, pinImages bool) *miniov2.Tenant {
+	defaulted := tenant.DeepCopy()
+	defaulted.EnsureDefaults()
+	if pinImages {
+		return defaulted
+	}
+
+	if tenant.Spec.Image == "" {
+		defaulted.Spec.Image = ""
+	}
+	if tenant.HasConsoleEnabled() && tenant.Spec.Console.Image == "" {
+		defaulted.Spec.Console.Image = ""
+	}
+	if tenant.HasKESEnabled() && tenant.Spec.KES.Image == "" {
+		defaulted.Spec.KES.Image = ""
+	}
+	if tenant.HasLogEnabled() && tenant.Spec.Log.Image == "" {
+		defaulted.Spec.Log.Image = ""
+	}
+	if tenant.HasPrometheusEnabled() {
+		if tenant.Spec.Prometheus.Image == "" {
+			defaulted.Spec.Prometheus.Image = ""
+		}
+		if tenant.Spec.Prometheus.SideCarImage == "" {
+			defaulted.Spec.Prometheus.SideCarImage = ""
+		}
+		if tenant.Spec.Prometheus.InitImage == "" {
+			defaulted.Spec.Prometheus.InitImage = ""
+		}
+	}
+	return defaulted
+}
+
+// jsonPatchOp is a single RFC 6902 JSON patch operation
+type jsonPatchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// defaultsPatch returns the JSON patch operations that bring the fields set in the defaulted
+// document into the original one, fields missing or null in the defaulted document are left as is.
+func defaultsPatch(path string, original, defaulted interface{}) []jsonPatchOp {
+	if defaulted == nil || reflect.DeepEqual(original, defaulted) {
+		return nil
+	}
+
+	switch d := defaulted.(type) {
+	case map[string]interface{}:
+		if o, ok := original.(map[string]interface{}); ok {
+			keys := make([]string, 0, len(d))
+			for k := range d {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+
+			var ops []jsonPatchOp
+			for _, k := range keys {
+				keyPath := path + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(k)
+				if _, exists := o[k]; !exists {
+					if d[k] != nil {
+						ops = append(ops, jsonPatchOp{Op: "add", Path: keyPath, Value: d[k]})
+					}
+					continue
+				}
+				ops = append(ops, defaultsPatch(keyPath, o[k], d[k])...)
+			}
+			return ops
+		}
+	case []interface{}:
+		if o, ok := original.([]interface{}); ok && len(o) == len(d) {
+			var ops []jsonPatchOp
+			for i := range d {
+				ops = append(ops, defaultsPatch(fmt.Sprintf("%s/%d", path, i), o[i], d[i])...)
+			}
+			return ops
+		}
+	}
+
+	if original == nil {
+		return []jsonPatchOp{{Op: "add", Path: path, Value: defaulted}}
+	}
+	return []jsonPatchOp{{Op: "replace", Path: path, Value: defaulted}}
+}
+
+// writeAdmissionReview replies to the API server with the response of the review
+func writeAdmissionReview(w http.ResponseWriter, review admissionv1.AdmissionReview) {
+	review.Request = nil
+	rawResp, err := json.Marshal(review)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err = w.Write(rawResp); err != nil {
+		log.Println(err)
+	}
+}
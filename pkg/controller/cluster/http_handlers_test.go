@@ -0,0 +1,153 @@
+// This file is part of MinIO Operator
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cluster
+
+import (
+	"encoding/json"
+	"testing"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const admissionTenant = `{
+	"apiVersion": "minio.min.io/v2",
+	"kind": "Tenant",
+	"metadata": {"name": "tenant-a", "namespace": "ns-a"},
+	"spec": {
+		"credsSecret": {"name": "creds"},
+		"console": {"consoleSecret": {"name": "console"}},
+		"pools": [{
+			"servers": 4,
+			"volumesPerServer": 4,
+			"volumeClaimTemplate": {
+				"spec": {
+					"accessModes": ["ReadWriteOnce"],
+					"resources": {"requests": {"storage": "1Gi"}}
+				}
+			}
+		}]
+	}
+}`
+
+func Test_defaultTenantAdmission(t *testing.T) {
+	tests := []struct {
+		name      string
+		pinImages bool
+		wantPaths map[string]interface{}
+		skipPaths []string
+	}{
+		{
+			name: "Defaults without images",
+			wantPaths: map[string]interface{}{
+				"/spec/pools/0/name": "ss-0",
+				"/spec/mountPath":    miniov2.MinIOVolumeMountPath,
+			},
+			skipPaths: []string{"/spec/image", "/spec/console/image"},
+		},
+		{
+			name:      "Defaults with pinned images",
+			pinImages: true,
+			wantPaths: map[string]interface{}{
+				"/spec/pools/0/name":  "ss-0",
+				"/spec/image":         miniov2.GetTenantMinIOImage(),
+				"/spec/console/image": miniov2.GetTenantConsoleImage(),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				Namespace: "ns-a",
+				Object:    runtime.RawExtension{Raw: []byte(admissionTenant)},
+			}
+			patch, err := defaultTenantAdmission(req, tt.pinImages)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ops []jsonPatchOp
+			if err = json.Unmarshal(patch, &ops); err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[string]interface{})
+			for _, op := range ops {
+				if op.Value == nil {
+					t.Errorf("operation on %s sets a null value", op.Path)
+				}
+				got[op.Path] = op.Value
+			}
+			for path, value := range tt.wantPaths {
+				if got[path] != value {
+					t.Errorf("patch %s = %v, want %v", path, got[path], value)
+				}
+			}
+			for _, path := range tt.skipPaths {
+				if _, ok := got[path]; ok {
+					t.Errorf("patch should not set %s", path)
+				}
+			}
+		})
+	}
+}
+
+func Test_validateTenantAdmission(t *testing.T) {
+	var updated map[string]interface{}
+	if err := json.Unmarshal([]byte(admissionTenant), &updated); err != nil {
+		t.Fatal(err)
+	}
+	updated["spec"].(map[string]interface{})["pools"].([]interface{})[0].(map[string]interface{})["servers"] = 8
+	rawUpdated, _ := json.Marshal(updated)
+
+	tests := []struct {
+		name    string
+		req     *admissionv1.AdmissionRequest
+		wantErr bool
+	}{
+		{
+			name: "Create valid tenant",
+			req: &admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				Object:    runtime.RawExtension{Raw: []byte(admissionTenant)},
+			},
+		},
+		{
+			name: "Update pool servers",
+			req: &admissionv1.AdmissionRequest{
+				Operation: admissionv1.Update,
+				Object:    runtime.RawExtension{Raw: rawUpdated},
+				OldObject: runtime.RawExtension{Raw: []byte(admissionTenant)},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Create pool servers",
+			req: &admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				Object:    runtime.RawExtension{Raw: rawUpdated},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateTenantAdmission(tt.req); (err != nil) != tt.wantErr {
+				t.Errorf("validateTenantAdmission() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -20,14 +20,9 @@ package cluster
 import (
 	"bytes"
 	"context"
-	"crypto/rand"
 	"crypto/sha256"
-	"crypto/x509"
-	"crypto/x509/pkix"
 	"encoding/hex"
-	"encoding/pem"
 	"errors"
-	"fmt"
 
 	"github.com/minio/operator/pkg/resources/jobs"
 	"github.com/minio/operator/pkg/resources/services"
@@ -44,83 +39,6 @@ import (
 	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
 )
 
-func generateKESCryptoData(tenant *miniov2.Tenant) ([]byte, []byte, error) {
-	privateKey, err := newPrivateKey(miniov2.DefaultEllipticCurve)
-	if err != nil {
-		klog.Errorf("Unexpected error during the ECDSA Key generation: %v", err)
-		return nil, nil, err
-	}
-
-	privKeyBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
-	if err != nil {
-		klog.Errorf("Unexpected error during encoding the ECDSA Private Key: %v", err)
-		return nil, nil, err
-	}
-
-	var csrExtensions []pkix.Extension
-	kesHosts := tenant.KESHosts()
-	for _, host := range kesHosts {
-		csrExtensions = append(csrExtensions, pkix.Extension{
-			Id:       nil,
-			Critical: false,
-			Value:    []byte(host),
-		})
-	}
-
-	csrTemplate := x509.CertificateRequest{
-		Subject: pkix.Name{
-			CommonName:   fmt.Sprintf("system:node:%s", tenant.KESWildCardName()),
-			Organization: tenant.Spec.CertConfig.OrganizationName,
-		},
-		SignatureAlgorithm: x509.ECDSAWithSHA512,
-		DNSNames:           tenant.KESHosts(),
-		Extensions:         csrExtensions,
-	}
-
-	csrBytes, err := x509.CreateCertificateRequest(rand.Reader, &csrTemplate, privateKey)
-	if err != nil {
-		klog.Errorf("Unexpected error during creating the CSR: %v", err)
-		return nil, nil, err
-	}
-	return privKeyBytes, csrBytes, nil
-}
-
-// createKESCSR handles all the steps required to create the CSR: from creation of keys, submitting CSR and
-// finally creating a secret that KES Statefulset will use to mount private key and certificate for TLS
-// This Method Blocks till the CSR Request is approved via kubectl approve
-func (c *Controller) createKESCSR(ctx context.Context, tenant *miniov2.Tenant) error {
-	privKeysBytes, csrBytes, err := generateKESCryptoData(tenant)
-	if err != nil {
-		klog.Errorf("Private Key and CSR generation failed with error: %v", err)
-		return err
-	}
-
-	err = c.createCertificateSigningRequest(ctx, tenant.KESPodLabels(), tenant.KESCSRName(), tenant.Namespace, csrBytes, tenant, "server")
-	if err != nil {
-		klog.Errorf("Unexpected error during the creation of the csr/%s: %v", tenant.KESCSRName(), err)
-		return err
-	}
-
-	// fetch certificate from CSR
-	certbytes, err := c.fetchCertificate(ctx, tenant.KESCSRName())
-	if err != nil {
-		klog.Errorf("Unexpected error during the creation of the csr/%s: %v", tenant.KESCSRName(), err)
-		return err
-	}
-
-	// PEM encode private ECDSA key
-	encodedPrivKey := pem.EncodeToMemory(&pem.Block{Type: privateKeyType, Bytes: privKeysBytes})
-
-	// Create secret for KES Statefulset to use
-	err = c.createSecret(ctx, tenant, tenant.KESPodLabels(), tenant.KESTLSSecretName(), encodedPrivKey, certbytes)
-	if err != nil {
-		klog.Errorf("Unexpected error during the creation of the secret/%s: %v", tenant.KESTLSSecretName(), err)
-		return err
-	}
-
-	return nil
-}
-
 // kesStatefulSetMatchesSpec checks if the StatefulSet for KES matches what is expected and described from the Tenant
 func kesStatefulSetMatchesSpec(tenant *miniov2.Tenant, kesStatefulSet *appsv1.StatefulSet) (bool, error) {
 	if kesStatefulSet == nil {
@@ -144,35 +62,17 @@ func kesStatefulSetMatchesSpec(tenant *miniov2.Tenant, kesStatefulSet *appsv1.St
 	return true, nil
 }
 
-func (c *Controller) checkKESCertificatesStatus(ctx context.Context, tenant *miniov2.Tenant, nsName types.NamespacedName) (err error) {
+func (c *Controller) checkKESCertificatesStatus(ctx context.Context, tenant *miniov2.Tenant) error {
 	if !tenant.ExternalClientCert() {
 		// check if there's already a TLS secret for MinIO client to authenticate against KES
-		_, err := c.kubeClientSet.CoreV1().Secrets(tenant.Namespace).Get(ctx, tenant.MinIOClientTLSSecretName(), metav1.GetOptions{})
-		if err != nil {
-			if k8serrors.IsNotFound(err) {
-				if err = c.checkAndCreateMinIOClientCSR(ctx, nsName, tenant); err != nil {
-					return err
-				}
-				// TLS secret not found, delete CSR if exists and start certificate generation process again
-				if err = c.certClient.CertificateSigningRequests().Delete(ctx, tenant.MinIOClientCSRName(), metav1.DeleteOptions{}); err != nil {
-					return err
-				}
-			}
+		if err := c.checkTenantCertificate(ctx, tenant, minioClientCertificateRequest(tenant, c.hostsTemplate), StatusWaitingMinIOClientCert); err != nil {
+			return err
 		}
 	}
 	// if KES is enabled and user didn't provide KES server certificates generate them
 	if !tenant.KESExternalCert() {
-		_, err := c.kubeClientSet.CoreV1().Secrets(tenant.Namespace).Get(ctx, tenant.KESTLSSecretName(), metav1.GetOptions{})
-		if err != nil {
-			if k8serrors.IsNotFound(err) {
-				if err = c.checkAndCreateKESCSR(ctx, nsName, tenant); err != nil {
-					return err
-				}
-				// TLS secret not found, delete CSR if exists and start certificate generation process again
-				if err = c.certClient.CertificateSigningRequests().Delete(ctx, tenant.KESCSRName(), metav1.DeleteOptions{}); err != nil {
-					return err
-				}
-			}
+		if err := c.checkTenantCertificate(ctx, tenant, kesCertificateRequest(tenant), StatusWaitingKESCert); err != nil {
+			return err
 		}
 	}
 	return nil
@@ -180,7 +80,7 @@ func (c *Controller) checkKESCertificatesStatus(ctx context.Context, tenant *min
 
 func (c *Controller) checkKESStatus(ctx context.Context, tenant *miniov2.Tenant, totalReplicas int32, cOpts metav1.CreateOptions, uOpts metav1.UpdateOptions, nsName types.NamespacedName) error {
 	if tenant.HasKESEnabled() {
-		if err := c.checkKESCertificatesStatus(ctx, tenant, nsName); err != nil {
+		if err := c.checkKESCertificatesStatus(ctx, tenant); err != nil {
 			return err
 		}
 		var err error
@@ -264,42 +164,6 @@ func (c *Controller) checkKESStatus(ctx context.Context, tenant *miniov2.Tenant,
 	return nil
 }
 
-func (c *Controller) checkAndCreateMinIOClientCSR(ctx context.Context, nsName types.NamespacedName, tenant *miniov2.Tenant) error {
-	if _, err := c.certClient.CertificateSigningRequests().Get(ctx, tenant.MinIOClientCSRName(), metav1.GetOptions{}); err != nil {
-		if k8serrors.IsNotFound(err) {
-			if tenant, err = c.updateTenantStatus(ctx, tenant, StatusWaitingMinIOClientCert, 0); err != nil {
-				return err
-			}
-			klog.V(2).Infof("Creating a new Certificate Signing Request for MinIO Client Certs, cluster %q", nsName)
-			if err = c.createMinIOClientCSR(ctx, tenant); err != nil {
-				return err
-			}
-			// we want to re-queue this tenant so we can re-check for the health at a later stage
-			return errors.New("waiting for minio client cert")
-		}
-		return err
-	}
-
-	return nil
-}
-
-func (c *Controller) checkAndCreateKESCSR(ctx context.Context, nsName types.NamespacedName, tenant *miniov2.Tenant) error {
-	if _, err := c.certClient.CertificateSigningRequests().Get(ctx, tenant.KESCSRName(), metav1.GetOptions{}); err != nil {
-		if k8serrors.IsNotFound(err) {
-			if tenant, err = c.updateTenantStatus(ctx, tenant, StatusWaitingKESCert, 0); err != nil {
-				return err
-			}
-			klog.V(2).Infof("Creating a new Certificate Signing Request for KES Server Certs, cluster %q", nsName)
-			if err = c.createKESCSR(ctx, tenant); err != nil {
-				return err
-			}
-			return errors.New("waiting for kes cert")
-		}
-		return err
-	}
-	return nil
-}
-
 func (c *Controller) getCertIdentity(ns string, cert *miniov2.LocalCertificateReference) (string, error) {
 	var certbytes []byte
 	secret, err := c.kubeClientSet.CoreV1().Secrets(ns).Get(context.Background(), cert.Name, metav1.GetOptions{})
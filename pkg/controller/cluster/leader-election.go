@@ -0,0 +1,171 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package cluster
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"time"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// leaseName is the name of the Lease the replicas of the Operator campaign for
+	leaseName = "minio-operator-lock"
+	// leaseDuration is how long the replicas wait before taking over a Lease that wasn't renewed
+	leaseDuration = 15 * time.Second
+	// leaseRenewDeadline is how long the leader retries renewing the Lease before giving it up
+	leaseRenewDeadline = 10 * time.Second
+	// leaseRetryPeriod is how often the replicas try to acquire or renew the Lease
+	leaseRetryPeriod = 2 * time.Second
+)
+
+// RunLeaderElection campaigns for the Lease of the Operator until the context is canceled or the leadership is
+// lost, lead is called once this replica is elected with a context canceled when the leadership is lost
+func (c *Controller) RunLeaderElection(ctx context.Context, lead func(ctx context.Context)) error {
+	// the name of the pod identifies the replica
+	identity, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      leaseName,
+			Namespace: miniov2.GetNSFromFile(),
+		},
+		Client: c.kubeClientSet.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: identity,
+		},
+	}
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		ReleaseOnCancel: true,
+		LeaseDuration:   leaseDuration,
+		RenewDeadline:   leaseRenewDeadline,
+		RetryPeriod:     leaseRetryPeriod,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				klog.Infof("%s is the leader of the MinIO Operator", identity)
+				lead(ctx)
+			},
+			OnStoppedLeading: func() {
+				klog.Infof("%s stopped leading the MinIO Operator", identity)
+			},
+			OnNewLeader: func(leader string) {
+				if leader != identity {
+					klog.Infof("%s is the leader of the MinIO Operator", leader)
+				}
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	c.leaderElector.Store(elector)
+	elector.Run(ctx)
+	return nil
+}
+
+// isLeader returns whether this replica leads the Operator
+func (c *Controller) isLeader() bool {
+	elector, ok := c.leaderElector.Load().(*leaderelection.LeaderElector)
+	return ok && elector.IsLeader()
+}
+
+// leaderURL returns the URL of the webhook server of the leader of the Operator
+func (c *Controller) leaderURL(ctx context.Context) (*url.URL, error) {
+	elector, ok := c.leaderElector.Load().(*leaderelection.LeaderElector)
+	if !ok || elector.GetLeader() == "" {
+		return nil, errors.New("no leader of the operator is elected")
+	}
+	pod, err := c.kubeClientSet.CoreV1().Pods(miniov2.GetNSFromFile()).Get(ctx, elector.GetLeader(), metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if pod.Status.PodIP == "" {
+		return nil, fmt.Errorf("the leader of the operator %s has no IP", pod.Name)
+	}
+	return &url.URL{
+		Scheme: "https",
+		Host:   net.JoinHostPort(pod.Status.PodIP, miniov2.WebhookDefaultPort),
+	}, nil
+}
+
+// leaderTransport returns a transport verifying that the leader presents the certificate of the Operator
+func (c *Controller) leaderTransport() (*http.Transport, error) {
+	keyPair, err := c.getOperatorCertificate(nil)
+	if err != nil {
+		return nil, err
+	}
+	rootCAs := x509.NewCertPool()
+	rootCAs.AppendCertsFromPEM(miniov2.GetPodCAFromFile())
+	for _, der := range keyPair.Certificate {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, err
+		}
+		rootCAs.AddCert(cert)
+	}
+	return &http.Transport{
+		TLSClientConfig: &tls.Config{
+			RootCAs:    rootCAs,
+			ServerName: fmt.Sprintf("operator.%s.svc", miniov2.GetNSFromFile()),
+		},
+		DisableKeepAlives: true,
+	}, nil
+}
+
+// UpdateArtifactsHandler - GET /webhook/v1/update/{file}
+// Serves the MinIO binaries the leader fetched to update a Tenant, the other replicas forward the requests to the
+// leader
+func (c *Controller) UpdateArtifactsHandler() http.Handler {
+	artifacts := http.StripPrefix(miniov2.WebhookAPIUpdate, http.FileServer(http.Dir(updatePath)))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c.isLeader() {
+			artifacts.ServeHTTP(w, r)
+			return
+		}
+		leader, err := c.leaderURL(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		transport, err := c.leaderTransport()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		proxy := httputil.NewSingleHostReverseProxy(leader)
+		proxy.Transport = transport
+		proxy.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,38 @@
+// This file is part of MinIO Operator
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cluster
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+)
+
+func TestController_UpdateArtifactsHandler(t *testing.T) {
+	c := &Controller{}
+	if c.isLeader() {
+		t.Fatal("isLeader() = true before the election")
+	}
+	// without a leader the artifacts can't be forwarded
+	w := httptest.NewRecorder()
+	c.UpdateArtifactsHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, miniov2.WebhookAPIUpdate+"/minio.RELEASE.2021-06-17T00-10-46Z", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("UpdateArtifactsHandler() status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
@@ -0,0 +1,201 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+// legacyServiceNameSyncTimeout is how long the Operator waits for the tenant informer to observe the legacy service
+// name recorded in the status of a tenant
+const legacyServiceNameSyncTimeout = 30 * time.Second
+
+// legacySecretNames are the names of the secrets once shared by the tenants of a namespace, replaced by a secret
+// per tenant
+var legacySecretNames = []string{miniov2.WebhookSecret, miniov2.OperatorTLSSecretName}
+
+// checkLegacyServiceName keeps the MinIO service of the tenants created before several tenants could share a
+// namespace, the clients of these tenants resolve it with the name of the namespace
+func (c *Controller) checkLegacyServiceName(ctx context.Context, tenant *miniov2.Tenant) (*miniov2.Tenant, error) {
+	if tenant.Status.LegacyServiceName {
+		return tenant, nil
+	}
+	svc, err := c.serviceLister.Services(tenant.Namespace).Get(miniov2.MinIOLegacyServiceName)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return tenant, nil
+		}
+		return tenant, err
+	}
+	if !metav1.IsControlledBy(svc, tenant) {
+		return tenant, nil
+	}
+	klog.V(2).Infof("Keeping the %s service of tenant %s/%s", svc.Name, tenant.Namespace, tenant.Name)
+	return c.updateLegacyServiceNameStatus(ctx, tenant)
+}
+
+// resolveLegacyServiceNames records the legacy service name of the tenants before the workers, the health monitor
+// and the Bucket and IAM controllers build a client for them, and waits for the tenant informer to observe it so
+// they don't dial a service that doesn't exist after an operator upgrade
+func (c *Controller) resolveLegacyServiceNames(ctx context.Context) error {
+	tenants, err := c.tenantsLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	for _, tenant := range tenants {
+		if tenant.Status.LegacyServiceName {
+			continue
+		}
+		updated, err := c.checkLegacyServiceName(ctx, tenant)
+		if err != nil {
+			return fmt.Errorf("failed to resolve the service name of tenant %s/%s: %w", tenant.Namespace, tenant.Name, err)
+		}
+		if !updated.Status.LegacyServiceName {
+			continue
+		}
+		if err = wait.PollImmediate(100*time.Millisecond, legacyServiceNameSyncTimeout, func() (bool, error) {
+			cached, err := c.tenantsLister.Tenants(tenant.Namespace).Get(tenant.Name)
+			if k8serrors.IsNotFound(err) {
+				return true, nil
+			}
+			if err != nil {
+				return false, err
+			}
+			return cached.Status.LegacyServiceName, nil
+		}); err != nil {
+			return fmt.Errorf("failed to wait for the service name of tenant %s/%s to sync: %w", tenant.Namespace, tenant.Name, err)
+		}
+	}
+	return nil
+}
+
+// tenantCreatedBefore returns whether tenant a was created before tenant b, the names of the tenants break the ties
+func tenantCreatedBefore(a, b *miniov2.Tenant) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return a.Name < b.Name
+}
+
+// legacyWebhookSecret returns the webhook secret once shared by the tenants of the namespace if the tenant owns it,
+// nil otherwise
+func (c *Controller) legacyWebhookSecret(tenant *miniov2.Tenant) (*corev1.Secret, error) {
+	secret, err := c.secretLister.Secrets(tenant.Namespace).Get(miniov2.WebhookSecret)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !metav1.IsControlledBy(secret, tenant) {
+		return nil, nil
+	}
+	return secret, nil
+}
+
+// podSpecUsesSecret returns whether a pod references the secret in its volumes or in the environment of its
+// containers
+func podSpecUsesSecret(spec *corev1.PodSpec, name string) bool {
+	for _, volume := range spec.Volumes {
+		if volume.Secret != nil && volume.Secret.SecretName == name {
+			return true
+		}
+		if volume.Projected == nil {
+			continue
+		}
+		for _, source := range volume.Projected.Sources {
+			if source.Secret != nil && source.Secret.Name == name {
+				return true
+			}
+		}
+	}
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, container := range containers {
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil && env.ValueFrom.SecretKeyRef.Name == name {
+				return true
+			}
+		}
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.SecretRef != nil && envFrom.SecretRef.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// deleteLegacySecrets deletes the secrets once shared by the tenants of the namespace owned by the tenant, once
+// neither its statefulsets nor its pods use them anymore
+func (c *Controller) deleteLegacySecrets(ctx context.Context, tenant *miniov2.Tenant) error {
+	var specs []*corev1.PodSpec
+	for _, name := range legacySecretNames {
+		secret, err := c.secretLister.Secrets(tenant.Namespace).Get(name)
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if !metav1.IsControlledBy(secret, tenant) {
+			continue
+		}
+		if specs == nil {
+			poolDir, err := c.getAllSSForTenant(tenant)
+			if err != nil {
+				return err
+			}
+			selector := labels.SelectorFromSet(tenant.MinIOPodLabels()).String()
+			pods, err := c.kubeClientSet.CoreV1().Pods(tenant.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+			if err != nil {
+				return err
+			}
+			for _, ss := range poolDir {
+				specs = append(specs, &ss.Spec.Template.Spec)
+			}
+			for i := range pods.Items {
+				specs = append(specs, &pods.Items[i].Spec)
+			}
+		}
+		inUse := false
+		for _, spec := range specs {
+			if podSpecUsesSecret(spec, name) {
+				inUse = true
+				break
+			}
+		}
+		if inUse {
+			continue
+		}
+		klog.V(2).Infof("Deleting the %s secret of tenant %s/%s, replaced by a secret of the tenant", name, tenant.Namespace, tenant.Name)
+		if err = c.kubeClientSet.CoreV1().Secrets(tenant.Namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+// This file is part of MinIO Operator
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	fakeminio "github.com/minio/operator/pkg/client/clientset/versioned/fake"
+	listers "github.com/minio/operator/pkg/client/listers/minio.min.io/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+func Test_podSpecUsesSecret(t *testing.T) {
+	tenant := &miniov2.Tenant{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a", Namespace: "ns"}}
+	spec := &corev1.PodSpec{
+		Containers: []corev1.Container{{
+			Env: []corev1.EnvVar{{
+				Name: miniov2.WebhookMinIOArgs,
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: miniov2.WebhookSecret},
+						Key:                  miniov2.WebhookMinIOArgs,
+					},
+				},
+			}},
+		}},
+		Volumes: []corev1.Volume{{
+			VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{
+					Sources: []corev1.VolumeProjection{{
+						Secret: &corev1.SecretProjection{
+							LocalObjectReference: corev1.LocalObjectReference{Name: tenant.OperatorTLSSecretCopyName()},
+						},
+					}},
+				},
+			},
+		}},
+	}
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: miniov2.WebhookSecret, want: true},
+		{name: tenant.OperatorTLSSecretCopyName(), want: true},
+		{name: miniov2.OperatorTLSSecretName, want: false},
+		{name: tenant.WebhookSecretName(), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := podSpecUsesSecret(spec, tt.name); got != tt.want {
+				t.Errorf("podSpecUsesSecret() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_tenantCreatedBefore(t *testing.T) {
+	now := time.Now()
+	tenant := func(name string, created time.Time) *miniov2.Tenant {
+		return &miniov2.Tenant{ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(created)}}
+	}
+	if !tenantCreatedBefore(tenant("b", now.Add(-time.Hour)), tenant("a", now)) {
+		t.Error("tenantCreatedBefore() = false for an older tenant")
+	}
+	if tenantCreatedBefore(tenant("a", now), tenant("b", now.Add(-time.Hour))) {
+		t.Error("tenantCreatedBefore() = true for a newer tenant")
+	}
+	if !tenantCreatedBefore(tenant("a", now), tenant("b", now)) || tenantCreatedBefore(tenant("b", now), tenant("a", now)) {
+		t.Error("tenantCreatedBefore() doesn't break ties with the names of the tenants")
+	}
+}
+
+func Test_resolveLegacyServiceNames(t *testing.T) {
+	legacy := &miniov2.Tenant{ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "ns-a", UID: "legacy"}}
+	current := &miniov2.Tenant{ObjectMeta: metav1.ObjectMeta{Name: "current", Namespace: "ns-b", UID: "current"}}
+	legacySvc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{
+		Name:            miniov2.MinIOLegacyServiceName,
+		Namespace:       legacy.Namespace,
+		OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(legacy, miniov2.SchemeGroupVersion.WithKind(miniov2.MinIOCRDResourceKind))},
+	}}
+	// a service named after the namespace the tenant doesn't control is not the service of the tenant
+	otherSvc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: miniov2.MinIOLegacyServiceName, Namespace: current.Namespace}}
+
+	tenantIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	serviceIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, obj := range []interface{}{legacy, current} {
+		if err := tenantIndexer.Add(obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, obj := range []interface{}{legacySvc, otherSvc} {
+		if err := serviceIndexer.Add(obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+	minioClient := fakeminio.NewSimpleClientset(legacy, current)
+	// the tenant informer observes the updated status
+	minioClient.PrependReactor("update", "tenants", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if err := tenantIndexer.Update(action.(k8stesting.UpdateAction).GetObject()); err != nil {
+			return true, nil, err
+		}
+		return false, nil, nil
+	})
+	c := &Controller{
+		minioClientSet: minioClient,
+		tenantsLister:  listers.NewTenantLister(tenantIndexer),
+		serviceLister:  corelisters.NewServiceLister(serviceIndexer),
+	}
+	if err := c.resolveLegacyServiceNames(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, tenant := range []*miniov2.Tenant{legacy, current} {
+		got, err := minioClient.MinioV2().Tenants(tenant.Namespace).Get(context.Background(), tenant.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := tenant == legacy; got.Status.LegacyServiceName != want {
+			t.Errorf("%s: Status.LegacyServiceName = %v, want %v", tenant.Name, got.Status.LegacyServiceName, want)
+		}
+	}
+}
@@ -20,6 +20,7 @@ package cluster
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -28,7 +29,10 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	miniov1 "github.com/minio/operator/pkg/apis/minio.min.io/v1"
@@ -49,6 +53,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -56,6 +61,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	appsinformers "k8s.io/client-go/informers/apps/v1"
 	batchinformers "k8s.io/client-go/informers/batch/v1"
 	coreinformers "k8s.io/client-go/informers/core/v1"
@@ -77,8 +83,6 @@ import (
 	jwtreq "github.com/dgrijalva/jwt-go/request"
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
-	"github.com/google/go-containerregistry/pkg/v1/remote"
-	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
 	clientset "github.com/minio/operator/pkg/client/clientset/versioned"
 	minioscheme "github.com/minio/operator/pkg/client/clientset/versioned/scheme"
@@ -133,8 +137,12 @@ const (
 	StatusUpdatingResourceRequirements         = "Updating Resource Requirements"
 	StatusUpdatingAffinity                     = "Updating Pod Affinity"
 	StatusNotOwned                             = "Statefulset not controlled by operator"
-	StatusFailedAlreadyExists                  = "Another MinIO Tenant already exists in the namespace"
+	StatusFailedBucketDNSConflict              = "Another MinIO Tenant of the namespace has Bucket DNS enabled"
 	StatusInconsistentMinIOVersions            = "Different versions across MinIO Pools"
+	StatusRotatingCredentials                  = "Rotating root credentials"
+	StatusRollingMinIOUpgrade                  = "Upgrading MinIO pods one at a time"
+	StatusBakingMinIOUpgrade                   = "Watching MinIO health after the upgrade"
+	StatusRollingBackMinIOUpgrade              = "Rolling back the MinIO upgrade"
 )
 
 // ErrMinIONotReady is the error returned when MinIO is not Ready
@@ -153,6 +161,8 @@ type Controller struct {
 	certClient certapi.CertificatesV1Client
 	// promClient is a clientset for Prometheus service monitor
 	promClient promclientset.Interface
+	// dynamicClient is a client for the Gateway API routes
+	dynamicClient dynamic.Interface
 	// statefulSetLister is able to list/get StatefulSets from a shared
 	// informer's store.
 	statefulSetLister appslisters.StatefulSetLister
@@ -188,6 +198,13 @@ type Controller struct {
 	// has synced at least once.
 	serviceListerSynced cache.InformerSynced
 
+	// secretLister is able to list/get Secrets from a shared informer's
+	// store.
+	secretLister corelisters.SecretLister
+	// secretListerSynced returns true if the Secret shared informer
+	// has synced at least once.
+	secretListerSynced cache.InformerSynced
+
 	// serviceMonitorLister is able to list/get Services from a shared informer's
 	// store.
 	serviceMonitorLister promlisters.ServiceMonitorLister
@@ -211,8 +228,36 @@ type Controller struct {
 	// currently running operator version
 	operatorVersion string
 
+	// Store the default images in the Tenant at admission instead of
+	// following the operator defaults on every sync
+	pinImageDefaults bool
+
 	// Webhook server instance
 	ws *http.Server
+
+	// Metrics server instance
+	ms *http.Server
+
+	// transports the Operator connects to the tenants with, trusting their certificates
+	transports *TenantTransports
+
+	// metrics exported by the metrics server
+	metrics *operatorMetrics
+
+	// TLS certificate of the webhook server, replaced when renewed
+	operatorCert atomic.Value
+
+	// elector of the leader of the replicas of the Operator, set once the election starts
+	leaderElector atomic.Value
+
+	// key of the HMACs of the credentials recorded in the status of the tenants, loaded once
+	hashKey atomic.Value
+
+	// MinIO release times of the images referenced by digest, read from their binaries
+	digestReleases sync.Map
+
+	// statefulsets of the pools deleted to resize their volumes, until they are created again
+	resizedPools sync.Map
 }
 
 // NewController returns a new sample controller
@@ -221,13 +266,16 @@ func NewController(
 	minioClientSet clientset.Interface,
 	certClient certapi.CertificatesV1Client,
 	promClient promclientset.Interface,
+	dynamicClient dynamic.Interface,
 	statefulSetInformer appsinformers.StatefulSetInformer,
 	deploymentInformer appsinformers.DeploymentInformer,
 	jobInformer batchinformers.JobInformer,
 	tenantInformer informers.TenantInformer,
 	serviceInformer coreinformers.ServiceInformer,
+	secretInformer coreinformers.SecretInformer,
 	serviceMonitorInformer prominformers.ServiceMonitorInformer,
-	hostsTemplate, operatorVersion string) *Controller {
+	hostsTemplate, operatorVersion string,
+	pinImageDefaults bool) *Controller {
 
 	// Create event broadcaster
 	// Add minio-controller types to the default Kubernetes Scheme so Events can be
@@ -244,6 +292,7 @@ func NewController(
 		minioClientSet:             minioClientSet,
 		certClient:                 certClient,
 		promClient:                 promClient,
+		dynamicClient:              dynamicClient,
 		statefulSetLister:          statefulSetInformer.Lister(),
 		statefulSetListerSynced:    statefulSetInformer.Informer().HasSynced,
 		deploymentLister:           deploymentInformer.Lister(),
@@ -254,16 +303,22 @@ func NewController(
 		tenantsSynced:              tenantInformer.Informer().HasSynced,
 		serviceLister:              serviceInformer.Lister(),
 		serviceListerSynced:        serviceInformer.Informer().HasSynced,
+		secretLister:               secretInformer.Lister(),
+		secretListerSynced:         secretInformer.Informer().HasSynced,
 		serviceMonitorLister:       serviceMonitorInformer.Lister(),
 		serviceMonitorListerSynced: serviceMonitorInformer.Informer().HasSynced,
 		workqueue:                  queue.NewNamedRateLimitingQueue(MinIOControllerRateLimiter(), "Tenants"),
 		recorder:                   recorder,
 		hostsTemplate:              hostsTemplate,
 		operatorVersion:            operatorVersion,
+		pinImageDefaults:           pinImageDefaults,
+		transports:                 NewTenantTransports(secretInformer),
 	}
+	controller.metrics = newOperatorMetrics(controller.workqueue.Len)
 
-	// Initialize operator webhook handlers
+	// Initialize operator webhook and metrics handlers
 	controller.ws = configureWebhookServer(controller)
+	controller.ms = configureMetricsServer(controller)
 
 	klog.Info("Setting up event handlers")
 	// Set up an event handler for when Tenant resources change
@@ -315,6 +370,19 @@ func NewController(
 		},
 		DeleteFunc: controller.handleObject,
 	})
+
+	// Rotate the root credentials of the tenants when their credentials secret changes
+	secretInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(old, new interface{}) {
+			newSecret := new.(*corev1.Secret)
+			oldSecret := old.(*corev1.Secret)
+			if newSecret.ResourceVersion == oldSecret.ResourceVersion {
+				// Periodic resync will send update events for all known Secrets.
+				return
+			}
+			controller.handleCredsSecret(new)
+		},
+	})
 	return controller
 }
 
@@ -356,11 +424,31 @@ func generateRandomKey(length int) string {
 
 func (c *Controller) applyOperatorWebhookSecret(ctx context.Context, tenant *miniov2.Tenant) (*v1.Secret, error) {
 	secret, err := c.kubeClientSet.CoreV1().Secrets(tenant.Namespace).Get(ctx,
-		miniov2.WebhookSecret, metav1.GetOptions{})
+		tenant.WebhookSecretName(), metav1.GetOptions{})
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
-			secret = getSecretForTenant(tenant, generateRandomKey(20), generateRandomKey(40))
-			return c.kubeClientSet.CoreV1().Secrets(tenant.Namespace).Create(ctx, secret, metav1.CreateOptions{})
+			// keep the credentials of the webhook secret once shared by the namespace, the MinIO pods
+			// use them until they restart
+			legacySecret, err := c.legacyWebhookSecret(tenant)
+			if err != nil {
+				return nil, err
+			}
+			if legacySecret == nil {
+				secret = getSecretForTenant(tenant, generateRandomKey(20), generateRandomKey(40))
+				return c.kubeClientSet.CoreV1().Secrets(tenant.Namespace).Create(ctx, secret, metav1.CreateOptions{})
+			}
+			secret = getSecretForTenant(tenant, string(legacySecret.Data[miniov2.WebhookOperatorUsername]),
+				string(legacySecret.Data[miniov2.WebhookOperatorPassword]))
+			if secret, err = c.kubeClientSet.CoreV1().Secrets(tenant.Namespace).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+				return nil, err
+			}
+			// update the revision of the tenant to restart the pools with the secrets of the tenant
+			t2, err := c.increaseTenantRevision(ctx, tenant)
+			if err != nil {
+				return nil, err
+			}
+			*tenant = *t2
+			return secret, nil
 		}
 		return nil, err
 	}
@@ -389,7 +477,7 @@ func getSecretForTenant(tenant *miniov2.Tenant, accessKey, secretKey string) *v1
 	secret := &corev1.Secret{
 		Type: "Opaque",
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      miniov2.WebhookSecret,
+			Name:      tenant.WebhookSecretName(),
 			Namespace: tenant.Namespace,
 			OwnerReferences: []metav1.OwnerReference{
 				*metav1.NewControllerRef(tenant, schema.GroupVersionKind{
@@ -454,12 +542,39 @@ func (mk *minioKeychain) Resolve(_ authn.Resource) (authn.Authenticator, error)
 	}), nil
 }
 
+// imageKeychain returns the keychain pulling the image of the tenant. The registry secret of the Operator is used
+// first when set. If the tenant has imagePullSecret use that for pulling the image, but if we fail to extract the
+// secret or we can't find the expected registry in the secret we will continue with the default keychain. This is
+// because the needed pull secret could be attached to the service-account.
+func (c *Controller) imageKeychain(ctx context.Context, ref name.Reference, tenant *miniov2.Tenant) authn.Keychain {
+	if secret := miniov2.GetUpdateSources().RegistrySecret; secret != "" {
+		keychain, err := c.getKeychainFromSecret(ctx, ref, miniov2.GetNSFromFile(), secret)
+		if err == nil {
+			return keychain
+		}
+		klog.Info(err)
+	}
+	if tenant.Spec.ImagePullSecret.Name == "" {
+		return authn.DefaultKeychain
+	}
+	keychain, err := c.getKeychainForTenant(ctx, ref, tenant)
+	if err != nil {
+		klog.Info(err)
+	}
+	return keychain
+}
+
 // getKeychainForTenant attempts to build a new authn.Keychain from the image pull secret on the Tenant
 func (c *Controller) getKeychainForTenant(ctx context.Context, ref name.Reference, tenant *miniov2.Tenant) (authn.Keychain, error) {
+	return c.getKeychainFromSecret(ctx, ref, tenant.Namespace, tenant.Spec.ImagePullSecret.Name)
+}
+
+// getKeychainFromSecret attempts to build a new authn.Keychain from a docker config secret
+func (c *Controller) getKeychainFromSecret(ctx context.Context, ref name.Reference, namespace, secretName string) (authn.Keychain, error) {
 	// Get the secret
-	secret, err := c.kubeClientSet.CoreV1().Secrets(tenant.Namespace).Get(ctx, tenant.Spec.ImagePullSecret.Name, metav1.GetOptions{})
+	secret, err := c.kubeClientSet.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
 	if err != nil {
-		return authn.DefaultKeychain, errors.New("can't retrieve the tenant image pull secret")
+		return authn.DefaultKeychain, fmt.Errorf("can't retrieve the image pull secret %s/%s", namespace, secretName)
 	}
 	// if we can't find .dockerconfigjson, error out
 	dockerConfigJSON, ok := secret.Data[".dockerconfigjson"]
@@ -483,8 +598,10 @@ func (c *Controller) getKeychainForTenant(ctx context.Context, ref name.Referenc
 	}, nil
 }
 
-// Attempts to fetch given image and then extracts and keeps relevant files
-// (minio, minio.sha256sum & minio.minisig) at a pre-defined location (/tmp/webhook/v1/update)
+// Attempts to fetch the MinIO binary of the release of the given image and keeps the relevant files (minio,
+// minio.sha256sum & minio.minisig) at a pre-defined location (/tmp/webhook/v1/update). The binary is pre-staged in
+// the artifacts directory of the Operator, or extracted from an OCI image layout tarball or from the image pulled
+// from its registry or a mirror. The binary is only served to MinIO once its signature is verified.
 func (c *Controller) fetchArtifacts(tenant *miniov2.Tenant) (latest time.Time, err error) {
 	basePath := updatePath
 
@@ -492,105 +609,28 @@ func (c *Controller) fetchArtifacts(tenant *miniov2.Tenant) (latest time.Time, e
 		return latest, err
 	}
 
-	ref, err := name.ParseReference(tenant.Spec.Image)
-	if err != nil {
-		return latest, err
-	}
-
-	keychain := authn.DefaultKeychain
-
-	// if the tenant has imagePullSecret use that for pulling the image, but if we fail to extract the secret or we
-	// can't find the expected registry in the secret we will continue with the default keychain. This is because the
-	// needed pull secret could be attached to the service-account.
-	if tenant.Spec.ImagePullSecret.Name != "" {
-		// Get the secret
-		keychain, err = c.getKeychainForTenant(context.Background(), ref, tenant)
-		if err != nil {
-			klog.Info(err)
-		}
-	}
-
-	img, err := remote.Image(ref, remote.WithAuthFromKeychain(keychain))
+	ref, err := name.ParseReference(miniov2.RewriteImage(tenant.Spec.Image))
 	if err != nil {
 		return latest, err
 	}
 
-	ls, err := img.Layers()
+	// the artifacts are collected out of the update directory served to MinIO, on the same filesystem so they can be
+	// moved to it once verified
+	stagingDir, err := ioutil.TempDir(filepath.Dir(filepath.Clean(basePath)), "artifacts")
 	if err != nil {
 		return latest, err
 	}
+	defer os.RemoveAll(stagingDir)
+	stagingPath := stagingDir + slashSeparator
 
-	// Find the file with largest size among all layers.
-	// This is the tar file with all minio relevant files.
-	start := 0
-	if len(ls) >= 2 { // skip the base layer
-		start = 1
-	}
-	maxSizeHash, _ := ls[start].Digest()
-	maxSize, _ := ls[start].Size()
-	for i := range ls {
-		if i < start {
-			continue
-		}
-		s, _ := ls[i].Size()
-		if s > maxSize {
-			maxSize, _ = ls[i].Size()
-			maxSizeHash, _ = ls[i].Digest()
-		}
-	}
-
-	f, err := os.OpenFile(basePath+"image.tar", os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0777)
-	if err != nil {
-		return latest, err
-	}
-	defer func() {
-		_ = f.Close()
-	}()
-
-	// Tarball writes a file called image.tar
-	// This file in turn has each container layer present inside in the form `<layer-hash>.tar.gz`
-	if err = tarball.Write(ref, img, f); err != nil {
-		return latest, err
-	}
-
-	// Extract the <layer-hash>.tar.gz file that has minio contents from `image.tar`
-	fileNameToExtract := strings.Split(maxSizeHash.String(), ":")[1] + ".tar.gz"
-	if err = miniov2.ExtractTar([]string{fileNameToExtract}, basePath, "image.tar"); err != nil {
-		return latest, err
-	}
-
-	// Extract the minio update related files (minio, minio.sha256sum and minio.minisig) from `<layer-hash>.tar.gz`
-	if err = miniov2.ExtractTar([]string{"usr/bin/minio", "usr/bin/minio.sha256sum", "usr/bin/minio.minisig"}, basePath, fileNameToExtract); err != nil {
-		return latest, err
-	}
-
-	srcBinary := "minio"
-	srcShaSum := "minio.sha256sum"
-	srcSig := "minio.minisig"
-
-	tag, err := c.fetchTag(basePath + srcBinary)
+	tag, err := c.collectArtifacts(context.Background(), tenant, ref, stagingPath)
 	if err != nil {
 		return latest, err
 	}
-
-	latest, err = miniov2.ReleaseTagToReleaseTime(tag)
-	if err != nil {
+	if err = publishArtifacts(miniov2.GetUpdateSources().MinisignPubKey, stagingPath, basePath, tag); err != nil {
 		return latest, err
 	}
-
-	destBinary := "minio." + tag
-	destShaSum := "minio." + tag + ".sha256sum"
-	destSig := "minio." + tag + ".minisig"
-	filesToRename := map[string]string{srcBinary: destBinary, srcShaSum: destShaSum, srcSig: destSig}
-
-	// rename all files to add tag specific values in the name.
-	// this is because minio updater looks for files in this name format.
-	for s, d := range filesToRename {
-		if err = os.Rename(basePath+s, basePath+d); err != nil {
-			return latest, err
-		}
-	}
-	return latest, nil
+	return miniov2.ReleaseTagToReleaseTime(tag)
 }
 
 // Remove all the files created during upload process
@@ -598,78 +638,39 @@ func (c *Controller) removeArtifacts() error {
 	return os.RemoveAll(updatePath)
 }
 
-var operatorTLSSecretName = "operator-tls"
-
 // Start will set up the event handlers for types we are interested in, as well
 // as syncing informer caches and starting workers. It will block until stopCh
 // is closed, at which point it will shutdown the workqueue and wait for
-// workers to finish processing their current work items.
+// workers to finish processing their current work items. Only the leader of the
+// replicas of the Operator starts the controller.
 func (c *Controller) Start(threadiness int, stopCh <-chan struct{}) error {
+	ctx, cancel := context.WithCancel(context.Background())
 	go func() {
-		ctx := context.Background()
-		namespace := miniov2.GetNSFromFile()
-		// operator deployment for owner reference
-		operatorDeployment, err := c.kubeClientSet.AppsV1().Deployments(namespace).Get(ctx, "minio-operator", metav1.GetOptions{})
-		if err != nil {
-			panic(err)
-		}
-
-		publicCertPath := "/tmp/public.crt"
-		publicKeyPath := "/tmp/private.key"
-
-		for {
-			// operator TLS certificates
-			operatorTLSCert, err := c.kubeClientSet.CoreV1().Secrets(namespace).Get(ctx, operatorTLSSecretName, metav1.GetOptions{})
-			if err != nil {
-				if k8serrors.IsNotFound(err) {
-					klog.Infof("operator TLS secret not found", err.Error())
-					if err = c.checkAndCreateOperatorCSR(ctx, operatorDeployment); err != nil {
-						klog.Infof("Waiting for the operator certificates to be issued %v", err.Error())
-						time.Sleep(time.Second * 10)
-					} else {
-						if err = c.certClient.CertificateSigningRequests().Delete(ctx, "operator-auto-tls", metav1.DeleteOptions{}); err != nil {
-							klog.Infof(err.Error())
-						}
-					}
-				}
-			} else {
-				if val, ok := operatorTLSCert.Data["public.crt"]; ok {
-					err := ioutil.WriteFile(publicCertPath, val, 0644)
-					if err != nil {
-						panic(err)
-					}
-				} else {
-					panic(errors.New("operator TLS wrong format"))
-				}
-
-				if val, ok := operatorTLSCert.Data["private.key"]; ok {
-					err := ioutil.WriteFile(publicKeyPath, val, 0644)
-					if err != nil {
-						panic(err)
-					}
-				} else {
-					panic(errors.New("operator TLS wrong format"))
-				}
-				break
-			}
-		}
-		klog.Infof("Starting api server")
-		// use those certificates to configure the web server
-		if err := c.ws.ListenAndServeTLS(publicCertPath, publicKeyPath); err != http.ErrServerClosed {
-			klog.Infof("HTTPS server ListenAndServeTLS: %v", err)
-			return
-		}
+		<-stopCh
+		cancel()
 	}()
+	// only the leader exports the metrics of the controller
+	if err := c.metrics.register(); err != nil {
+		return err
+	}
+
+	// operator TLS certificates
+	go c.renewOperatorCertificate(ctx)
 
 	// Start the informer factories to begin populating the informer caches
 	klog.Info("Starting Tenant controller")
 
 	// Wait for the caches to be synced before starting workers
 	klog.Info("Waiting for informer caches to sync")
-	if ok := cache.WaitForCacheSync(stopCh, c.statefulSetListerSynced, c.deploymentListerSynced, c.tenantsSynced); !ok {
+	if ok := cache.WaitForCacheSync(stopCh, c.statefulSetListerSynced, c.deploymentListerSynced, c.tenantsSynced, c.secretListerSynced, c.serviceListerSynced); !ok {
 		return fmt.Errorf("failed to wait for caches to sync")
 	}
 
+	// the Bucket and IAM controllers start once this returns, resolve the service names before anything dials them
+	if err := c.resolveLegacyServiceNames(ctx); err != nil {
+		return err
+	}
+
 	klog.Info("Starting workers")
 	// Launch two workers to process Tenant resources
 	for i := 0; i < threadiness; i++ {
@@ -682,12 +683,56 @@ func (c *Controller) Start(threadiness int, stopCh <-chan struct{}) error {
 	return nil
 }
 
+// ServeWebhooks starts the webhook and metrics servers once the certificate of the Operator is issued by the
+// leader, every replica of the Operator serves the webhooks
+func (c *Controller) ServeWebhooks(stopCh <-chan struct{}) {
+	go func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			<-stopCh
+			cancel()
+		}()
+
+		for {
+			if err := c.loadOperatorCertificate(ctx); err != nil {
+				klog.Infof("Waiting for the operator certificates to be issued %v", err.Error())
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(operatorCertificateRetryInterval):
+				}
+				continue
+			}
+			break
+		}
+		go c.reloadOperatorCertificate(ctx)
+		go c.serveMetrics()
+		klog.Infof("Starting api server")
+		// use those certificates to configure the web server
+		c.ws.TLSConfig = &tls.Config{
+			GetCertificate: c.getOperatorCertificate,
+		}
+		if err := c.ws.ListenAndServeTLS("", ""); err != http.ErrServerClosed {
+			klog.Infof("HTTPS server ListenAndServeTLS: %v", err)
+			return
+		}
+	}()
+}
+
+// TenantTransports returns the transports the Operator connects to the tenants with, for the Bucket and IAM
+// controllers to share them
+func (c *Controller) TenantTransports() *TenantTransports {
+	return c.transports
+}
+
 // Stop is called to shutdown the controller
 func (c *Controller) Stop() {
 	klog.Info("Stopping the minio controller webhook")
 	// Wait upto 5 secs and terminate all connections.
 	tctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	_ = c.ws.Shutdown(tctx)
+	_ = c.ms.Shutdown(tctx)
 	cancel()
 
 	klog.Info("Stopping the minio controller")
@@ -738,9 +783,13 @@ func (c *Controller) processNextWorkItem() bool {
 		klog.V(2).Infof("Key from workqueue: %s", key)
 		// Run the syncHandler, passing it the namespace/name string of the
 		// Tenant resource to be synced.
-		if err := c.syncHandler(key); err != nil {
+		start := time.Now()
+		err := c.syncHandler(key)
+		c.observeReconcile(key, time.Since(start), err)
+		if err != nil {
 			// Put the item back on the workqueue to handle any transient errors.
 			c.workqueue.AddRateLimited(key)
+			c.metrics.countWorkqueueRetry()
 			return fmt.Errorf("error syncing '%s': %s", key, err.Error())
 		}
 		// Finally, if no error occurs we Forget this item so it does not
@@ -757,6 +806,15 @@ func (c *Controller) processNextWorkItem() bool {
 	return true
 }
 
+// observeReconcile records a reconcile in the metrics of the tenant, unless the tenant was deleted
+func (c *Controller) observeReconcile(key string, duration time.Duration, err error) {
+	namespace, tenantName := key2NamespaceName(key)
+	if _, gerr := c.tenantsLister.Tenants(namespace).Get(tenantName); gerr != nil {
+		return
+	}
+	c.metrics.observeReconcile(namespace, tenantName, duration, err)
+}
+
 const slashSeparator = "/"
 
 func key2NamespaceName(key string) (namespace, name string) {
@@ -791,6 +849,8 @@ func (c *Controller) syncHandler(key string) error {
 		// The Tenant resource may no longer exist, in which case we stop processing.
 		if k8serrors.IsNotFound(err) {
 			runtime.HandleError(fmt.Errorf("Tenant '%s' in work queue no longer exists", key))
+			c.metrics.deleteTenant(namespace, tenantName)
+			c.transports.Forget(namespace, tenantName)
 			return nil
 		}
 		return nil
@@ -803,6 +863,9 @@ func (c *Controller) syncHandler(key string) error {
 	// Validate the MinIO Tenant
 	if err = tenant.Validate(); err != nil {
 		klog.V(2).Infof(err.Error())
+		if tenant.Status.CurrentState != err.Error() {
+			c.recorder.Event(tenant, corev1.EventTypeWarning, "ValidationFailed", err.Error())
+		}
 		var err2 error
 		if _, err2 = c.updateTenantStatus(ctx, tenant, err.Error(), 0); err2 != nil {
 			klog.V(2).Infof(err2.Error())
@@ -810,6 +873,23 @@ func (c *Controller) syncHandler(key string) error {
 		// return nil so we don't re-queue this work item
 		return nil
 	}
+	// Refuse the images the allow-list of the Operator doesn't allow
+	if disallowed := tenant.DisallowedImages(); len(disallowed) > 0 {
+		msg := fmt.Sprintf("Images not allowed by the Operator: %s", strings.Join(disallowed, ", "))
+		klog.V(2).Infof("Tenant '%s': %s", key, msg)
+		if tenant.Status.CurrentState != msg {
+			c.recorder.Event(tenant, corev1.EventTypeWarning, "ImagesNotAllowed", msg)
+		}
+		if _, err = c.updateTenantStatus(ctx, tenant, msg, 0); err != nil {
+			klog.V(2).Infof(err.Error())
+		}
+		// return nil so we don't re-queue this work item
+		return nil
+	}
+	// Tenants created before several tenants could share a namespace keep their MinIO service
+	if tenant, err = c.checkLegacyServiceName(ctx, tenant); err != nil {
+		return err
+	}
 	// AutoCertEnabled verification is used to manage the tenant migration between v1 and v2
 	// Previous behavior was that AutoCert is disabled by default if RequestAutoCert is nil
 	// New behavior is that AutoCert is enabled by default if RequestAutoCert is nil
@@ -860,19 +940,26 @@ func (c *Controller) syncHandler(key string) error {
 		} else {
 			return err
 		}
+	} else if !hlSvc.Spec.PublishNotReadyAddresses {
+		// MinIO servers must resolve each other before they pass their readiness probe
+		klog.V(2).Infof("Publishing not ready addresses in Headless Service for cluster %q", nsName)
+		hlSvcCopy := hlSvc.DeepCopy()
+		hlSvcCopy.Spec.PublishNotReadyAddresses = true
+		if hlSvc, err = c.kubeClientSet.CoreV1().Services(tenant.Namespace).Update(ctx, hlSvcCopy, uOpts); err != nil {
+			return err
+		}
 	}
 
-	// List all MinIO Tenants in this namespace.
-	li, err := c.tenantsLister.Tenants(tenant.Namespace).List(labels.NewSelector())
-	if err != nil {
-		return err
-	}
-
-	// Only 1 minio tenant per namespace allowed.
-	if len(li) > 1 {
+	// The services resolving the buckets are named after the buckets, only the oldest tenant of the namespace
+	// with Bucket DNS enabled gets them
+	if tenant.S3BucketDNS() {
+		li, err := c.tenantsLister.Tenants(tenant.Namespace).List(labels.NewSelector())
+		if err != nil {
+			return err
+		}
 		for _, t := range li {
-			if t.Status.CurrentState != StatusInitialized {
-				if _, err = c.updateTenantStatus(ctx, t, StatusFailedAlreadyExists, 0); err != nil {
+			if t.Name != tenant.Name && t.S3BucketDNS() && tenantCreatedBefore(t, tenant) {
+				if _, err = c.updateTenantStatus(ctx, tenant, StatusFailedBucketDNSConflict, 0); err != nil {
 					return err
 				}
 				// return nil so we don't re-queue this work item
@@ -881,19 +968,32 @@ func (c *Controller) syncHandler(key string) error {
 		}
 	}
 
+	// Isolate the pods of the tenant before creating them
+	if err = c.checkNetworkPolicies(ctx, tenant); err != nil {
+		return err
+	}
+
 	minioSecretName := tenant.Spec.CredsSecret.Name
 	minioSecret, err := c.kubeClientSet.CoreV1().Secrets(tenant.Namespace).Get(ctx, minioSecretName, gOpts)
 	if err != nil {
 		return err
 	}
 
-	adminClnt, err := tenant.NewMinIOAdmin(minioSecret.Data)
+	adminClnt, err := tenant.NewMinIOAdmin(minioSecret.Data, c.transports.Transport(tenant))
 	if err != nil {
 		return err
 	}
+	// Restart the pools if the root credentials changed
+	if tenant, err = c.checkCredsRotation(ctx, tenant, minioSecret); err != nil {
+		return err
+	}
 	// For each pool check if there is a stateful set
 	var totalReplicas int32
 	var images []string
+	// changes restarting the pods wait for the maintenance window
+	now := time.Now()
+	windowOpen := tenant.MaintenanceWindowOpen(now)
+	var pending []string
 
 	err = c.checkKESStatus(ctx, tenant, totalReplicas, cOpts, uOpts, nsName)
 	if err != nil {
@@ -902,15 +1002,16 @@ func (c *Controller) syncHandler(key string) error {
 	}
 
 	// Copy Operator TLS certificate to Tenant Namespace
-	operatorTLSSecret, err := c.kubeClientSet.CoreV1().Secrets(miniov2.GetNSFromFile()).Get(ctx, operatorTLSSecretName, metav1.GetOptions{})
+	operatorTLSSecret, err := c.kubeClientSet.CoreV1().Secrets(miniov2.GetNSFromFile()).Get(ctx, miniov2.OperatorTLSSecretName, metav1.GetOptions{})
 	if err != nil {
 		return err
 	}
-	if val, ok := operatorTLSSecret.Data["public.crt"]; ok {
+	operatorCertKey, _ := miniov2.OperatorTLSKeys()
+	if val, ok := operatorTLSSecret.Data[operatorCertKey]; ok {
 		secret := &corev1.Secret{
 			Type: "Opaque",
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      operatorTLSSecretName,
+				Name:      tenant.OperatorTLSSecretCopyName(),
 				Namespace: tenant.Namespace,
 				Labels:    tenant.MinIOPodLabels(),
 				OwnerReferences: []metav1.OwnerReference{
@@ -929,6 +1030,20 @@ func (c *Controller) syncHandler(key string) error {
 		if err != nil && !k8serrors.IsAlreadyExists(err) {
 			return err
 		}
+		if err != nil {
+			// follow the renewals of the operator certificate
+			existing, err := c.kubeClientSet.CoreV1().Secrets(tenant.Namespace).Get(ctx, tenant.OperatorTLSSecretCopyName(), gOpts)
+			if err != nil {
+				return err
+			}
+			if metav1.IsControlledBy(existing, tenant) && !bytes.Equal(existing.Data["public.crt"], val) {
+				existing = existing.DeepCopy()
+				existing.Data = secret.Data
+				if _, err = c.kubeClientSet.CoreV1().Secrets(tenant.Namespace).Update(ctx, existing, uOpts); err != nil {
+					return err
+				}
+			}
+		}
 	}
 	// consolidate the status of all pools. this is meant to cover for legacy tenants
 	// this status value is zero only for new tenants or legacy tenants
@@ -953,6 +1068,8 @@ func (c *Controller) syncHandler(key string) error {
 
 	// Check if this is fresh setup not an expansion.
 	freshSetup := len(tenant.Spec.Pools) == len(tenant.Status.Pools)
+	// Block evictions while MinIO can't take a server down, if requested
+	gated := tenant.HasPodDisruptionBudgetEnabled() && evictionsGated(tenant, c.transports.Transport(tenant))
 	for i, pool := range tenant.Spec.Pools {
 		// Get the StatefulSet with the name specified in Tenant.status.pools[i].SSName
 
@@ -971,6 +1088,13 @@ func (c *Controller) syncHandler(key string) error {
 				return err
 			}
 		}
+		// Decommissioned pools are no longer part of MinIO, clean up what's left of them
+		if tenant.PoolDecommissioned(i) {
+			if err = c.removeDecommissionedPool(ctx, tenant, i); err != nil {
+				return err
+			}
+			continue
+		}
 		ss, err := c.statefulSetLister.StatefulSets(tenant.Namespace).Get(ssName)
 		if k8serrors.IsNotFound(err) {
 
@@ -978,7 +1102,7 @@ func (c *Controller) syncHandler(key string) error {
 
 			// Check healthcheck for previous pool only if its not a fresh setup,
 			// if they are online before adding this pool.
-			if !freshSetup && !tenant.MinIOHealthCheck() {
+			if !freshSetup && !tenant.MinIOHealthCheck(c.transports.Transport(tenant)) {
 				klog.Infof("Deploying pool failed %s", pool.Name)
 				return ErrMinIONotReady
 			}
@@ -988,21 +1112,38 @@ func (c *Controller) syncHandler(key string) error {
 			}
 
 			ss = statefulsets.NewPool(tenant, secret, &pool, hlSvc.Name, c.hostsTemplate, c.operatorVersion)
+			// the pools keep running the previous image after a rolled back upgrade
+			if upgrade := tenant.RolledBackUpgrade(); upgrade != nil {
+				ss.Spec.Template.Spec.Containers[0].Image = miniov2.RewriteImage(upgrade.From)
+			}
+			c.keepResizedPool(ss, &pool, windowOpen)
 			ss, err = c.kubeClientSet.AppsV1().StatefulSets(tenant.Namespace).Create(ctx, ss, cOpts)
 			if err != nil {
 				return err
 			}
-
-			// Report the pool is properly created
-			tenant.Status.Pools[i].State = miniov2.PoolCreated
-			// push updates to status
-			if tenant, err = c.updatePoolStatus(ctx, tenant); err != nil {
+			if tenant, err = c.completePoolResize(ctx, tenant, i, ss); err != nil {
 				return err
 			}
+
+			// A statefulset recreated for a pool that is already part of MinIO
+			// doesn't change the MinIO args, nor the state of the pool
+			newPool := tenant.Status.Pools[i].State == miniov2.PoolNotCreated
+			if newPool {
+				c.recorder.Event(tenant, corev1.EventTypeNormal, "PoolCreated",
+					fmt.Sprintf("Created statefulset %s for pool %s", ss.Name, pool.Name))
+				// Report the pool is properly created
+				tenant.Status.Pools[i].State = miniov2.PoolCreated
+				// push updates to status
+				if tenant, err = c.updatePoolStatus(ctx, tenant); err != nil {
+					return err
+				}
+			}
 			// Restart the services to fetch the new args, ignore any error.
 			// only perform `restart()` of server deployment when we are truly
 			// expanding an existing deployment.
-			if !freshSetup {
+			if !freshSetup && newPool {
+				c.recorder.Event(tenant, corev1.EventTypeNormal, "PoolsRestarted",
+					fmt.Sprintf("Restarting MinIO to expand the tenant with pool %s", pool.Name))
 				adminClnt.ServiceRestart(ctx) //nolint:errcheck
 			}
 		} else {
@@ -1012,6 +1153,10 @@ func (c *Controller) syncHandler(key string) error {
 					return err
 				}
 			}
+			// Expand the volumes of the pool if the storage requested grew
+			if tenant, ss, err = c.checkPoolVolumeExpansion(ctx, tenant, i, ss); err != nil {
+				return err
+			}
 			// Verify if this pool matches the spec on the tenant (resources, affinity, sidecars, etc)
 			poolMatchesSS, err := poolSSMatchesSpec(tenant, &pool, ss, c.operatorVersion)
 			if err != nil {
@@ -1026,6 +1171,10 @@ func (c *Controller) syncHandler(key string) error {
 				}
 
 				nss := statefulsets.NewPool(tenant, secret, &pool, hlSvc.Name, c.hostsTemplate, c.operatorVersion)
+				keepPoolRollout(nss, ss)
+				if !windowOpen {
+					pending = append(pending, deferPoolChanges(nss, ss, &pool)...)
+				}
 				ssCopy := ss.DeepCopy()
 
 				ssCopy.Spec.Template = nss.Spec.Template
@@ -1038,8 +1187,11 @@ func (c *Controller) syncHandler(key string) error {
 					ssCopy.Spec.Template.ObjectMeta.Labels[k] = v
 				}
 
-				if ss, err = c.kubeClientSet.AppsV1().StatefulSets(tenant.Namespace).Update(ctx, ssCopy, uOpts); err != nil {
-					return err
+				// nothing left to update when every change is deferred
+				if !equality.Semantic.DeepEqual(ssCopy.Spec, ss.Spec) {
+					if ss, err = c.kubeClientSet.AppsV1().StatefulSets(tenant.Namespace).Update(ctx, ssCopy, uOpts); err != nil {
+						return err
+					}
 				}
 			}
 
@@ -1056,9 +1208,19 @@ func (c *Controller) syncHandler(key string) error {
 			return nil
 		}
 
+		// Limit evictions to what the erasure sets of the pool tolerate
+		if tenant.HasPodDisruptionBudgetEnabled() {
+			err = c.checkPoolPDB(ctx, tenant, &pool, ss.Name, gated)
+		} else {
+			err = c.removePoolPDB(ctx, tenant, ss.Name)
+		}
+		if err != nil {
+			return err
+		}
+
 		// keep track of all replicas
 		totalReplicas += ss.Status.Replicas
-		images = append(images, ss.Spec.Template.Spec.Containers[0].Image)
+		images = append(images, miniov2.RewriteImage(ss.Spec.Template.Spec.Containers[0].Image))
 	}
 	// validate each pool if it's initialized
 	for pi, pool := range tenant.Spec.Pools {
@@ -1074,7 +1236,7 @@ func (c *Controller) syncHandler(key string) error {
 			if len(pods.Items) > 0 {
 				ssPod := pods.Items[0]
 				podAddress := fmt.Sprintf("%s:9000", tenant.MinIOHLPodHostname(ssPod.Name))
-				podAdminClnt, err := tenant.NewMinIOAdminForAddress(podAddress, minioSecret.Data)
+				podAdminClnt, err := tenant.NewMinIOAdminForAddress(podAddress, minioSecret.Data, c.transports.Transport(tenant))
 				if err != nil {
 					return err
 				}
@@ -1082,6 +1244,8 @@ func (c *Controller) syncHandler(key string) error {
 				_, err = podAdminClnt.ServerInfo(ctx)
 				// any error means we are not ready, if the call succeeds, the ss is ready
 				if err == nil {
+					c.recorder.Event(tenant, corev1.EventTypeNormal, "PoolInitialized",
+						fmt.Sprintf("Pool %s is part of MinIO", pool.Name))
 					// Report the pool is properly created
 					tenant.Status.Pools[pi].State = miniov2.PoolInitialized
 					// push updates to status
@@ -1095,8 +1259,19 @@ func (c *Controller) syncHandler(key string) error {
 		}
 	}
 
-	// compare all the images across all pools, they should always be the same.
+	// drive the decommission of the pools flagged for it
+	if tenant, err = c.checkPoolsDecommission(ctx, tenant, adminClnt, minioSecret.Data); err != nil {
+		if _, terr := c.updateTenantStatus(ctx, tenant, err.Error(), totalReplicas); terr != nil {
+			return terr
+		}
+		return err
+	}
+
+	// compare all the images across all pools, they should always be the same, except during a rolling upgrade
 	for _, image := range images {
+		if tenant.CurrentUpgrade() != nil {
+			break
+		}
 		for i := 0; i < len(images); i++ {
 			if image != images[i] {
 				if _, err = c.updateTenantStatus(ctx, tenant, StatusInconsistentMinIOVersions, totalReplicas); err != nil {
@@ -1108,13 +1283,28 @@ func (c *Controller) syncHandler(key string) error {
 		}
 	}
 
-	// In loop above we compared all the versions in all pools.
-	// So comparing tenant.Spec.Image (version to update to) against one value from images slice is fine.
-	if tenant.Spec.Image != images[0] && tenant.Status.CurrentState != StatusUpdatingMinIOVersion {
-		if !tenant.MinIOHealthCheck() {
+	upgrade := tenant.CurrentUpgrade()
+	if upgrade == nil && !windowOpen && tenant.RolledBackUpgrade() == nil && imageOutdated(miniov2.RewriteImage(tenant.Spec.Image), images) {
+		// Upgrades start once the maintenance window opens, the upgrades in progress carry on
+		pending = append(pending, fmt.Sprintf("image %s", tenant.Spec.Image))
+	} else if tenant.GetUpgradeStrategy() == miniov2.RollingUpgradeStrategy ||
+		upgrade != nil && upgrade.Strategy == miniov2.RollingUpgradeStrategy {
+		// Rolling upgrades restart the pods with the new image one at a time
+		if tenant, err = c.checkRollingUpgrade(ctx, tenant, images, totalReplicas); err != nil {
+			return err
+		}
+	} else if miniov2.RewriteImage(tenant.Spec.Image) != images[0] && tenant.Status.CurrentState != StatusUpdatingMinIOVersion && tenant.RolledBackUpgrade() == nil {
+		// In loop above we compared all the versions in all pools.
+		// So comparing tenant.Spec.Image (version to update to) against one value from images slice is fine.
+		if !tenant.MinIOHealthCheck(c.transports.Transport(tenant)) {
 			return ErrMinIONotReady
 		}
 
+		var ready bool
+		if tenant, ready, err = c.upgradePreflight(ctx, tenant, images[0], totalReplicas); err != nil || !ready {
+			return err
+		}
+
 		// Images different with the newer state change, continue to verify
 		// if upgrade is possible
 		tenant, err = c.updateTenantStatus(ctx, tenant, StatusUpdatingMinIOVersion, totalReplicas)
@@ -1124,10 +1314,21 @@ func (c *Controller) syncHandler(key string) error {
 
 		klog.V(4).Infof("Collecting artifacts for Tenant '%s' to update MinIO from: %s, to: %s",
 			tenantName, images[0], tenant.Spec.Image)
+		c.recorder.Event(tenant, corev1.EventTypeNormal, "UpgradeStarted",
+			fmt.Sprintf("Updating MinIO from %s to %s", images[0], tenant.Spec.Image))
+		if tenant, err = c.startUpgrade(ctx, tenant, images[0], miniov2.InPlaceUpgradeStrategy); err != nil {
+			return err
+		}
 
 		latest, err := c.fetchArtifacts(tenant)
 		if err != nil {
 			_ = c.removeArtifacts()
+			c.metrics.countUpgrade(tenant.Namespace, tenant.Name, upgradeFailed)
+			c.recorder.Event(tenant, corev1.EventTypeWarning, "UpgradeFailed",
+				fmt.Sprintf("Unable to collect the artifacts of %s: %v", tenant.Spec.Image, err))
+			if _, terr := c.endUpgrade(ctx, tenant, miniov2.UpgradeFailed, err.Error()); terr != nil {
+				return terr
+			}
 			return err
 		}
 		updateURL, err := tenant.UpdateURL(latest, fmt.Sprintf("http://operator.%s.svc.%s:%s%s",
@@ -1136,8 +1337,13 @@ func (c *Controller) syncHandler(key string) error {
 		))
 		if err != nil {
 			_ = c.removeArtifacts()
+			c.metrics.countUpgrade(tenant.Namespace, tenant.Name, upgradeFailed)
 
 			err = fmt.Errorf("Unable to get canonical update URL for Tenant '%s', failed with %v", tenantName, err)
+			c.recorder.Event(tenant, corev1.EventTypeWarning, "UpgradeFailed", err.Error())
+			if _, terr := c.endUpgrade(ctx, tenant, miniov2.UpgradeFailed, err.Error()); terr != nil {
+				return terr
+			}
 			if _, terr := c.updateTenantStatus(ctx, tenant, err.Error(), totalReplicas); terr != nil {
 				return terr
 			}
@@ -1152,8 +1358,13 @@ func (c *Controller) syncHandler(key string) error {
 		us, err := adminClnt.ServerUpdate(ctx, updateURL)
 		if err != nil {
 			_ = c.removeArtifacts()
+			c.metrics.countUpgrade(tenant.Namespace, tenant.Name, upgradeFailed)
 
 			err = fmt.Errorf("Tenant '%s' MinIO update failed with %w", tenantName, err)
+			c.recorder.Event(tenant, corev1.EventTypeWarning, "UpgradeFailed", err.Error())
+			if _, terr := c.endUpgrade(ctx, tenant, miniov2.UpgradeFailed, err.Error()); terr != nil {
+				return terr
+			}
 			if _, terr := c.updateTenantStatus(ctx, tenant, err.Error(), totalReplicas); terr != nil {
 				return terr
 			}
@@ -1165,11 +1376,23 @@ func (c *Controller) syncHandler(key string) error {
 		if us.CurrentVersion != us.UpdatedVersion {
 			klog.Infof("Tenant '%s' MinIO updated successfully from: %s, to: %s successfully",
 				tenantName, us.CurrentVersion, us.UpdatedVersion)
+			c.metrics.countUpgrade(tenant.Namespace, tenant.Name, upgradeSucceeded)
+			c.recorder.Event(tenant, corev1.EventTypeNormal, "Upgraded",
+				fmt.Sprintf("MinIO updated from %s to %s", us.CurrentVersion, us.UpdatedVersion))
+			if tenant, err = c.endUpgrade(ctx, tenant, miniov2.UpgradeSucceeded, fmt.Sprintf("MinIO updated from %s to %s", us.CurrentVersion, us.UpdatedVersion)); err != nil {
+				return err
+			}
 		} else {
 			msg := fmt.Sprintf("Tenant '%s' MinIO is already running the most recent version of %s",
 				tenantName,
 				us.CurrentVersion)
 			klog.Info(msg)
+			c.metrics.countUpgrade(tenant.Namespace, tenant.Name, upgradeUpToDate)
+			c.recorder.Event(tenant, corev1.EventTypeNormal, "UpgradeSkipped",
+				fmt.Sprintf("MinIO already runs the most recent version %s", us.CurrentVersion))
+			if tenant, err = c.endUpgrade(ctx, tenant, miniov2.UpgradeUpToDate, msg); err != nil {
+				return err
+			}
 			if _, terr := c.updateTenantStatus(ctx, tenant, msg, totalReplicas); terr != nil {
 				return err
 			}
@@ -1179,7 +1402,10 @@ func (c *Controller) syncHandler(key string) error {
 		// clean the local directory
 		_ = c.removeArtifacts()
 
-		for _, pool := range tenant.Spec.Pools {
+		for pi, pool := range tenant.Spec.Pools {
+			if tenant.PoolDecommissioned(pi) {
+				continue
+			}
 			// Now proceed to make the yaml changes for the tenant statefulset.
 			ss := statefulsets.NewPool(tenant, secret, &pool, hlSvc.Name, c.hostsTemplate, c.operatorVersion)
 			if _, err = c.kubeClientSet.AppsV1().StatefulSets(tenant.Namespace).Update(ctx, ss, uOpts); err != nil {
@@ -1189,6 +1415,16 @@ func (c *Controller) syncHandler(key string) error {
 
 	}
 
+	// Report the changes waiting for the maintenance window
+	if tenant, err = c.checkPendingChanges(ctx, tenant, key, pending, now); err != nil {
+		return err
+	}
+
+	// Once the pools run with rotated root credentials, update everything derived from them
+	if tenant, err = c.completeCredsRotation(ctx, tenant, adminClnt, minioSecret, totalReplicas); err != nil {
+		return err
+	}
+
 	// Check whether console is enabled or if it should be removed and the state of it's service
 	err = c.checkConsoleStatus(ctx, tenant, totalReplicas, adminClnt, cOpts, uOpts, nsName)
 	if err != nil {
@@ -1196,6 +1432,11 @@ func (c *Controller) syncHandler(key string) error {
 		return err
 	}
 
+	// Expose MinIO and Console through Ingresses or Gateway API routes
+	if tenant, err = c.checkExternalExposure(ctx, tenant); err != nil {
+		return err
+	}
+
 	if tenant.HasLogEnabled() {
 		var logSecret *corev1.Secret
 		logSecret, err = c.checkAndCreateLogSecret(ctx, tenant)
@@ -1223,7 +1464,7 @@ func (c *Controller) syncHandler(key string) error {
 			return err
 		}
 		// Make sure that MinIO is up and running to enable Log Search.
-		if !tenant.MinIOHealthCheck() {
+		if !tenant.MinIOHealthCheck(c.transports.Transport(tenant)) {
 			if _, err = c.updateTenantStatus(ctx, tenant, StatusWaitingForReadyState, totalReplicas); err != nil {
 				return err
 			}
@@ -1263,6 +1504,12 @@ func (c *Controller) syncHandler(key string) error {
 		}
 	}
 
+	// Delete the secrets once shared by the tenants of the namespace after the pools restarted with the
+	// secrets of the tenant
+	if err = c.deleteLegacySecrets(ctx, tenant); err != nil {
+		return err
+	}
+
 	// Finally, we update the status block of the Tenant resource to reflect the
 	// current state of the world
 	_, err = c.updateTenantStatus(ctx, tenant, StatusInitialized, totalReplicas)
@@ -1537,8 +1784,18 @@ func (c *Controller) checkAndCreatePrometheusStatefulSet(ctx context.Context, te
 }
 
 func (c *Controller) checkAndCreatePrometheusServiceMonitorSecret(ctx context.Context, tenant *miniov2.Tenant, accessKey, secretKey string) error {
-	_, err := c.kubeClientSet.CoreV1().Secrets(tenant.Namespace).Get(ctx, tenant.PromServiceMonitorSecret(), metav1.GetOptions{})
-	if err == nil || !k8serrors.IsNotFound(err) {
+	secret, err := c.kubeClientSet.CoreV1().Secrets(tenant.Namespace).Get(ctx, tenant.PromServiceMonitorSecret(), metav1.GetOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	} else if err == nil {
+		// check if the token needs to be signed with new root credentials
+		updatedSecret := secrets.UpdatePromServiceMonitorSecret(tenant, accessKey, secretKey, secret)
+		if updatedSecret == nil {
+			return nil
+		}
+
+		klog.V(2).Infof("Updating Prometheus Service Monitor secret for %s", tenant.Name)
+		_, err = c.kubeClientSet.CoreV1().Secrets(tenant.Namespace).Update(ctx, updatedSecret, metav1.UpdateOptions{})
 		return err
 	}
 
@@ -1547,7 +1804,7 @@ func (c *Controller) checkAndCreatePrometheusServiceMonitorSecret(ctx context.Co
 	}
 
 	klog.V(2).Infof("Creating a new Prometheus Service Monitor secret for %s", tenant.Namespace)
-	secret := secrets.PromServiceMonitorSecret(tenant, accessKey, secretKey)
+	secret = secrets.PromServiceMonitorSecret(tenant, accessKey, secretKey)
 	_, err = c.kubeClientSet.CoreV1().Secrets(tenant.Namespace).Create(ctx, secret, metav1.CreateOptions{})
 	return err
 }
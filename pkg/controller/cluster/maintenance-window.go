@@ -0,0 +1,64 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// imageOutdated returns whether a pool doesn't run the image of the tenant
+func imageOutdated(image string, images []string) bool {
+	for _, i := range images {
+		if i != image {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPendingChanges reports the changes deferred until the maintenance window opens in the status of the tenant,
+// the tenant is queued again for the window to apply them
+func (c *Controller) checkPendingChanges(ctx context.Context, tenant *miniov2.Tenant, key string, pending []string, now time.Time) (*miniov2.Tenant, error) {
+	var next *metav1.Time
+	if len(pending) > 0 {
+		if start := tenant.NextMaintenanceWindow(now); !start.IsZero() {
+			next = &metav1.Time{Time: start}
+			c.workqueue.AddAfter(key, start.Sub(now))
+		}
+	}
+	changed := !reflect.DeepEqual(pending, tenant.Status.PendingChanges)
+	if !changed && next.Equal(tenant.Status.NextMaintenanceWindow) {
+		return tenant, nil
+	}
+	if changed && len(pending) > 0 {
+		msg := fmt.Sprintf("Deferred until the maintenance window opens: %s", strings.Join(pending, ", "))
+		if next != nil {
+			msg = fmt.Sprintf("%s, next window at %s", msg, next.UTC().Format(time.RFC3339))
+		}
+		c.recorder.Event(tenant, corev1.EventTypeNormal, "ChangesDeferred", msg)
+	}
+	return c.updatePendingChangesStatus(ctx, tenant, pending, next)
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package cluster
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"strings"
+	"time"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// metricsReviewTimeout bounds the reviews of the token of a request for the metrics
+const metricsReviewTimeout = 10 * time.Second
+
+func configureMetricsServer(c *Controller) *http.Server {
+	router := http.NewServeMux()
+	router.Handle(miniov2.OperatorMetricsPath, c.authorizeMetrics(c.metrics.handler()))
+	return &http.Server{
+		Addr:           ":" + miniov2.OperatorMetricsPort,
+		Handler:        router,
+		ReadTimeout:    10 * time.Second,
+		WriteTimeout:   10 * time.Second,
+		MaxHeaderBytes: 1 << 20,
+	}
+}
+
+// authorizeMetrics only lets through the requests with a bearer token of a user allowed to get the metrics path,
+// like the service account of Prometheus
+func (c *Controller) authorizeMetrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || token == r.Header.Get("Authorization") {
+			http.Error(w, "a bearer token is required", http.StatusUnauthorized)
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), metricsReviewTimeout)
+		defer cancel()
+
+		tokenReview, err := c.kubeClientSet.AuthenticationV1().TokenReviews().Create(ctx, &authenticationv1.TokenReview{
+			Spec: authenticationv1.TokenReviewSpec{Token: token},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			klog.Errorf("Unable to review the token of a request for the metrics: %v", err)
+			http.Error(w, "unable to review the token", http.StatusInternalServerError)
+			return
+		}
+		if !tokenReview.Status.Authenticated {
+			http.Error(w, "the token is not valid", http.StatusUnauthorized)
+			return
+		}
+
+		user := tokenReview.Status.User
+		extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+		for key, value := range user.Extra {
+			extra[key] = authorizationv1.ExtraValue(value)
+		}
+		accessReview, err := c.kubeClientSet.AuthorizationV1().SubjectAccessReviews().Create(ctx, &authorizationv1.SubjectAccessReview{
+			Spec: authorizationv1.SubjectAccessReviewSpec{
+				User:   user.Username,
+				UID:    user.UID,
+				Groups: user.Groups,
+				Extra:  extra,
+				NonResourceAttributes: &authorizationv1.NonResourceAttributes{
+					Path: r.URL.Path,
+					Verb: "get",
+				},
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			klog.Errorf("Unable to review the access of %s to the metrics: %v", user.Username, err)
+			http.Error(w, "unable to review the access", http.StatusInternalServerError)
+			return
+		}
+		if !accessReview.Status.Allowed {
+			http.Error(w, user.Username+" is not allowed to get the metrics", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// serveMetrics serves the metrics with the certificate of the Operator, every replica serves them but only the
+// leader exports the metrics of the controller
+func (c *Controller) serveMetrics() {
+	c.ms.TLSConfig = &tls.Config{
+		GetCertificate: c.getOperatorCertificate,
+	}
+	if err := c.ms.ListenAndServeTLS("", ""); err != http.ErrServerClosed {
+		klog.Infof("Metrics server ListenAndServeTLS: %v", err)
+	}
+}
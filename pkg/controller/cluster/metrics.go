@@ -0,0 +1,255 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+package cluster
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Outcomes of the MinIO upgrades of a tenant
+const (
+	upgradeSucceeded  = "succeeded"
+	upgradeFailed     = "failed"
+	upgradeUpToDate   = "up_to_date"
+	upgradeRolledBack = "rolled_back"
+)
+
+// upgradeResults are the outcomes of the upgrades, the counters of a deleted tenant are removed for each of them
+var upgradeResults = []string{upgradeSucceeded, upgradeFailed, upgradeUpToDate, upgradeRolledBack}
+
+// reconcileDurationBuckets are the upper bounds of the buckets of the reconcile duration histogram, in seconds
+var reconcileDurationBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120}
+
+// healthStatuses are the health colors of a tenant, exported as one gauge each
+var healthStatuses = []miniov2.HealthStatus{miniov2.HealthStatusGreen, miniov2.HealthStatusYellow, miniov2.HealthStatusRed}
+
+// tenantMetric identifies the metrics of a tenant
+type tenantMetric struct {
+	namespace string
+	tenant    string
+}
+
+// operatorMetrics keeps the metrics of the Operator. The collectors of the controller are only registered once
+// this replica leads the Operator, so the other replicas don't export empty series.
+type operatorMetrics struct {
+	registry *prometheus.Registry
+
+	certificateExpiry *prometheus.GaugeVec
+	reconciles        *prometheus.CounterVec
+	reconcileErrors   *prometheus.CounterVec
+	reconcileDuration *prometheus.HistogramVec
+	upgrades          *prometheus.CounterVec
+	drivesOnline      *prometheus.GaugeVec
+	drivesOffline     *prometheus.GaugeVec
+	drivesHealing     *prometheus.GaugeVec
+	writeQuorum       *prometheus.GaugeVec
+	healthStatus      *prometheus.GaugeVec
+	workqueueRetries  prometheus.Counter
+	// workqueueDepth exports the number of tenants waiting to be reconciled, nil when there is no workqueue
+	workqueueDepth prometheus.GaugeFunc
+
+	mu sync.Mutex
+	// certificates are the secrets of the certificates of each tenant with an expiry exported, to remove them
+	// with the tenant
+	certificates map[tenantMetric]map[string]bool
+}
+
+// newOperatorMetrics returns the metrics of the Operator, workqueueDepth returns the number of tenants waiting to
+// be reconciled and may be nil
+func newOperatorMetrics(workqueueDepth func() int) *operatorMetrics {
+	tenantLabels := []string{"namespace", "tenant"}
+	m := &operatorMetrics{
+		registry: prometheus.NewRegistry(),
+		certificateExpiry: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "minio_operator_certificate_expiry_timestamp_seconds",
+			Help: "Time the certificates managed by the operator expire, in seconds since the Unix epoch",
+		}, []string{"namespace", "tenant", "secret"}),
+		reconciles: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "minio_operator_tenant_reconcile_total",
+			Help: "Number of reconciles of the tenant",
+		}, tenantLabels),
+		reconcileErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "minio_operator_tenant_reconcile_errors_total",
+			Help: "Number of reconciles of the tenant that failed",
+		}, tenantLabels),
+		reconcileDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "minio_operator_tenant_reconcile_duration_seconds",
+			Help:    "Duration of the reconciles of the tenant, in seconds",
+			Buckets: reconcileDurationBuckets,
+		}, tenantLabels),
+		upgrades: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "minio_operator_tenant_upgrades_total",
+			Help: "Number of attempts to upgrade the MinIO of the tenant, by outcome",
+		}, []string{"namespace", "tenant", "result"}),
+		drivesOnline: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "minio_operator_tenant_drives_online",
+			Help: "Number of drives of the tenant online",
+		}, tenantLabels),
+		drivesOffline: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "minio_operator_tenant_drives_offline",
+			Help: "Number of drives of the tenant offline",
+		}, tenantLabels),
+		drivesHealing: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "minio_operator_tenant_drives_healing",
+			Help: "Number of drives of the tenant healing",
+		}, tenantLabels),
+		writeQuorum: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "minio_operator_tenant_write_quorum",
+			Help: "Number of drives online the tenant needs to accept writes",
+		}, tenantLabels),
+		healthStatus: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "minio_operator_tenant_health_status",
+			Help: "Health color of the tenant, 1 for its current color",
+		}, []string{"namespace", "tenant", "status"}),
+		workqueueRetries: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "minio_operator_workqueue_retries_total",
+			Help: "Number of tenants put back on the workqueue after a failed reconcile",
+		}),
+		certificates: make(map[tenantMetric]map[string]bool),
+	}
+	if workqueueDepth != nil {
+		m.workqueueDepth = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "minio_operator_workqueue_depth",
+			Help: "Number of tenants waiting to be reconciled",
+		}, func() float64 {
+			return float64(workqueueDepth())
+		})
+	}
+	// the metrics of the process are exported by every replica
+	m.registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// register registers the collectors of the controller, called once this replica leads the Operator
+func (m *operatorMetrics) register() error {
+	collectors := []prometheus.Collector{
+		m.certificateExpiry,
+		m.reconciles,
+		m.reconcileErrors,
+		m.reconcileDuration,
+		m.upgrades,
+		m.drivesOnline,
+		m.drivesOffline,
+		m.drivesHealing,
+		m.writeQuorum,
+		m.healthStatus,
+		m.workqueueRetries,
+	}
+	if m.workqueueDepth != nil {
+		collectors = append(collectors, m.workqueueDepth)
+	}
+	for _, collector := range collectors {
+		if err := m.registry.Register(collector); err != nil {
+			if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setCertificateExpiry records the end of the validity of a certificate
+func (m *operatorMetrics) setCertificateExpiry(namespace, tenant, secret string, notAfter time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := tenantMetric{namespace: namespace, tenant: tenant}
+	if m.certificates[key] == nil {
+		m.certificates[key] = make(map[string]bool)
+	}
+	m.certificates[key][secret] = true
+	m.certificateExpiry.WithLabelValues(namespace, tenant, secret).Set(float64(notAfter.Unix()))
+}
+
+// observeReconcile records a reconcile of a tenant, failed when err is not nil
+func (m *operatorMetrics) observeReconcile(namespace, tenant string, duration time.Duration, err error) {
+	m.reconciles.WithLabelValues(namespace, tenant).Inc()
+	if err != nil {
+		m.reconcileErrors.WithLabelValues(namespace, tenant).Inc()
+	} else {
+		// export the errors of the tenant before it fails once
+		m.reconcileErrors.WithLabelValues(namespace, tenant)
+	}
+	m.reconcileDuration.WithLabelValues(namespace, tenant).Observe(duration.Seconds())
+}
+
+// countUpgrade records an attempt to upgrade the MinIO of a tenant and its outcome
+func (m *operatorMetrics) countUpgrade(namespace, tenant, result string) {
+	m.upgrades.WithLabelValues(namespace, tenant, result).Inc()
+}
+
+// countWorkqueueRetry records a tenant put back on the workqueue after a failed reconcile
+func (m *operatorMetrics) countWorkqueueRetry() {
+	m.workqueueRetries.Inc()
+}
+
+// setTenantHealth records the health of a tenant
+func (m *operatorMetrics) setTenantHealth(namespace, tenant string, status *miniov2.TenantStatus) {
+	m.drivesOnline.WithLabelValues(namespace, tenant).Set(float64(status.DrivesOnline))
+	m.drivesOffline.WithLabelValues(namespace, tenant).Set(float64(status.DrivesOffline))
+	m.drivesHealing.WithLabelValues(namespace, tenant).Set(float64(status.DrivesHealing))
+	m.writeQuorum.WithLabelValues(namespace, tenant).Set(float64(status.WriteQuorum))
+	for _, color := range healthStatuses {
+		value := 0.0
+		if status.HealthStatus == color {
+			value = 1
+		}
+		m.healthStatus.WithLabelValues(namespace, tenant, string(color)).Set(value)
+	}
+}
+
+// deleteTenant removes the metrics of a deleted tenant
+func (m *operatorMetrics) deleteTenant(namespace, tenant string) {
+	m.mu.Lock()
+	key := tenantMetric{namespace: namespace, tenant: tenant}
+	for secret := range m.certificates[key] {
+		m.certificateExpiry.DeleteLabelValues(namespace, tenant, secret)
+	}
+	delete(m.certificates, key)
+	m.mu.Unlock()
+
+	for _, vec := range []*prometheus.MetricVec{
+		m.reconciles.MetricVec,
+		m.reconcileErrors.MetricVec,
+		m.reconcileDuration.MetricVec,
+		m.drivesOnline.MetricVec,
+		m.drivesOffline.MetricVec,
+		m.drivesHealing.MetricVec,
+		m.writeQuorum.MetricVec,
+	} {
+		vec.DeleteLabelValues(namespace, tenant)
+	}
+	for _, result := range upgradeResults {
+		m.upgrades.DeleteLabelValues(namespace, tenant, result)
+	}
+	for _, color := range healthStatuses {
+		m.healthStatus.DeleteLabelValues(namespace, tenant, string(color))
+	}
+}
+
+// handler exports the registered metrics in the Prometheus text format
+func (m *operatorMetrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
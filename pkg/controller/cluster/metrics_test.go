@@ -0,0 +1,241 @@
+// This file is part of MinIO Operator
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cluster
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func Test_operatorMetrics_register(t *testing.T) {
+	m := newOperatorMetrics(func() int { return 3 })
+	m.setCertificateExpiry("ns", "tenant", "tenant-tls", time.Unix(1700000000, 0))
+
+	// the replicas that don't lead the operator export no series of the controller
+	families, err := m.registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if strings.HasPrefix(family.GetName(), "minio_operator_") {
+			t.Errorf("Gather() = %s before register(), want no metric of the controller", family.GetName())
+		}
+	}
+
+	if err := m.register(); err != nil {
+		t.Fatalf("register() error = %v", err)
+	}
+	if err := m.register(); err != nil {
+		t.Fatalf("register() twice error = %v", err)
+	}
+	want := `# HELP minio_operator_workqueue_depth Number of tenants waiting to be reconciled
+# TYPE minio_operator_workqueue_depth gauge
+minio_operator_workqueue_depth 3
+# HELP minio_operator_certificate_expiry_timestamp_seconds Time the certificates managed by the operator expire, in seconds since the Unix epoch
+# TYPE minio_operator_certificate_expiry_timestamp_seconds gauge
+minio_operator_certificate_expiry_timestamp_seconds{namespace="ns",secret="tenant-tls",tenant="tenant"} 1.7e+09
+`
+	if err := testutil.GatherAndCompare(m.registry, strings.NewReader(want),
+		"minio_operator_workqueue_depth", "minio_operator_certificate_expiry_timestamp_seconds"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_operatorMetrics_certificates(t *testing.T) {
+	m := newOperatorMetrics(nil)
+	m.setCertificateExpiry("ns", "tenant", "tenant-tls", time.Unix(1700000000, 0))
+	m.setCertificateExpiry("ns", "other", "other-tls", time.Unix(1600000000, 0))
+	m.setCertificateExpiry("operator", "", "operator-tls", time.Unix(1800000000, 0))
+	m.deleteTenant("ns", "other")
+
+	want := `# HELP minio_operator_certificate_expiry_timestamp_seconds Time the certificates managed by the operator expire, in seconds since the Unix epoch
+# TYPE minio_operator_certificate_expiry_timestamp_seconds gauge
+minio_operator_certificate_expiry_timestamp_seconds{namespace="ns",secret="tenant-tls",tenant="tenant"} 1.7e+09
+minio_operator_certificate_expiry_timestamp_seconds{namespace="operator",secret="operator-tls",tenant=""} 1.8e+09
+`
+	if err := testutil.CollectAndCompare(m.certificateExpiry, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_operatorMetrics_tenant(t *testing.T) {
+	m := newOperatorMetrics(nil)
+	m.observeReconcile("ns", "tenant", 200*time.Millisecond, nil)
+	m.observeReconcile("ns", "tenant", 3*time.Second, errors.New("failed"))
+	m.countUpgrade("ns", "tenant", upgradeFailed)
+	m.countUpgrade("ns", "tenant", upgradeSucceeded)
+	m.countWorkqueueRetry()
+	m.setTenantHealth("ns", "tenant", &miniov2.TenantStatus{
+		DrivesOnline:  3,
+		DrivesOffline: 1,
+		WriteQuorum:   3,
+		HealthStatus:  miniov2.HealthStatusYellow,
+	})
+	m.observeReconcile("ns", "other", time.Second, nil)
+	m.countUpgrade("ns", "other", upgradeUpToDate)
+	m.setTenantHealth("ns", "other", &miniov2.TenantStatus{HealthStatus: miniov2.HealthStatusGreen})
+	m.deleteTenant("ns", "other")
+	if err := m.register(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# HELP minio_operator_tenant_reconcile_total Number of reconciles of the tenant
+# TYPE minio_operator_tenant_reconcile_total counter
+minio_operator_tenant_reconcile_total{namespace="ns",tenant="tenant"} 2
+# HELP minio_operator_tenant_reconcile_errors_total Number of reconciles of the tenant that failed
+# TYPE minio_operator_tenant_reconcile_errors_total counter
+minio_operator_tenant_reconcile_errors_total{namespace="ns",tenant="tenant"} 1
+# HELP minio_operator_tenant_reconcile_duration_seconds Duration of the reconciles of the tenant, in seconds
+# TYPE minio_operator_tenant_reconcile_duration_seconds histogram
+minio_operator_tenant_reconcile_duration_seconds_bucket{namespace="ns",tenant="tenant",le="0.1"} 0
+minio_operator_tenant_reconcile_duration_seconds_bucket{namespace="ns",tenant="tenant",le="0.25"} 1
+minio_operator_tenant_reconcile_duration_seconds_bucket{namespace="ns",tenant="tenant",le="0.5"} 1
+minio_operator_tenant_reconcile_duration_seconds_bucket{namespace="ns",tenant="tenant",le="1"} 1
+minio_operator_tenant_reconcile_duration_seconds_bucket{namespace="ns",tenant="tenant",le="2.5"} 1
+minio_operator_tenant_reconcile_duration_seconds_bucket{namespace="ns",tenant="tenant",le="5"} 2
+minio_operator_tenant_reconcile_duration_seconds_bucket{namespace="ns",tenant="tenant",le="10"} 2
+minio_operator_tenant_reconcile_duration_seconds_bucket{namespace="ns",tenant="tenant",le="30"} 2
+minio_operator_tenant_reconcile_duration_seconds_bucket{namespace="ns",tenant="tenant",le="60"} 2
+minio_operator_tenant_reconcile_duration_seconds_bucket{namespace="ns",tenant="tenant",le="120"} 2
+minio_operator_tenant_reconcile_duration_seconds_bucket{namespace="ns",tenant="tenant",le="+Inf"} 2
+minio_operator_tenant_reconcile_duration_seconds_sum{namespace="ns",tenant="tenant"} 3.2
+minio_operator_tenant_reconcile_duration_seconds_count{namespace="ns",tenant="tenant"} 2
+# HELP minio_operator_tenant_upgrades_total Number of attempts to upgrade the MinIO of the tenant, by outcome
+# TYPE minio_operator_tenant_upgrades_total counter
+minio_operator_tenant_upgrades_total{namespace="ns",result="failed",tenant="tenant"} 1
+minio_operator_tenant_upgrades_total{namespace="ns",result="succeeded",tenant="tenant"} 1
+# HELP minio_operator_tenant_drives_online Number of drives of the tenant online
+# TYPE minio_operator_tenant_drives_online gauge
+minio_operator_tenant_drives_online{namespace="ns",tenant="tenant"} 3
+# HELP minio_operator_tenant_drives_offline Number of drives of the tenant offline
+# TYPE minio_operator_tenant_drives_offline gauge
+minio_operator_tenant_drives_offline{namespace="ns",tenant="tenant"} 1
+# HELP minio_operator_tenant_write_quorum Number of drives online the tenant needs to accept writes
+# TYPE minio_operator_tenant_write_quorum gauge
+minio_operator_tenant_write_quorum{namespace="ns",tenant="tenant"} 3
+# HELP minio_operator_tenant_health_status Health color of the tenant, 1 for its current color
+# TYPE minio_operator_tenant_health_status gauge
+minio_operator_tenant_health_status{namespace="ns",status="green",tenant="tenant"} 0
+minio_operator_tenant_health_status{namespace="ns",status="red",tenant="tenant"} 0
+minio_operator_tenant_health_status{namespace="ns",status="yellow",tenant="tenant"} 1
+# HELP minio_operator_workqueue_retries_total Number of tenants put back on the workqueue after a failed reconcile
+# TYPE minio_operator_workqueue_retries_total counter
+minio_operator_workqueue_retries_total 1
+`
+	if err := testutil.GatherAndCompare(m.registry, strings.NewReader(want),
+		"minio_operator_tenant_reconcile_total",
+		"minio_operator_tenant_reconcile_errors_total",
+		"minio_operator_tenant_reconcile_duration_seconds",
+		"minio_operator_tenant_upgrades_total",
+		"minio_operator_tenant_drives_online",
+		"minio_operator_tenant_drives_offline",
+		"minio_operator_tenant_write_quorum",
+		"minio_operator_tenant_health_status",
+		"minio_operator_workqueue_retries_total",
+	); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_authorizeMetrics(t *testing.T) {
+	tests := []struct {
+		name          string
+		authorization string
+		authenticated bool
+		allowed       bool
+		wantStatus    int
+	}{
+		{
+			name:       "no token",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:          "not a bearer token",
+			authorization: "Basic dXNlcjpwYXNz",
+			wantStatus:    http.StatusUnauthorized,
+		},
+		{
+			name:          "invalid token",
+			authorization: "Bearer invalid",
+			wantStatus:    http.StatusUnauthorized,
+		},
+		{
+			name:          "user not allowed",
+			authorization: "Bearer token",
+			authenticated: true,
+			wantStatus:    http.StatusForbidden,
+		},
+		{
+			name:          "user allowed",
+			authorization: "Bearer token",
+			authenticated: true,
+			allowed:       true,
+			wantStatus:    http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kubeClient := fake.NewSimpleClientset()
+			var review *authorizationv1.SubjectAccessReview
+			kubeClient.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				tokenReview := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+				tokenReview.Status.Authenticated = tt.authenticated && tokenReview.Spec.Token == "token"
+				tokenReview.Status.User = authenticationv1.UserInfo{
+					Username: "system:serviceaccount:monitoring:prometheus",
+					Groups:   []string{"system:serviceaccounts"},
+				}
+				return true, tokenReview, nil
+			})
+			kubeClient.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				review = action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+				review.Status.Allowed = tt.allowed
+				return true, review, nil
+			})
+			c := &Controller{kubeClientSet: kubeClient}
+			handler := c.authorizeMetrics(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			r := httptest.NewRequest(http.MethodGet, miniov2.OperatorMetricsPath, nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.wantStatus {
+				t.Errorf("authorizeMetrics() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if review != nil {
+				attributes := review.Spec.NonResourceAttributes
+				if review.Spec.User != "system:serviceaccount:monitoring:prometheus" || attributes == nil ||
+					attributes.Path != miniov2.OperatorMetricsPath || attributes.Verb != "get" {
+					t.Errorf("authorizeMetrics() reviewed %+v, want the access of the user to get the metrics", review.Spec)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package cluster
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// The minisign signature algorithms: the legacy one signs the file itself, the default one its BLAKE2b-512 hash
+var (
+	minisignLegacyAlgorithm = []byte("Ed")
+	minisignHashedAlgorithm = []byte("ED")
+)
+
+const minisignTrustedCommentPrefix = "trusted comment: "
+
+// minisignSignature is a signature of a file created by minisign
+type minisignSignature struct {
+	algorithm       []byte
+	keyID           []byte
+	signature       []byte
+	trustedComment  string
+	globalSignature []byte
+}
+
+// parseMinisignPublicKey decodes a minisign public key, the base64 encoding of the algorithm, the key ID and the
+// Ed25519 public key
+func parseMinisignPublicKey(pubKey string) (keyID []byte, key ed25519.PublicKey, err error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(pubKey))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid minisign public key: %v", err)
+	}
+	if len(raw) != 2+8+ed25519.PublicKeySize || !bytes.Equal(raw[:2], minisignLegacyAlgorithm) {
+		return nil, nil, errors.New("invalid minisign public key")
+	}
+	return raw[2:10], ed25519.PublicKey(raw[10:]), nil
+}
+
+// parseMinisignSignature decodes a minisign signature file: an untrusted comment, the signature of the file, a
+// trusted comment and the signature of the signature along with the trusted comment
+func parseMinisignSignature(data []byte) (*minisignSignature, error) {
+	lines := strings.Split(strings.TrimSpace(strings.ReplaceAll(string(data), "\r\n", "\n")), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[2], minisignTrustedCommentPrefix) {
+		return nil, errors.New("invalid minisign signature")
+	}
+	raw, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(raw) != 2+8+ed25519.SignatureSize {
+		return nil, errors.New("invalid minisign signature")
+	}
+	globalSignature, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil || len(globalSignature) != ed25519.SignatureSize {
+		return nil, errors.New("invalid minisign global signature")
+	}
+	return &minisignSignature{
+		algorithm:       raw[:2],
+		keyID:           raw[2:10],
+		signature:       raw[10:],
+		trustedComment:  strings.TrimPrefix(lines[2], minisignTrustedCommentPrefix),
+		globalSignature: globalSignature,
+	}, nil
+}
+
+// verifyMinisign verifies the minisign signature of a file with a minisign public key
+func verifyMinisign(pubKey, path, signaturePath string) error {
+	keyID, key, err := parseMinisignPublicKey(pubKey)
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(signaturePath)
+	if err != nil {
+		return err
+	}
+	sig, err := parseMinisignSignature(data)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(sig.keyID, keyID) {
+		return errors.New("the minisign signature was created with another key")
+	}
+
+	var message []byte
+	switch {
+	case bytes.Equal(sig.algorithm, minisignHashedAlgorithm):
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		h, _ := blake2b.New512(nil)
+		if _, err = io.Copy(h, f); err != nil {
+			return err
+		}
+		message = h.Sum(nil)
+	case bytes.Equal(sig.algorithm, minisignLegacyAlgorithm):
+		if message, err = ioutil.ReadFile(path); err != nil {
+			return err
+		}
+	default:
+		return errors.New("unsupported minisign signature algorithm")
+	}
+
+	if !ed25519.Verify(key, message, sig.signature) {
+		return fmt.Errorf("invalid minisign signature of %s", path)
+	}
+	if !ed25519.Verify(key, append(append([]byte{}, sig.signature...), sig.trustedComment...), sig.globalSignature) {
+		return fmt.Errorf("invalid minisign trusted comment signature of %s", path)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// This file is part of MinIO Operator
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cluster
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// minisignTestSign signs a file like minisign, prehashing the file with the hashed algorithm
+func minisignTestSign(t *testing.T, key ed25519.PrivateKey, keyID, algorithm, data []byte, trustedComment string) []byte {
+	t.Helper()
+	message := data
+	if string(algorithm) == "ED" {
+		h := blake2b.Sum512(data)
+		message = h[:]
+	}
+	signature := ed25519.Sign(key, message)
+	globalSignature := ed25519.Sign(key, append(append([]byte{}, signature...), trustedComment...))
+	return []byte(fmt.Sprintf("untrusted comment: signature from minisign secret key\n%s\ntrusted comment: %s\n%s\n",
+		base64.StdEncoding.EncodeToString(append(append(append([]byte{}, algorithm...), keyID...), signature...)),
+		trustedComment,
+		base64.StdEncoding.EncodeToString(globalSignature)))
+}
+
+func Test_verifyMinisign(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyID := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	pubKey := base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), keyID...), public...))
+
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "minio.RELEASE.2021-06-17T00-10-46Z")
+	data := []byte("minio binary")
+	if err = ioutil.WriteFile(binary, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		pubKey    string
+		signature []byte
+		wantErr   bool
+	}{
+		{"prehashed", pubKey, minisignTestSign(t, private, keyID, []byte("ED"), data, "timestamp:1623888646"), false},
+		{"legacy", pubKey, minisignTestSign(t, private, keyID, []byte("Ed"), data, "timestamp:1623888646"), false},
+		{"other file", pubKey, minisignTestSign(t, private, keyID, []byte("ED"), []byte("another binary"), "timestamp:1623888646"), true},
+		{"other key ID", pubKey, minisignTestSign(t, private, []byte{8, 7, 6, 5, 4, 3, 2, 1}, []byte("ED"), data, "timestamp:1623888646"), true},
+		{"invalid public key", "RWTx5Zr1", minisignTestSign(t, private, keyID, []byte("ED"), data, "timestamp:1623888646"), true},
+		{"invalid signature", pubKey, []byte("untrusted comment: nothing\n"), true},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signature := filepath.Join(dir, fmt.Sprintf("minio.%d.minisig", i))
+			if err := ioutil.WriteFile(signature, tt.signature, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := verifyMinisign(tt.pubKey, binary, signature); (err != nil) != tt.wantErr {
+				t.Errorf("verifyMinisign() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// the trusted comment can't be changed
+	signature := minisignTestSign(t, private, keyID, []byte("ED"), data, "timestamp:1623888646")
+	tampered := bytes.Replace(signature, []byte("timestamp:1623888646"), []byte("timestamp:1623888647"), 1)
+	path := filepath.Join(dir, "minio.tampered.minisig")
+	if err = ioutil.WriteFile(path, tampered, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err = verifyMinisign(pubKey, binary, path); err == nil {
+		t.Errorf("verifyMinisign() accepted a tampered trusted comment")
+	}
+}
@@ -19,7 +19,6 @@ package cluster
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
 	"log"
 	"net"
@@ -29,6 +28,7 @@ import (
 
 	"k8s.io/klog/v2"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"k8s.io/apimachinery/pkg/labels"
@@ -36,10 +36,16 @@ import (
 	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
 )
 
+// serversUsageRefreshInterval is how often the usage counters of the drives are refreshed in the status of a
+// tenant when its health doesn't change
+const serversUsageRefreshInterval = 30 * time.Minute
+
 // recurrentTenantStatusMonitor loop that checks every 3 minutes for tenants health
 func (c *Controller) recurrentTenantStatusMonitor(stopCh <-chan struct{}) {
+	// time the usage counters of the drives of each tenant were last written to its status
+	usageRefreshed := map[string]time.Time{}
 	// do an initial check, then start the periodic check
-	if err := c.tenantsHealthMonitor(); err != nil {
+	if err := c.tenantsHealthMonitor(usageRefreshed); err != nil {
 		log.Println(err)
 	}
 	// How often will this function run
@@ -51,7 +57,7 @@ func (c *Controller) recurrentTenantStatusMonitor(stopCh <-chan struct{}) {
 	for {
 		select {
 		case <-ticker.C:
-			if err := c.tenantsHealthMonitor(); err != nil {
+			if err := c.tenantsHealthMonitor(usageRefreshed); err != nil {
 				log.Println(err)
 			}
 		case <-stopCh:
@@ -62,13 +68,25 @@ func (c *Controller) recurrentTenantStatusMonitor(stopCh <-chan struct{}) {
 
 }
 
-func (c *Controller) tenantsHealthMonitor() error {
+func (c *Controller) tenantsHealthMonitor(usageRefreshed map[string]time.Time) error {
 	// list all tenants and get their cluster health
 	tenants, err := c.tenantsLister.Tenants("").List(labels.NewSelector())
 	if err != nil {
 		return err
 	}
+	listed := map[string]bool{}
 	for _, tenant := range tenants {
+		listed[tenant.Namespace+"/"+tenant.Name] = true
+	}
+	for key := range usageRefreshed {
+		if !listed[key] {
+			delete(usageRefreshed, key)
+		}
+	}
+	for _, tenant := range tenants {
+		// never modify the tenants of the lister
+		tenant = tenant.DeepCopy()
+		previous := tenant.Status.DeepCopy()
 		// don't get the tenant cluster health if it doesn't have at least 1 pool initialized
 		oneInitialized := false
 		for _, pool := range tenant.Status.Pools {
@@ -81,7 +99,7 @@ func (c *Controller) tenantsHealthMonitor() error {
 		}
 
 		// get cluster health for tenant
-		healthResult, err := getMinIOHealthStatus(tenant, RegularMode)
+		healthResult, err := getMinIOHealthStatus(tenant, c.transports.Transport(tenant), RegularMode)
 		if err != nil {
 			// show the error and continue
 			klog.V(2).Infof(err.Error())
@@ -97,7 +115,7 @@ func (c *Controller) tenantsHealthMonitor() error {
 			continue
 		}
 
-		adminClnt, err := tenant.NewMinIOAdmin(minioSecret.Data)
+		adminClnt, err := tenant.NewMinIOAdmin(minioSecret.Data, c.transports.Transport(tenant))
 		if err != nil {
 			// show the error and continue
 			klog.V(2).Infof(err.Error())
@@ -128,7 +146,9 @@ func (c *Controller) tenantsHealthMonitor() error {
 
 		tenant.Status.DrivesOnline = int32(onlineDisks)
 		tenant.Status.DrivesOffline = int32(offlineDisks)
+		tenant.Status.Servers = serversHealth(tenant, storageInfo.Disks)
 
+		previousHealth := tenant.Status.HealthStatus
 		tenant.Status.HealthStatus = miniov2.HealthStatusGreen
 
 		if tenant.Status.DrivesOffline > 0 || tenant.Status.DrivesHealing > 0 {
@@ -137,10 +157,27 @@ func (c *Controller) tenantsHealthMonitor() error {
 		if tenant.Status.DrivesOnline < tenant.Status.WriteQuorum {
 			tenant.Status.HealthStatus = miniov2.HealthStatusRed
 		}
+		c.metrics.setTenantHealth(tenant.Namespace, tenant.Name, &tenant.Status)
+		if tenant.Status.HealthStatus != previousHealth {
+			eventType := corev1.EventTypeNormal
+			if tenant.Status.HealthStatus != miniov2.HealthStatusGreen {
+				eventType = corev1.EventTypeWarning
+			}
+			c.recorder.Event(tenant, eventType, "HealthStatusChanged",
+				fmt.Sprintf("Health changed to %s: %d drives online, %d offline, %d healing, write quorum %d",
+					tenant.Status.HealthStatus, tenant.Status.DrivesOnline, tenant.Status.DrivesOffline,
+					tenant.Status.DrivesHealing, tenant.Status.WriteQuorum))
+		}
 
+		key := tenant.Namespace + "/" + tenant.Name
+		if !healthChanged(previous, &tenant.Status) && time.Since(usageRefreshed[key]) < serversUsageRefreshInterval {
+			continue
+		}
 		if _, err = c.updatePoolStatus(context.Background(), tenant); err != nil {
 			klog.V(2).Infof(err.Error())
+			continue
 		}
+		usageRefreshed[key] = time.Now()
 
 	}
 	return nil
@@ -163,43 +200,17 @@ const (
 	RegularMode = "RegularMode"
 )
 
-func getHealthCheckTransport() *http.Transport {
-	// Keep TLS config.
-	tlsConfig := &tls.Config{
-		// Can't use SSLv3 because of POODLE and BEAST
-		// Can't use TLSv1.0 because of POODLE and BEAST using CBC cipher
-		// Can't use TLSv1.1 because of RC4 cipher usage
-		MinVersion:         tls.VersionTLS12,
-		InsecureSkipVerify: true, // FIXME: use trusted CA
-	}
-	return &http.Transport{
-		Proxy: http.ProxyFromEnvironment,
-		DialContext: (&net.Dialer{
-			Timeout:   2 * time.Second,
-			KeepAlive: 10 * time.Second,
-		}).DialContext,
-		ResponseHeaderTimeout: 5 * time.Second,
-		TLSHandshakeTimeout:   5 * time.Second,
-		ExpectContinueTimeout: 5 * time.Second,
-		TLSClientConfig:       tlsConfig,
-		// Go net/http automatically unzip if content-type is
-		// gzip disable this feature, as we are always interested
-		// in raw stream.
-		DisableCompression: true,
-	}
-}
-
 // getMinIOHealthStatus returns the cluster health for a Tenant.
 // There's two types of questions we can make to MinIO's cluster/health one asking if the cluster is healthy `RegularMode`
 // or if it's acceptable to remove a node `MaintenanceMode`
-func getMinIOHealthStatus(tenant *miniov2.Tenant, mode HealthMode) (*HealthResult, error) {
-	return getMinIOHealthStatusWithRetry(tenant, mode, 5)
+func getMinIOHealthStatus(tenant *miniov2.Tenant, tr *http.Transport, mode HealthMode) (*HealthResult, error) {
+	return getMinIOHealthStatusWithRetry(tenant, tr, mode, 5)
 }
 
 // getMinIOHealthStatusWithRetry returns the cluster health for a Tenant.
 // There's two types of questions we can make to MinIO's cluster/health one asking if the cluster is healthy `RegularMode`
 // or if it's acceptable to remove a node `MaintenanceMode`
-func getMinIOHealthStatusWithRetry(tenant *miniov2.Tenant, mode HealthMode, tryCount int) (*HealthResult, error) {
+func getMinIOHealthStatusWithRetry(tenant *miniov2.Tenant, tr *http.Transport, mode HealthMode, tryCount int) (*HealthResult, error) {
 	// build the endpoint to contact the Tenant
 	svcURL := tenant.GetTenantServiceURL()
 
@@ -215,9 +226,9 @@ func getMinIOHealthStatusWithRetry(tenant *miniov2.Tenant, mode HealthMode, tryC
 	}
 
 	httpClient := &http.Client{
-		Transport: getHealthCheckTransport(),
+		Transport: tr,
+		Timeout:   miniov2.HealthCheckTimeout,
 	}
-	defer httpClient.CloseIdleConnections()
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
@@ -225,11 +236,13 @@ func getMinIOHealthStatusWithRetry(tenant *miniov2.Tenant, mode HealthMode, tryC
 		if err, ok := err.(net.Error); ok && err.Timeout() && tryCount > 0 {
 			log.Printf("health check failed, retrying %d, err: %s", tryCount, err)
 			time.Sleep(10 * time.Second)
-			return getMinIOHealthStatusWithRetry(tenant, mode, tryCount-1)
+			return getMinIOHealthStatusWithRetry(tenant, tr, mode, tryCount-1)
 		}
 		log.Println("error pinging", err)
 		return nil, err
 	}
+	// the transport is shared, release the connection
+	defer resp.Body.Close()
 	driveskHealing := 0
 	if resp.Header.Get("X-Minio-Healing-Drives") != "" {
 		val, err := strconv.Atoi(resp.Header.Get("X-Minio-Healing-Drives"))
@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package cluster
+
+import (
+	"context"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	"github.com/minio/operator/pkg/resources/networkpolicies"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+)
+
+// checkNetworkPolicies creates or updates the NetworkPolicies isolating the components of the tenant,
+// and removes the ones of components that are no longer deployed or of a tenant no longer isolated
+func (c *Controller) checkNetworkPolicies(ctx context.Context, tenant *miniov2.Tenant) error {
+	expected := make(map[string]*networkingv1.NetworkPolicy)
+	if tenant.HasNetworkPolicyEnabled() {
+		for _, policy := range networkpolicies.NewForTenant(tenant, miniov2.GetNSFromFile()) {
+			expected[policy.Name] = policy
+		}
+	}
+
+	selector := labels.SelectorFromSet(labels.Set{miniov2.TenantLabel: tenant.Name})
+	existing, err := c.kubeClientSet.NetworkingV1().NetworkPolicies(tenant.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return err
+	}
+	for i := range existing.Items {
+		policy := &existing.Items[i]
+		if !metav1.IsControlledBy(policy, tenant) {
+			continue
+		}
+		want, ok := expected[policy.Name]
+		if !ok {
+			klog.Infof("Removing NetworkPolicy %s of Tenant '%s/%s'", policy.Name, tenant.Namespace, tenant.Name)
+			if err = c.kubeClientSet.NetworkingV1().NetworkPolicies(tenant.Namespace).Delete(ctx, policy.Name, metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+				return err
+			}
+			continue
+		}
+		delete(expected, policy.Name)
+		if equality.Semantic.DeepEqual(want.Spec, policy.Spec) {
+			continue
+		}
+		klog.Infof("Updating NetworkPolicy %s of Tenant '%s/%s'", policy.Name, tenant.Namespace, tenant.Name)
+		policy.Spec = want.Spec
+		if _, err = c.kubeClientSet.NetworkingV1().NetworkPolicies(tenant.Namespace).Update(ctx, policy, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+
+	for _, policy := range expected {
+		klog.Infof("Creating NetworkPolicy %s for Tenant '%s/%s'", policy.Name, tenant.Namespace, tenant.Name)
+		if _, err = c.kubeClientSet.NetworkingV1().NetworkPolicies(tenant.Namespace).Create(ctx, policy, metav1.CreateOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
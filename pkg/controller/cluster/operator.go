@@ -18,148 +18,112 @@ package cluster
 
 import (
 	"context"
-	"crypto/rand"
-	"crypto/x509"
-	"crypto/x509/pkix"
-	"encoding/pem"
+	"crypto/tls"
 	"errors"
 	"fmt"
-
-	"k8s.io/apimachinery/pkg/runtime/schema"
-
-	k8serrors "k8s.io/apimachinery/pkg/api/errors"
-
-	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
 
 	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 )
 
-var (
-	errOperatorWaitForTLS = errors.New("waiting for Operator cert")
+const (
+	// operatorCertificateCheckInterval is how often the Operator checks whether its certificate needs to be renewed
+	operatorCertificateCheckInterval = time.Hour
+	// operatorCertificateRetryInterval is how often the Operator retries issuing or loading its certificate
+	operatorCertificateRetryInterval = 10 * time.Second
 )
 
-func generateOperatorCryptoData() ([]byte, []byte, error) {
-	privateKey, err := newPrivateKey(miniov2.DefaultEllipticCurve)
+// checkOperatorCertificate makes sure the TLS certificate of the Operator webhooks is issued, requesting
+// it with the provider configured for the Operator otherwise, and renews it when needed
+func (c *Controller) checkOperatorCertificate(ctx context.Context, operator metav1.Object) error {
+	provider := c.operatorCertificateProvider()
+	req := operatorCertificateRequest(operator)
+	issued, err := provider.check(ctx, req)
 	if err != nil {
-		klog.Errorf("Unexpected error during the ECDSA Key generation: %v", err)
-		return nil, nil, err
-	}
-
-	privKeyBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
-	if err != nil {
-		klog.Errorf("Unexpected error during encoding the ECDSA Private Key: %v", err)
-		return nil, nil, err
+		return err
 	}
-
-	opCommon := fmt.Sprintf("operator.%s.svc.%s", miniov2.GetNSFromFile(), miniov2.GetClusterDomain())
-	opCommonNoDomain := fmt.Sprintf("operator.%s.svc", miniov2.GetNSFromFile())
-
-	csrTemplate := x509.CertificateRequest{
-		Subject: pkix.Name{
-			CommonName:   fmt.Sprintf("system:node:%s", opCommonNoDomain),
-			Organization: []string{"system:nodes"},
-		},
-		Extensions: []pkix.Extension{
-			{
-				Id:       nil,
-				Critical: false,
-				Value:    []byte("operator"),
-			},
-			{
-				Id:       nil,
-				Critical: false,
-				Value:    []byte(opCommonNoDomain),
-			},
-			{
-				Id:       nil,
-				Critical: false,
-				Value:    []byte(opCommon),
-			},
-		},
-		SignatureAlgorithm: x509.ECDSAWithSHA512,
-		DNSNames:           []string{"operator", opCommonNoDomain, opCommon},
+	if !issued {
+		klog.V(2).Infof("Requesting the certificate of secret/%s for the Operator", req.secretName)
+		if err = provider.issue(ctx, req); err != nil {
+			return err
+		}
 	}
-
-	csrBytes, err := x509.CreateCertificateRequest(rand.Reader, &csrTemplate, privateKey)
+	cert, _, err := c.renewCertificate(ctx, req, provider)
 	if err != nil {
-		klog.Errorf("Unexpected error during creating the CSR: %v", err)
-		return nil, nil, err
+		return err
 	}
-	return privKeyBytes, csrBytes, nil
-}
-
-func (c *Controller) createOperatorSecret(ctx context.Context, operator metav1.Object, labels map[string]string, secretName string, pkBytes, certBytes []byte) error {
-	secret := &corev1.Secret{
-		Type: "Opaque",
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      secretName,
-			Namespace: miniov2.GetNSFromFile(),
-			Labels:    labels,
-			OwnerReferences: []metav1.OwnerReference{
-				*metav1.NewControllerRef(operator, schema.GroupVersionKind{
-					Group:   miniov2.SchemeGroupVersion.Group,
-					Version: miniov2.SchemeGroupVersion.Version,
-					Kind:    miniov2.OperatorCRDResourceKind,
-				}),
-			},
-		},
-		Data: map[string][]byte{
-			"private.key": pkBytes,
-			"public.crt":  certBytes,
-		},
+	c.metrics.setCertificateExpiry(req.namespace, "", req.secretName, cert.NotAfter)
+	if time.Now().After(expiryWarningTime(cert)) {
+		klog.Warningf("The certificate of the Operator in secret/%s expires on %s", req.secretName, cert.NotAfter.Format(time.RFC3339))
 	}
-	_, err := c.kubeClientSet.CoreV1().Secrets(miniov2.GetNSFromFile()).Create(ctx, secret, metav1.CreateOptions{})
-	return err
+	return nil
 }
 
-// createOperatorCSR handles all the steps required to create the CSR: from creation of keys, submitting CSR and
-// finally creating a secret that Operator deployment will use to mount private key and certificate for TLS
-// This Method Blocks till the CSR Request is approved via kubectl approve
-func (c *Controller) createOperatorCSR(ctx context.Context, operator metav1.Object) error {
-	privKeysBytes, csrBytes, err := generateOperatorCryptoData()
+// loadOperatorCertificate reads the TLS certificate of the Operator webhooks from its secret, it's served
+// from memory so a renewed certificate is picked up without restarting the webhook server
+func (c *Controller) loadOperatorCertificate(ctx context.Context) error {
+	secret, err := c.kubeClientSet.CoreV1().Secrets(miniov2.GetNSFromFile()).Get(ctx, miniov2.OperatorTLSSecretName, metav1.GetOptions{})
 	if err != nil {
-		klog.Errorf("Private Key and CSR generation failed with error: %v", err)
 		return err
 	}
-	namespace := miniov2.GetNSFromFile()
-	operatorCSRName := fmt.Sprintf("operator-%s-csr", namespace)
-	err = c.createCertificateSigningRequest(ctx, map[string]string{}, operatorCSRName, namespace, csrBytes, operator, "server")
+	certKey, keyKey := miniov2.OperatorTLSKeys()
+	keyPair, err := tls.X509KeyPair(secret.Data[certKey], secret.Data[keyKey])
 	if err != nil {
-		klog.Errorf("Unexpected error during the creation of the csr/%s: %v", operatorCSRName, err)
-		return err
+		return fmt.Errorf("operator TLS wrong format: %v", err)
 	}
+	c.operatorCert.Store(&keyPair)
+	return nil
+}
 
-	// fetch certificate from CSR
-	certBytes, err := c.fetchCertificate(ctx, operatorCSRName)
-	if err != nil {
-		klog.Errorf("Unexpected error during the creation of the csr/%s: %v", operatorCSRName, err)
-		return err
+// getOperatorCertificate returns the certificate the webhook server presents
+func (c *Controller) getOperatorCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	keyPair, ok := c.operatorCert.Load().(*tls.Certificate)
+	if !ok {
+		return nil, errors.New("the operator TLS certificate isn't loaded")
 	}
+	return keyPair, nil
+}
 
-	// PEM encode private ECDSA key
-	encodedPrivKey := pem.EncodeToMemory(&pem.Block{Type: privateKeyType, Bytes: privKeysBytes})
-
-	// Create secret for operator to use
-	err = c.createOperatorSecret(ctx, operator, map[string]string{}, "operator-tls", encodedPrivKey, certBytes)
-	if err != nil {
-		klog.Errorf("Unexpected error during the creation of the secret/%s: %v", "operator-tls", err)
-		return err
+// renewOperatorCertificate issues the TLS certificate of the Operator webhooks and periodically renews it until
+// the context is canceled, only the leader runs it
+func (c *Controller) renewOperatorCertificate(ctx context.Context) {
+	for {
+		interval := operatorCertificateCheckInterval
+		// operator deployment for owner reference
+		operator, err := c.kubeClientSet.AppsV1().Deployments(miniov2.GetNSFromFile()).Get(ctx, "minio-operator", metav1.GetOptions{})
+		if err == nil {
+			err = c.checkOperatorCertificate(ctx, operator)
+		}
+		if err == nil {
+			err = c.loadOperatorCertificate(ctx)
+		}
+		if err != nil {
+			klog.Errorf("Unable to issue the operator certificate: %v", err)
+			interval = operatorCertificateRetryInterval
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
 	}
-	return nil
 }
 
-func (c *Controller) checkAndCreateOperatorCSR(ctx context.Context, operator metav1.Object) error {
-	if _, err := c.certClient.CertificateSigningRequests().Get(ctx, "operator-auto-tls", metav1.GetOptions{}); err != nil {
-		if k8serrors.IsNotFound(err) {
-			klog.V(2).Infof("Creating a new Certificate Signing Request for Operator Server Certs, cluster %q")
-			if err = c.createOperatorCSR(ctx, operator); err != nil {
-				return err
+// reloadOperatorCertificate periodically reloads the TLS certificate of the Operator webhooks renewed by the
+// leader until the context is canceled
+func (c *Controller) reloadOperatorCertificate(ctx context.Context) {
+	ticker := time.NewTicker(operatorCertificateCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.loadOperatorCertificate(ctx); err != nil {
+				klog.Errorf("Unable to reload the operator certificate: %v", err)
 			}
-			return errOperatorWaitForTLS
 		}
-		return err
 	}
-	return nil
 }
@@ -0,0 +1,92 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+package cluster
+
+import (
+	"context"
+	"net/http"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	"github.com/minio/operator/pkg/resources/poddisruptionbudgets"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// evictionsGated tells whether the health gate of the tenant blocks every eviction, because MinIO
+// reports none of its servers can be taken down for maintenance
+func evictionsGated(tenant *miniov2.Tenant, tr *http.Transport) bool {
+	if tenant.Spec.PodDisruptionBudget == nil || !tenant.Spec.PodDisruptionBudget.HealthGate {
+		return false
+	}
+	// MinIO can't answer until at least one pool is initialized
+	initialized := false
+	for _, pool := range tenant.Status.Pools {
+		if pool.State == miniov2.PoolInitialized {
+			initialized = true
+		}
+	}
+	if !initialized {
+		return false
+	}
+	result, err := getMinIOHealthStatusWithRetry(tenant, tr, MaintenanceMode, 0)
+	if err != nil {
+		// evictions can't make an unreachable tenant any worse, don't block node drains because of it
+		klog.V(2).Infof("Unable to check if Tenant '%s/%s' allows maintenance: %v", tenant.Namespace, tenant.Name, err)
+		return false
+	}
+	return result.StatusCode != http.StatusOK
+}
+
+// checkPoolPDB creates or updates the PodDisruptionBudget of the pool so evictions never take more
+// servers down than its erasure sets tolerate, or none at all when gated
+func (c *Controller) checkPoolPDB(ctx context.Context, tenant *miniov2.Tenant, pool *miniov2.Pool, ssName string, gated bool) error {
+	maxUnavailable := tenant.PoolMaxUnavailableServers(pool)
+	if gated {
+		maxUnavailable = 0
+	}
+	expected := poddisruptionbudgets.NewForPool(tenant, pool, ssName, maxUnavailable)
+
+	pdb, err := c.kubeClientSet.PolicyV1beta1().PodDisruptionBudgets(tenant.Namespace).Get(ctx, expected.Name, metav1.GetOptions{})
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return err
+		}
+		klog.V(2).Infof("Creating PodDisruptionBudget %s allowing %d unavailable servers", expected.Name, maxUnavailable)
+		_, err = c.kubeClientSet.PolicyV1beta1().PodDisruptionBudgets(tenant.Namespace).Create(ctx, expected, metav1.CreateOptions{})
+		return err
+	}
+	if equality.Semantic.DeepEqual(pdb.Spec, expected.Spec) {
+		return nil
+	}
+
+	klog.V(2).Infof("Updating PodDisruptionBudget %s to allow %d unavailable servers", expected.Name, maxUnavailable)
+	pdbCopy := pdb.DeepCopy()
+	pdbCopy.Spec = expected.Spec
+	_, err = c.kubeClientSet.PolicyV1beta1().PodDisruptionBudgets(tenant.Namespace).Update(ctx, pdbCopy, metav1.UpdateOptions{})
+	return err
+}
+
+// removePoolPDB deletes the PodDisruptionBudget of the pool with the given statefulset, if any
+func (c *Controller) removePoolPDB(ctx context.Context, tenant *miniov2.Tenant, ssName string) error {
+	err := c.kubeClientSet.PolicyV1beta1().PodDisruptionBudgets(tenant.Namespace).Delete(ctx, ssName, metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -17,6 +17,7 @@
 package cluster
 
 import (
+	"fmt"
 	"reflect"
 	"strings"
 
@@ -58,6 +59,37 @@ func (c *Controller) getAllSSForTenant(tenant *miniov2.Tenant) (map[int]*appsv1.
 	return poolDir, nil
 }
 
+// keepPoolRollout carries the MinIO image and the rollout partition of the statefulset of a pool over to the
+// statefulset regenerated from the tenant, only the upgrades of the tenant change them. The registry rewrite rules
+// of the Operator still apply to the image.
+func keepPoolRollout(nss, ss *appsv1.StatefulSet) {
+	nss.Spec.Template.Spec.Containers[0].Image = miniov2.RewriteImage(ss.Spec.Template.Spec.Containers[0].Image)
+	if ss.Spec.UpdateStrategy.RollingUpdate != nil {
+		nss.Spec.UpdateStrategy.RollingUpdate = ss.Spec.UpdateStrategy.RollingUpdate.DeepCopy()
+	}
+}
+
+// deferPoolChanges carries the resources, the affinity and the image registry of the statefulset of a pool over to
+// the statefulset regenerated from the tenant while the maintenance window is closed, changing them would restart
+// the pods. It returns the changes deferred.
+func deferPoolChanges(nss, ss *appsv1.StatefulSet, pool *miniov2.Pool) []string {
+	var deferred []string
+	container, nContainer := &ss.Spec.Template.Spec.Containers[0], &nss.Spec.Template.Spec.Containers[0]
+	if nContainer.Image != container.Image {
+		nContainer.Image = container.Image
+		deferred = append(deferred, fmt.Sprintf("image registry of pool %s", pool.Name))
+	}
+	if !equality.Semantic.DeepEqual(nContainer.Resources, container.Resources) {
+		nContainer.Resources = *container.Resources.DeepCopy()
+		deferred = append(deferred, fmt.Sprintf("resources of pool %s", pool.Name))
+	}
+	if !equality.Semantic.DeepEqual(nss.Spec.Template.Spec.Affinity, ss.Spec.Template.Spec.Affinity) {
+		nss.Spec.Template.Spec.Affinity = ss.Spec.Template.Spec.Affinity.DeepCopy()
+		deferred = append(deferred, fmt.Sprintf("affinity of pool %s", pool.Name))
+	}
+	return deferred
+}
+
 // poolSSMatchesSpec checks if the statefulset for the pool matches what is expected and described from the Tenant
 func poolSSMatchesSpec(tenant *miniov2.Tenant, pool *miniov2.Pool, ss *appsv1.StatefulSet, opVersion string) (bool, error) {
 	// Verify Resources
@@ -71,6 +103,20 @@ func poolSSMatchesSpec(tenant *miniov2.Tenant, pool *miniov2.Pool, ss *appsv1.St
 		klog.V(4).Infof("affinity update for pool %s", pool.Name)
 		poolMatchesSS = false
 	}
+	// Verify probes
+	liveness, readiness, startup := statefulsets.PoolProbes(tenant, pool)
+	container := ss.Spec.Template.Spec.Containers[0]
+	if !equality.Semantic.DeepDerivative(liveness, container.LivenessProbe) ||
+		!equality.Semantic.DeepDerivative(readiness, container.ReadinessProbe) ||
+		!equality.Semantic.DeepDerivative(startup, container.StartupProbe) {
+		klog.V(4).Infof("probes update for pool %s", pool.Name)
+		poolMatchesSS = false
+	}
+	// Verify the registry rewrite rules apply to the image
+	if miniov2.RewriteImage(container.Image) != container.Image {
+		klog.V(4).Infof("image registry update for pool %s", pool.Name)
+		poolMatchesSS = false
+	}
 	// Verify all sidecars
 	if tenant.Spec.SideCars != nil {
 		if len(ss.Spec.Template.Spec.Containers) != len(tenant.Spec.SideCars.Containers)+1 {
@@ -78,8 +124,10 @@ func poolSSMatchesSpec(tenant *miniov2.Tenant, pool *miniov2.Pool, ss *appsv1.St
 			poolMatchesSS = false
 		}
 		// compare each container spec to the sidecars (shifted by one as container 0 is MinIO)
-		for i := 1; i < len(ss.Spec.Template.Spec.Containers); i++ {
-			if !equality.Semantic.DeepDerivative(ss.Spec.Template.Spec.Containers[i], tenant.Spec.SideCars.Containers[i-1]) {
+		for i := 1; i < len(ss.Spec.Template.Spec.Containers) && i <= len(tenant.Spec.SideCars.Containers); i++ {
+			sidecar := tenant.Spec.SideCars.Containers[i-1]
+			sidecar.Image = miniov2.RewriteImage(sidecar.Image)
+			if !equality.Semantic.DeepDerivative(ss.Spec.Template.Spec.Containers[i], sidecar) {
 				// container doesn't match
 				poolMatchesSS = false
 				break
@@ -106,6 +154,10 @@ func poolSSMatchesSpec(tenant *miniov2.Tenant, pool *miniov2.Pool, ss *appsv1.St
 	if miniov2.IsEnvUpdated(current, new) {
 		poolMatchesSS = false
 	}
+	// Check if the domains of Bucket DNS changed with the external hostnames of the MinIO service
+	if tenant.S3BucketDNS() && new["MINIO_DOMAIN"] != tenant.MinIODomain() {
+		poolMatchesSS = false
+	}
 	// Check if endpoints protocol changed because of TLS configuration and pods need to be restarted
 	if new["MINIO_ENDPOINTS"] != "" {
 		if tenant.TLS() && !strings.HasPrefix(new["MINIO_ENDPOINTS"], "https") {
@@ -25,8 +25,26 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// minioTestProbe returns a default probe of a MinIO container of a tenant with TLS
+func minioTestProbe(path string, failureThreshold int32) *corev1.Probe {
+	return &corev1.Probe{
+		Handler: corev1.Handler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path:   path,
+				Port:   intstr.FromInt(miniov2.MinIOPort),
+				Scheme: corev1.URISchemeHTTPS,
+			},
+		},
+		TimeoutSeconds:   miniov2.MinIOProbeTimeoutSeconds,
+		PeriodSeconds:    miniov2.MinIOProbePeriodSeconds,
+		SuccessThreshold: 1,
+		FailureThreshold: failureThreshold,
+	}
+}
+
 func Test_poolSSMatchesSpec(t *testing.T) {
 	type args struct {
 		tenant          *miniov2.Tenant
@@ -69,7 +87,10 @@ func Test_poolSSMatchesSpec(t *testing.T) {
 							Spec: corev1.PodSpec{
 								Containers: []corev1.Container{
 									{
-										Name: "minio",
+										Name:           "minio",
+										LivenessProbe:  minioTestProbe(miniov2.MinIOLivenessPath, miniov2.MinIOLivenessFailureThreshold),
+										ReadinessProbe: minioTestProbe(miniov2.MinIOReadinessPath, miniov2.MinIOReadinessFailureThreshold),
+										StartupProbe:   minioTestProbe(miniov2.MinIOLivenessPath, miniov2.MinIOStartupFailureThreshold),
 									},
 								},
 							},
@@ -654,6 +675,54 @@ func Test_poolSSMatchesSpec(t *testing.T) {
 			want:    false,
 			wantErr: false,
 		},
+		{
+			name: "Pool Readiness Probe Changed",
+			args: args{
+				tenant: &miniov2.Tenant{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "tenant-a",
+					},
+					Spec: miniov2.TenantSpec{},
+				},
+				pool: &miniov2.Pool{
+					Name: "pool-0",
+					Readiness: &corev1.Probe{
+						PeriodSeconds:    5,
+						FailureThreshold: 2,
+					},
+				},
+				ss: &appsv1.StatefulSet{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "tenant-a-pool-0",
+						Labels: map[string]string{
+							miniov2.PoolLabel:     "pool-0",
+							miniov2.TenantLabel:   "tenant-a",
+							miniov2.OperatorLabel: "0.1",
+						},
+						Annotations: map[string]string{
+							miniov2.Revision: "0",
+						},
+					},
+					Spec: appsv1.StatefulSetSpec{
+						Template: corev1.PodTemplateSpec{
+							Spec: corev1.PodSpec{
+								Containers: []corev1.Container{
+									{
+										Name:           "minio",
+										LivenessProbe:  minioTestProbe(miniov2.MinIOLivenessPath, miniov2.MinIOLivenessFailureThreshold),
+										ReadinessProbe: minioTestProbe(miniov2.MinIOReadinessPath, miniov2.MinIOReadinessFailureThreshold),
+										StartupProbe:   minioTestProbe(miniov2.MinIOLivenessPath, miniov2.MinIOStartupFailureThreshold),
+									},
+								},
+							},
+						},
+					},
+				},
+				operatorVersion: "0.1",
+			},
+			want:    false,
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -668,3 +737,78 @@ func Test_poolSSMatchesSpec(t *testing.T) {
 		})
 	}
 }
+
+func Test_keepPoolRollout(t *testing.T) {
+	partition := int32(2)
+	ss := &appsv1.StatefulSet{
+		Spec: appsv1.StatefulSetSpec{
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: &partition},
+			},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Image: "minio/minio:old"}}},
+			},
+		},
+	}
+	nss := &appsv1.StatefulSet{
+		Spec: appsv1.StatefulSetSpec{
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Image: "minio/minio:new"}}},
+			},
+		},
+	}
+	keepPoolRollout(nss, ss)
+	if image := nss.Spec.Template.Spec.Containers[0].Image; image != "minio/minio:old" {
+		t.Errorf("keepPoolRollout() image = %s, want minio/minio:old", image)
+	}
+	if rollingUpdate := nss.Spec.UpdateStrategy.RollingUpdate; rollingUpdate == nil || *rollingUpdate.Partition != 2 {
+		t.Errorf("keepPoolRollout() rollingUpdate = %v, want partition 2", rollingUpdate)
+	}
+}
+
+func Test_deferPoolChanges(t *testing.T) {
+	ss := &appsv1.StatefulSet{
+		Spec: appsv1.StatefulSetSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{
+					Image: "minio/minio:old",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+					},
+				}}},
+			},
+		},
+	}
+	nss := ss.DeepCopy()
+	nss.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{{Name: "MINIO_BROWSER", Value: "off"}}
+	if deferred := deferPoolChanges(nss, ss, &miniov2.Pool{Name: "pool-0"}); len(deferred) != 0 {
+		t.Errorf("deferPoolChanges() = %v, want no change deferred", deferred)
+	}
+
+	nss.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceMemory] = resource.MustParse("2Gi")
+	nss.Spec.Template.Spec.Affinity = &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{}}
+	deferred := deferPoolChanges(nss, ss, &miniov2.Pool{Name: "pool-0"})
+	if len(deferred) != 2 || deferred[0] != "resources of pool pool-0" || deferred[1] != "affinity of pool pool-0" {
+		t.Errorf("deferPoolChanges() = %v, want the resources and the affinity of pool-0 deferred", deferred)
+	}
+	if memory := nss.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceMemory]; memory.String() != "1Gi" {
+		t.Errorf("deferPoolChanges() memory request = %s, want 1Gi", memory.String())
+	}
+	if nss.Spec.Template.Spec.Affinity != nil {
+		t.Errorf("deferPoolChanges() affinity = %v, want nil", nss.Spec.Template.Spec.Affinity)
+	}
+	if len(nss.Spec.Template.Spec.Containers[0].Env) != 1 {
+		t.Errorf("deferPoolChanges() changed the environment of the pool")
+	}
+
+	nss.Spec.Template.Spec.Containers[0].Image = "registry.example.com/minio/minio:old"
+	deferred = deferPoolChanges(nss, ss, &miniov2.Pool{Name: "pool-0"})
+	if len(deferred) != 1 || deferred[0] != "image registry of pool pool-0" {
+		t.Errorf("deferPoolChanges() = %v, want the image registry of pool-0 deferred", deferred)
+	}
+	if image := nss.Spec.Template.Spec.Containers[0].Image; image != "minio/minio:old" {
+		t.Errorf("deferPoolChanges() image = %s, want minio/minio:old", image)
+	}
+}
@@ -0,0 +1,179 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package cluster
+
+import (
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/minio/madmin-go"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+)
+
+// driveEndpoint returns the `<host>:<port>` of the server of a drive endpoint and the pod running it, both are
+// empty for the drives of a single server tenant, their endpoint is their path
+func driveEndpoint(endpoint string) (server, pod string) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return "", ""
+	}
+	return u.Host, strings.Split(u.Hostname(), ".")[0]
+}
+
+// podPool returns the index of the pool of a MinIO pod of the tenant, -1 when the pod is not in a pool
+func podPool(tenant *miniov2.Tenant, pod string) int {
+	for pi, pool := range tenant.Status.Pools {
+		if pi >= len(tenant.Spec.Pools) || !strings.HasPrefix(pod, pool.SSName+"-") {
+			continue
+		}
+		if _, err := strconv.Atoi(strings.TrimPrefix(pod, pool.SSName+"-")); err == nil {
+			return pi
+		}
+	}
+	return -1
+}
+
+// drivePVC returns the name of the PersistentVolumeClaim of the pod mounted at the path of a drive, an empty name
+// when no volume of the pool is mounted there
+func drivePVC(tenant *miniov2.Tenant, pool *miniov2.Pool, pod, drivePath string) string {
+	name := miniov2.MinIOVolumeName
+	if pool.VolumeClaimTemplate != nil {
+		name = pool.VolumeClaimTemplate.Name
+	}
+	for i := 0; i < int(pool.VolumesPerServer); i++ {
+		mountPath := tenant.Spec.Mountpath
+		if pool.VolumesPerServer > 1 {
+			mountPath += strconv.Itoa(i)
+		}
+		if path.Join("/", mountPath, "/", tenant.Spec.Subpath) == path.Clean(drivePath) {
+			return name + strconv.Itoa(i) + "-" + pod
+		}
+	}
+	return ""
+}
+
+// serversHealth groups the drives reported by MinIO by server pod, along with their pool, their PVC and the
+// progress of their healing
+func serversHealth(tenant *miniov2.Tenant, disks []madmin.Disk) []miniov2.ServerHealth {
+	var servers []miniov2.ServerHealth
+	index := map[string]int{}
+	for _, disk := range disks {
+		endpoint, pod := driveEndpoint(disk.Endpoint)
+		if endpoint == "" && len(tenant.Spec.Pools) == 1 && len(tenant.Status.Pools) == 1 && tenant.Spec.Pools[0].Servers == 1 {
+			pod = tenant.Status.Pools[0].SSName + "-0"
+		}
+		i, ok := index[endpoint]
+		if !ok {
+			server := miniov2.ServerHealth{Endpoint: endpoint, Pod: pod}
+			if pi := podPool(tenant, server.Pod); pi >= 0 {
+				server.Pool = tenant.Spec.Pools[pi].Name
+			}
+			i = len(servers)
+			index[endpoint] = i
+			servers = append(servers, server)
+		}
+		server := &servers[i]
+
+		drivePath := disk.DrivePath
+		if drivePath == "" {
+			if u, err := url.Parse(disk.Endpoint); err == nil {
+				drivePath = u.Path
+			}
+		}
+		drive := miniov2.DriveHealth{
+			Path:       drivePath,
+			State:      disk.State,
+			Set:        int32(disk.SetIndex),
+			UsedSpace:  int64(disk.UsedSpace),
+			TotalSpace: int64(disk.TotalSpace),
+		}
+		if pi := podPool(tenant, server.Pod); pi >= 0 {
+			drive.PVC = drivePVC(tenant, &tenant.Spec.Pools[pi], server.Pod, drivePath)
+		}
+		if disk.Healing && disk.HealInfo != nil {
+			healing := &miniov2.DriveHealing{
+				ObjectsHealed: int64(disk.HealInfo.ObjectsHealed),
+				ObjectsFailed: int64(disk.HealInfo.ObjectsFailed),
+				BytesHealed:   int64(disk.HealInfo.BytesDone),
+				BucketsHealed: int32(len(disk.HealInfo.HealedBuckets)),
+				BucketsQueued: int32(len(disk.HealInfo.QueuedBuckets)),
+			}
+			if !disk.HealInfo.Started.IsZero() {
+				started := metav1.NewTime(disk.HealInfo.Started)
+				healing.Started = &started
+			}
+			if !disk.HealInfo.LastUpdate.IsZero() {
+				lastUpdate := metav1.NewTime(disk.HealInfo.LastUpdate)
+				healing.LastUpdate = &lastUpdate
+			}
+			drive.Healing = healing
+		}
+		if disk.State == madmin.DriveStateOk {
+			server.DrivesOnline++
+		} else {
+			server.DrivesOffline++
+		}
+		server.Drives = append(server.Drives, drive)
+	}
+
+	sort.Slice(servers, func(i, j int) bool {
+		return servers[i].Endpoint < servers[j].Endpoint
+	})
+	for _, server := range servers {
+		drives := server.Drives
+		sort.Slice(drives, func(i, j int) bool {
+			return drives[i].Path < drives[j].Path
+		})
+	}
+	return servers
+}
+
+// withoutUsage returns a copy of the servers without the usage counters of their drives, the used and total space
+// and the progress of the healing, which change on every check of the health of the tenant
+func withoutUsage(servers []miniov2.ServerHealth) []miniov2.ServerHealth {
+	stripped := make([]miniov2.ServerHealth, len(servers))
+	for i := range servers {
+		servers[i].DeepCopyInto(&stripped[i])
+		for j := range stripped[i].Drives {
+			drive := &stripped[i].Drives[j]
+			drive.UsedSpace = 0
+			drive.TotalSpace = 0
+			if drive.Healing != nil {
+				drive.Healing = &miniov2.DriveHealing{Started: drive.Healing.Started}
+			}
+		}
+	}
+	return stripped
+}
+
+// healthChanged returns whether the health of the tenant changed beyond the usage counters of the drives, the
+// status is only updated then, or once the usage counters are stale, to avoid an update on every check
+func healthChanged(previous, current *miniov2.TenantStatus) bool {
+	return previous.DrivesOnline != current.DrivesOnline ||
+		previous.DrivesOffline != current.DrivesOffline ||
+		previous.DrivesHealing != current.DrivesHealing ||
+		previous.WriteQuorum != current.WriteQuorum ||
+		previous.HealthStatus != current.HealthStatus ||
+		!equality.Semantic.DeepEqual(withoutUsage(previous.Servers), withoutUsage(current.Servers))
+}
@@ -0,0 +1,174 @@
+// This file is part of MinIO Operator
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/minio/madmin-go"
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_serversHealth(t *testing.T) {
+	tenant := &miniov2.Tenant{
+		Spec: miniov2.TenantSpec{
+			Mountpath: miniov2.MinIOVolumeMountPath,
+			Pools:     []miniov2.Pool{{Name: "pool-0", Servers: 2, VolumesPerServer: 2}},
+		},
+		Status: miniov2.TenantStatus{Pools: []miniov2.PoolStatus{{SSName: "tenant-pool-0"}}},
+	}
+	host0 := "https://tenant-pool-0-0.tenant-hl.ns.svc.cluster.local:9000"
+	host1 := "https://tenant-pool-0-1.tenant-hl.ns.svc.cluster.local:9000"
+	started := time.Date(2021, 6, 17, 0, 0, 0, 0, time.UTC)
+	disks := []madmin.Disk{
+		{Endpoint: host1 + "/export1", DrivePath: "/export1", State: madmin.DriveStateOk, SetIndex: 0, UsedSpace: 10, TotalSpace: 100},
+		{Endpoint: host1 + "/export0", State: madmin.DriveStateOffline, SetIndex: 0},
+		{Endpoint: host0 + "/export0", DrivePath: "/export0", State: madmin.DriveStateOk, SetIndex: 0},
+		{Endpoint: host0 + "/export1", DrivePath: "/export1", State: madmin.DriveStateOk, SetIndex: 0, Healing: true,
+			HealInfo: &madmin.HealingDisk{Started: started, ObjectsHealed: 3, BytesDone: 42, QueuedBuckets: []string{"b"}, HealedBuckets: []string{"a"}}},
+	}
+
+	servers := serversHealth(tenant, disks)
+	if len(servers) != 2 {
+		t.Fatalf("serversHealth() returned %d servers, want 2", len(servers))
+	}
+	server := servers[0]
+	if server.Endpoint != "tenant-pool-0-0.tenant-hl.ns.svc.cluster.local:9000" || server.Pod != "tenant-pool-0-0" || server.Pool != "pool-0" {
+		t.Errorf("serversHealth() server = %s pod %s pool %s, want tenant-pool-0-0 of pool-0", server.Endpoint, server.Pod, server.Pool)
+	}
+	healing := server.Drives[1].Healing
+	if healing == nil || healing.ObjectsHealed != 3 || healing.BytesHealed != 42 || healing.BucketsHealed != 1 ||
+		healing.BucketsQueued != 1 || !healing.Started.Time.Equal(started) || healing.LastUpdate != nil {
+		t.Errorf("serversHealth() healing = %+v, want the progress of /export1 of tenant-pool-0-0", healing)
+	}
+	if server.Drives[0].Healing != nil {
+		t.Errorf("serversHealth() reported /export0 of tenant-pool-0-0 healing")
+	}
+
+	server = servers[1]
+	if server.Pod != "tenant-pool-0-1" || server.DrivesOnline != 1 || server.DrivesOffline != 1 {
+		t.Errorf("serversHealth() server %s has %d drives online and %d offline, want 1 and 1", server.Pod, server.DrivesOnline, server.DrivesOffline)
+	}
+	drive := server.Drives[0]
+	if drive.Path != "/export0" || drive.State != madmin.DriveStateOffline || drive.PVC != "export0-tenant-pool-0-1" {
+		t.Errorf("serversHealth() drive = %s %s %s, want the offline /export0 on export0-tenant-pool-0-1", drive.Path, drive.State, drive.PVC)
+	}
+	drive = server.Drives[1]
+	if drive.PVC != "export1-tenant-pool-0-1" || drive.UsedSpace != 10 || drive.TotalSpace != 100 {
+		t.Errorf("serversHealth() drive = %s %d/%d, want export1-tenant-pool-0-1 with 10/100 bytes used", drive.PVC, drive.UsedSpace, drive.TotalSpace)
+	}
+}
+
+func Test_serversHealthSingleServer(t *testing.T) {
+	tenant := &miniov2.Tenant{
+		Spec: miniov2.TenantSpec{
+			Mountpath: miniov2.MinIOVolumeMountPath,
+			Subpath:   "data",
+			Pools:     []miniov2.Pool{{Name: "pool-0", Servers: 1, VolumesPerServer: 1}},
+		},
+		Status: miniov2.TenantStatus{Pools: []miniov2.PoolStatus{{SSName: "tenant-pool-0"}}},
+	}
+	servers := serversHealth(tenant, []madmin.Disk{{Endpoint: "/export/data", State: madmin.DriveStateOk, SetIndex: -1}})
+	if len(servers) != 1 || servers[0].Pod != "tenant-pool-0-0" || servers[0].Pool != "pool-0" {
+		t.Fatalf("serversHealth() = %+v, want the drives of tenant-pool-0-0", servers)
+	}
+	if drive := servers[0].Drives[0]; drive.Path != "/export/data" || drive.PVC != "export0-tenant-pool-0-0" || drive.Set != -1 {
+		t.Errorf("serversHealth() drive = %+v, want /export/data on export0-tenant-pool-0-0", drive)
+	}
+}
+
+func Test_healthChanged(t *testing.T) {
+	started := metav1.NewTime(time.Date(2021, 6, 17, 0, 0, 0, 0, time.UTC))
+	status := func(update func(status *miniov2.TenantStatus)) *miniov2.TenantStatus {
+		status := &miniov2.TenantStatus{
+			DrivesOnline: 2,
+			WriteQuorum:  2,
+			HealthStatus: miniov2.HealthStatusGreen,
+			Servers: []miniov2.ServerHealth{{
+				Endpoint:     "tenant-pool-0-0.tenant-hl.ns.svc.cluster.local:9000",
+				DrivesOnline: 2,
+				Drives: []miniov2.DriveHealth{
+					{Path: "/export0", State: madmin.DriveStateOk, UsedSpace: 10, TotalSpace: 100},
+					{Path: "/export1", State: madmin.DriveStateOk, UsedSpace: 10, TotalSpace: 100,
+						Healing: &miniov2.DriveHealing{Started: &started, ObjectsHealed: 1}},
+				},
+			}},
+		}
+		if update != nil {
+			update(status)
+		}
+		return status
+	}
+	tests := []struct {
+		name   string
+		update func(status *miniov2.TenantStatus)
+		want   bool
+	}{
+		{
+			name: "unchanged",
+		},
+		{
+			name: "used space",
+			update: func(status *miniov2.TenantStatus) {
+				status.Servers[0].Drives[0].UsedSpace = 20
+			},
+		},
+		{
+			name: "healing progress",
+			update: func(status *miniov2.TenantStatus) {
+				lastUpdate := metav1.Now()
+				status.Servers[0].Drives[1].Healing.ObjectsHealed = 100
+				status.Servers[0].Drives[1].Healing.LastUpdate = &lastUpdate
+			},
+		},
+		{
+			name: "drive offline",
+			update: func(status *miniov2.TenantStatus) {
+				status.Servers[0].Drives[0].State = madmin.DriveStateOffline
+			},
+			want: true,
+		},
+		{
+			name: "healing done",
+			update: func(status *miniov2.TenantStatus) {
+				status.Servers[0].Drives[1].Healing = nil
+			},
+			want: true,
+		},
+		{
+			name: "health status",
+			update: func(status *miniov2.TenantStatus) {
+				status.HealthStatus = miniov2.HealthStatusYellow
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := status(nil)
+			current := status(tt.update)
+			if got := healthChanged(previous, current); got != tt.want {
+				t.Errorf("healthChanged() = %v, want %v", got, tt.want)
+			}
+			if current.Servers[0].Drives[0].TotalSpace != 100 {
+				t.Error("healthChanged() modified the status")
+			}
+		})
+	}
+}
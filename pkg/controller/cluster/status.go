@@ -21,6 +21,7 @@ import (
 
 	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 )
@@ -32,7 +33,8 @@ func (c *Controller) updateTenantStatus(ctx context.Context, tenant *miniov2.Ten
 func (c *Controller) updateTenantStatusWithRetry(ctx context.Context, tenant *miniov2.Tenant, currentState string, availableReplicas int32, retry bool) (*miniov2.Tenant, error) {
 	// If we are updating the tenant with the same status as before we are going to skip it as to avoid a resource number
 	// change and have the operator loop re-processing the tenant endlessly
-	if tenant.Status.CurrentState == currentState && tenant.Status.AvailableReplicas == availableReplicas {
+	if tenant.Status.CurrentState == currentState && tenant.Status.AvailableReplicas == availableReplicas &&
+		tenant.Status.ObservedGeneration == tenant.Generation {
 		return tenant, nil
 	}
 	// NEVER modify objects from the store. It's a read-only, local cache.
@@ -41,6 +43,8 @@ func (c *Controller) updateTenantStatusWithRetry(ctx context.Context, tenant *mi
 	tenantCopy := tenant.DeepCopy()
 	tenantCopy.Status.AvailableReplicas = availableReplicas
 	tenantCopy.Status.CurrentState = currentState
+	tenantCopy.Status.ObservedGeneration = tenant.Generation
+	setTenantConditions(tenantCopy, currentState)
 	// If the CustomResourceSubresources feature gate is not enabled,
 	// we must use Update instead of UpdateStatus to update the Status block of the Tenant resource.
 	// UpdateStatus will not allow changes to the Spec of the resource,
@@ -163,3 +167,172 @@ func (c *Controller) updateCertificatesWithRetry(ctx context.Context, tenant *mi
 	}
 	return t, nil
 }
+
+func (c *Controller) updateIssuedCertificateStatus(ctx context.Context, tenant *miniov2.Tenant, issued *miniov2.IssuedCertificate, roll bool) (*miniov2.Tenant, error) {
+	return c.updateStatus(ctx, tenant, func(status *miniov2.TenantStatus) {
+		found := false
+		for i := range status.Certificates.Issued {
+			if status.Certificates.Issued[i].SecretName == issued.SecretName {
+				status.Certificates.Issued[i] = *issued
+				found = true
+			}
+		}
+		if !found {
+			status.Certificates.Issued = append(status.Certificates.Issued, *issued)
+		}
+		if roll {
+			// update the revision of the tenant to force a rolling restart with the renewed certificate
+			status.Revision++
+		}
+	})
+}
+
+// stateCondition describes how a legacy `currentState` message translates into typed conditions
+type stateCondition struct {
+	// reason used on the Ready, Progressing and Degraded conditions
+	reason string
+	// condition of the component being worked on, if any
+	component string
+	// the state is an intermediate step of the reconciliation
+	progressing bool
+}
+
+// stateConditions maps every standard status message to its typed conditions, any message
+// not listed here is an error reported by syncHandler and marks the tenant as Degraded
+var stateConditions = map[string]stateCondition{
+	StatusProvisioningCIService:                {reason: "ProvisioningService", progressing: true},
+	StatusProvisioningHLService:                {reason: "ProvisioningService", progressing: true},
+	StatusProvisioningStatefulSet:              {reason: "ProvisioningStatefulSet", progressing: true},
+	StatusProvisioningConsoleDeployment:        {reason: "ProvisioningConsole", component: miniov2.TenantConditionConsoleReady, progressing: true},
+	StatusProvisioningKESStatefulSet:           {reason: "ProvisioningKES", component: miniov2.TenantConditionKESReady, progressing: true},
+	StatusProvisioningLogPGStatefulSet:         {reason: "ProvisioningLogSearch", component: miniov2.TenantConditionLogSearchReady, progressing: true},
+	StatusProvisioningLogSearchAPIDeployment:   {reason: "ProvisioningLogSearch", component: miniov2.TenantConditionLogSearchReady, progressing: true},
+	StatusProvisioningPrometheusStatefulSet:    {reason: "ProvisioningPrometheus", component: miniov2.TenantConditionPrometheusReady, progressing: true},
+	StatusProvisioningPrometheusServiceMonitor: {reason: "ProvisioningPrometheus", component: miniov2.TenantConditionPrometheusReady, progressing: true},
+	StatusWaitingForReadyState:                 {reason: "WaitingForPods", progressing: true},
+	StatusWaitingForLogSearchReadyState:        {reason: "WaitingForLogSearchPods", component: miniov2.TenantConditionLogSearchReady, progressing: true},
+	StatusWaitingMinIOCert:                     {reason: "WaitingForMinIOCertificate", component: miniov2.TenantConditionCertificatesReady, progressing: true},
+	StatusWaitingMinIOClientCert:               {reason: "WaitingForMinIOClientCertificate", component: miniov2.TenantConditionCertificatesReady, progressing: true},
+	StatusWaitingKESCert:                       {reason: "WaitingForKESCertificate", component: miniov2.TenantConditionCertificatesReady, progressing: true},
+	StatusWaitingConsoleCert:                   {reason: "WaitingForConsoleCertificate", component: miniov2.TenantConditionCertificatesReady, progressing: true},
+	StatusUpdatingMinIOVersion:                 {reason: "UpdatingMinIOVersion", component: miniov2.TenantConditionUpgradeInProgress, progressing: true},
+	StatusUpdatingConsole:                      {reason: "UpdatingConsole", component: miniov2.TenantConditionConsoleReady, progressing: true},
+	StatusUpdatingKES:                          {reason: "UpdatingKES", component: miniov2.TenantConditionKESReady, progressing: true},
+	StatusUpdatingLogPGStatefulSet:             {reason: "UpdatingLogSearch", component: miniov2.TenantConditionLogSearchReady, progressing: true},
+	StatusUpdatingLogSearchAPIServer:           {reason: "UpdatingLogSearch", component: miniov2.TenantConditionLogSearchReady, progressing: true},
+	StatusUpdatingResourceRequirements:         {reason: "UpdatingResourceRequirements", progressing: true},
+	StatusUpdatingAffinity:                     {reason: "UpdatingAffinity", progressing: true},
+	StatusNotOwned:                             {reason: "StatefulSetNotOwned"},
+	StatusFailedBucketDNSConflict:              {reason: "BucketDNSConflict"},
+	StatusInconsistentMinIOVersions:            {reason: "InconsistentMinIOVersions"},
+	StatusRotatingCredentials:                  {reason: "RotatingCredentials", progressing: true},
+	StatusRollingMinIOUpgrade:                  {reason: "RollingMinIOUpgrade", component: miniov2.TenantConditionUpgradeInProgress, progressing: true},
+	StatusBakingMinIOUpgrade:                   {reason: "BakingMinIOUpgrade", component: miniov2.TenantConditionUpgradeInProgress, progressing: true},
+	StatusRollingBackMinIOUpgrade:              {reason: "RollingBackMinIOUpgrade", component: miniov2.TenantConditionUpgradeInProgress, progressing: true},
+}
+
+// setTenantConditions updates the typed conditions of the tenant to reflect the legacy `currentState`
+// message, the tenant passed must be a copy as it's modified in place.
+func setTenantConditions(tenant *miniov2.Tenant, currentState string) {
+	setCondition := func(conditionType string, status metav1.ConditionStatus, reason string) {
+		meta.SetStatusCondition(&tenant.Status.Conditions, metav1.Condition{
+			Type:               conditionType,
+			Status:             status,
+			ObservedGeneration: tenant.Generation,
+			Reason:             reason,
+			Message:            currentState,
+		})
+	}
+
+	if currentState == StatusInitialized {
+		setCondition(miniov2.TenantConditionReady, metav1.ConditionTrue, "Initialized")
+		setCondition(miniov2.TenantConditionProgressing, metav1.ConditionFalse, "Initialized")
+		setCondition(miniov2.TenantConditionDegraded, metav1.ConditionFalse, "Initialized")
+		setCondition(miniov2.TenantConditionCertificatesReady, metav1.ConditionTrue, "Initialized")
+		setCondition(miniov2.TenantConditionUpgradeInProgress, metav1.ConditionFalse, "Initialized")
+		components := map[string]bool{
+			miniov2.TenantConditionKESReady:        tenant.HasKESEnabled(),
+			miniov2.TenantConditionConsoleReady:    tenant.HasConsoleEnabled(),
+			miniov2.TenantConditionLogSearchReady:  tenant.HasLogEnabled(),
+			miniov2.TenantConditionPrometheusReady: tenant.HasPrometheusEnabled(),
+		}
+		for conditionType, enabled := range components {
+			if enabled {
+				setCondition(conditionType, metav1.ConditionTrue, "Initialized")
+			} else {
+				// Components not requested by the tenant don't report any condition
+				meta.RemoveStatusCondition(&tenant.Status.Conditions, conditionType)
+			}
+		}
+		return
+	}
+
+	sc, ok := stateConditions[currentState]
+	if !ok {
+		sc = stateCondition{reason: "ReconcileError"}
+	}
+	setCondition(miniov2.TenantConditionReady, metav1.ConditionFalse, sc.reason)
+	if sc.progressing {
+		setCondition(miniov2.TenantConditionProgressing, metav1.ConditionTrue, sc.reason)
+		setCondition(miniov2.TenantConditionDegraded, metav1.ConditionFalse, sc.reason)
+	} else {
+		setCondition(miniov2.TenantConditionProgressing, metav1.ConditionFalse, sc.reason)
+		setCondition(miniov2.TenantConditionDegraded, metav1.ConditionTrue, sc.reason)
+	}
+	switch sc.component {
+	case "":
+	case miniov2.TenantConditionUpgradeInProgress:
+		setCondition(sc.component, metav1.ConditionTrue, sc.reason)
+	default:
+		setCondition(sc.component, metav1.ConditionFalse, sc.reason)
+	}
+}
+
+// updateStatus applies the update to the status of a copy of the tenant and saves it, the update is applied again
+// to the latest tenant on a conflict
+func (c *Controller) updateStatus(ctx context.Context, tenant *miniov2.Tenant, update func(status *miniov2.TenantStatus)) (*miniov2.Tenant, error) {
+	return c.updateStatusWithRetry(ctx, tenant, update, true)
+}
+
+func (c *Controller) updateStatusWithRetry(ctx context.Context, tenant *miniov2.Tenant, update func(status *miniov2.TenantStatus), retry bool) (*miniov2.Tenant, error) {
+	// NEVER modify objects from the store. It's a read-only, local cache.
+	// You can use DeepCopy() to make a deep copy of original object and modify this copy
+	// Or create a copy manually for better performance
+	tenantCopy := tenant.DeepCopy()
+	update(&tenantCopy.Status)
+	opts := metav1.UpdateOptions{}
+	t, err := c.minioClientSet.MinioV2().Tenants(tenant.Namespace).UpdateStatus(ctx, tenantCopy, opts)
+	if err != nil {
+		// if rejected due to conflict, get the latest tenant and retry once
+		if k8serrors.IsConflict(err) && retry {
+			klog.Info("Hit conflict issue, getting latest version of tenant")
+			tenant, err = c.minioClientSet.MinioV2().Tenants(tenant.Namespace).Get(ctx, tenant.Name, metav1.GetOptions{})
+			if err != nil {
+				return tenant, err
+			}
+			return c.updateStatusWithRetry(ctx, tenant, update, false)
+		}
+		return t, err
+	}
+	t.EnsureDefaults()
+	return t, nil
+}
+
+func (c *Controller) updateExternalURLs(ctx context.Context, tenant *miniov2.Tenant, urls *miniov2.ExternalURLs) (*miniov2.Tenant, error) {
+	return c.updateStatus(ctx, tenant, func(status *miniov2.TenantStatus) {
+		status.ExternalURLs = urls
+	})
+}
+
+func (c *Controller) updateLegacyServiceNameStatus(ctx context.Context, tenant *miniov2.Tenant) (*miniov2.Tenant, error) {
+	return c.updateStatus(ctx, tenant, func(status *miniov2.TenantStatus) {
+		status.LegacyServiceName = true
+	})
+}
+
+func (c *Controller) updatePendingChangesStatus(ctx context.Context, tenant *miniov2.Tenant, pending []string, next *metav1.Time) (*miniov2.Tenant, error) {
+	return c.updateStatus(ctx, tenant, func(status *miniov2.TenantStatus) {
+		status.PendingChanges = pending
+		status.NextMaintenanceWindow = next
+	})
+}
@@ -0,0 +1,158 @@
+// This file is part of MinIO Operator
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cluster
+
+import (
+	"testing"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_setTenantConditions(t *testing.T) {
+	tests := []struct {
+		name         string
+		states       []string
+		kes          bool
+		want         map[string]metav1.ConditionStatus
+		wantAbsent   []string
+		wantReason   string
+		wantDegraded bool
+	}{
+		{
+			name:   "Provisioning",
+			states: []string{StatusProvisioningStatefulSet},
+			want: map[string]metav1.ConditionStatus{
+				miniov2.TenantConditionReady:       metav1.ConditionFalse,
+				miniov2.TenantConditionProgressing: metav1.ConditionTrue,
+				miniov2.TenantConditionDegraded:    metav1.ConditionFalse,
+			},
+			wantReason: "ProvisioningStatefulSet",
+		},
+		{
+			name:   "Waiting for KES certificate",
+			states: []string{StatusWaitingKESCert},
+			kes:    true,
+			want: map[string]metav1.ConditionStatus{
+				miniov2.TenantConditionReady:             metav1.ConditionFalse,
+				miniov2.TenantConditionProgressing:       metav1.ConditionTrue,
+				miniov2.TenantConditionCertificatesReady: metav1.ConditionFalse,
+			},
+			wantReason: "WaitingForKESCertificate",
+		},
+		{
+			name:   "Error message",
+			states: []string{StatusProvisioningStatefulSet, "pool servers can't be modified"},
+			want: map[string]metav1.ConditionStatus{
+				miniov2.TenantConditionReady:       metav1.ConditionFalse,
+				miniov2.TenantConditionProgressing: metav1.ConditionFalse,
+				miniov2.TenantConditionDegraded:    metav1.ConditionTrue,
+			},
+			wantReason:   "ReconcileError",
+			wantDegraded: true,
+		},
+		{
+			name:   "Rolling upgrade",
+			states: []string{StatusRollingMinIOUpgrade},
+			want: map[string]metav1.ConditionStatus{
+				miniov2.TenantConditionReady:             metav1.ConditionFalse,
+				miniov2.TenantConditionProgressing:       metav1.ConditionTrue,
+				miniov2.TenantConditionDegraded:          metav1.ConditionFalse,
+				miniov2.TenantConditionUpgradeInProgress: metav1.ConditionTrue,
+			},
+			wantReason: "RollingMinIOUpgrade",
+		},
+		{
+			name:   "Baking upgrade",
+			states: []string{StatusBakingMinIOUpgrade},
+			want: map[string]metav1.ConditionStatus{
+				miniov2.TenantConditionReady:             metav1.ConditionFalse,
+				miniov2.TenantConditionProgressing:       metav1.ConditionTrue,
+				miniov2.TenantConditionDegraded:          metav1.ConditionFalse,
+				miniov2.TenantConditionUpgradeInProgress: metav1.ConditionTrue,
+			},
+			wantReason: "BakingMinIOUpgrade",
+		},
+		{
+			name:   "Rolling back upgrade",
+			states: []string{StatusRollingBackMinIOUpgrade},
+			want: map[string]metav1.ConditionStatus{
+				miniov2.TenantConditionReady:             metav1.ConditionFalse,
+				miniov2.TenantConditionProgressing:       metav1.ConditionTrue,
+				miniov2.TenantConditionDegraded:          metav1.ConditionFalse,
+				miniov2.TenantConditionUpgradeInProgress: metav1.ConditionTrue,
+			},
+			wantReason: "RollingBackMinIOUpgrade",
+		},
+		{
+			name:   "Upgrade then initialized",
+			states: []string{StatusUpdatingMinIOVersion, StatusProvisioningKESStatefulSet, StatusInitialized},
+			kes:    true,
+			want: map[string]metav1.ConditionStatus{
+				miniov2.TenantConditionReady:             metav1.ConditionTrue,
+				miniov2.TenantConditionProgressing:       metav1.ConditionFalse,
+				miniov2.TenantConditionDegraded:          metav1.ConditionFalse,
+				miniov2.TenantConditionCertificatesReady: metav1.ConditionTrue,
+				miniov2.TenantConditionKESReady:          metav1.ConditionTrue,
+				miniov2.TenantConditionUpgradeInProgress: metav1.ConditionFalse,
+			},
+			wantAbsent: []string{miniov2.TenantConditionConsoleReady, miniov2.TenantConditionLogSearchReady, miniov2.TenantConditionPrometheusReady},
+			wantReason: "Initialized",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tenant := &miniov2.Tenant{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "tenant-a",
+					Generation: 3,
+				},
+			}
+			if tt.kes {
+				tenant.Spec.KES = &miniov2.KESConfig{}
+			}
+			for _, state := range tt.states {
+				setTenantConditions(tenant, state)
+			}
+			for conditionType, status := range tt.want {
+				condition := meta.FindStatusCondition(tenant.Status.Conditions, conditionType)
+				if condition == nil {
+					t.Fatalf("condition %s missing", conditionType)
+				}
+				if condition.Status != status {
+					t.Errorf("condition %s = %s, want %s", conditionType, condition.Status, status)
+				}
+				if condition.ObservedGeneration != tenant.Generation {
+					t.Errorf("condition %s observedGeneration = %d, want %d", conditionType, condition.ObservedGeneration, tenant.Generation)
+				}
+			}
+			for _, conditionType := range tt.wantAbsent {
+				if meta.FindStatusCondition(tenant.Status.Conditions, conditionType) != nil {
+					t.Errorf("condition %s should not be reported", conditionType)
+				}
+			}
+			ready := meta.FindStatusCondition(tenant.Status.Conditions, miniov2.TenantConditionReady)
+			if ready.Reason != tt.wantReason {
+				t.Errorf("Ready reason = %s, want %s", ready.Reason, tt.wantReason)
+			}
+			if meta.IsStatusConditionTrue(tenant.Status.Conditions, miniov2.TenantConditionDegraded) != tt.wantDegraded {
+				t.Errorf("Degraded = %v, want %v", !tt.wantDegraded, tt.wantDegraded)
+			}
+		})
+	}
+}
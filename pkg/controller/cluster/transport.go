@@ -0,0 +1,155 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package cluster
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// tenantTransport is the transport the Operator connects to a tenant with
+type tenantTransport struct {
+	// hash of the CAs and settings the transport was built with
+	hash      string
+	transport *http.Transport
+}
+
+// TenantTransports caches the transports the Operator connects to the tenants with, shared by the Tenant, Bucket
+// and IAM controllers. The CAs a transport trusts are read from the secrets of its tenant every time it's
+// requested, so a tenant is never dialed before its certificates are trusted.
+type TenantTransports struct {
+	secretLister corelisters.SecretLister
+	synced       cache.InformerSynced
+
+	mu sync.Mutex
+	// transports of the tenants, keyed by namespace/name
+	transports map[string]*tenantTransport
+}
+
+// NewTenantTransports returns the transports of the tenants trusting the certificates of the given secrets
+func NewTenantTransports(secretInformer coreinformers.SecretInformer) *TenantTransports {
+	return &TenantTransports{
+		secretLister: secretInformer.Lister(),
+		synced:       secretInformer.Informer().HasSynced,
+		transports:   map[string]*tenantTransport{},
+	}
+}
+
+// HasSynced returns true once the secrets the trusted CAs are read from have synced
+func (tt *TenantTransports) HasSynced() bool {
+	return tt.synced()
+}
+
+// tlsConfig returns the TLS configuration the Operator connects to the tenant with. The certificates of the
+// tenant are verified against the given CAs and the hostname of the MinIO service, unless
+// `spec.insecureSkipVerify` is set.
+func tlsConfig(tenant *miniov2.Tenant, cas [][]byte) *tls.Config {
+	rootCAs := x509.NewCertPool()
+	for _, ca := range cas {
+		if len(ca) > 0 && !rootCAs.AppendCertsFromPEM(ca) {
+			klog.Warningf("Ignoring an invalid CA certificate of Tenant '%s/%s'", tenant.Namespace, tenant.Name)
+		}
+	}
+	host, _, err := net.SplitHostPort(tenant.MinIOServerHostAddress())
+	if err != nil {
+		host = tenant.MinIOFQDNServiceName()
+	}
+	return &tls.Config{
+		// Can't use SSLv3 because of POODLE and BEAST
+		// Can't use TLSv1.0 because of POODLE and BEAST using CBC cipher
+		// Can't use TLSv1.1 because of RC4 cipher usage
+		MinVersion: tls.VersionTLS12,
+		RootCAs:    rootCAs,
+		// pods are reached by their own address too, the certificates are always valid for the service
+		ServerName:         host,
+		InsecureSkipVerify: tenant.Spec.InsecureSkipVerify,
+	}
+}
+
+// transportHash returns the hash of everything the transport of the tenant is built from
+func transportHash(tenant *miniov2.Tenant, cas [][]byte) string {
+	h := sha256.New()
+	for _, ca := range cas {
+		h.Write(ca)
+		h.Write([]byte{0})
+	}
+	if tenant.Spec.InsecureSkipVerify {
+		h.Write([]byte("insecure"))
+	}
+	h.Write([]byte(tenant.MinIOServerHostAddress()))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Transport returns the transport the Operator connects to the tenant with. It is shared by all the clients of
+// the tenant and rebuilt when its trusted CAs change.
+func (tt *TenantTransports) Transport(tenant *miniov2.Tenant) *http.Transport {
+	cas := tt.trustedCAs(tenant)
+	hash := transportHash(tenant, cas)
+	key := tenant.Namespace + "/" + tenant.Name
+
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	if cached, found := tt.transports[key]; found {
+		if cached.hash == hash {
+			return cached.transport
+		}
+		cached.transport.CloseIdleConnections()
+	}
+
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 15 * time.Second,
+		}).DialContext,
+		MaxIdleConnsPerHost:   16,
+		IdleConnTimeout:       time.Minute,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ExpectContinueTimeout: 5 * time.Second,
+		TLSClientConfig:       tlsConfig(tenant, cas),
+		// Go net/http automatically unzip if content-type is
+		// gzip disable this feature, as we are always interested
+		// in raw stream.
+		DisableCompression: true,
+	}
+	tt.transports[key] = &tenantTransport{hash: hash, transport: transport}
+	return transport
+}
+
+// Forget drops the transport of a deleted tenant
+func (tt *TenantTransports) Forget(namespace, name string) {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	key := namespace + "/" + name
+	if cached, ok := tt.transports[key]; ok {
+		cached.transport.CloseIdleConnections()
+	}
+	delete(tt.transports, key)
+}
@@ -0,0 +1,91 @@
+// This file is part of MinIO Operator
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cluster
+
+import (
+	"encoding/pem"
+	"net/http/httptest"
+	"testing"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestTenantTransports_Transport(t *testing.T) {
+	srv := httptest.NewTLSServer(nil)
+	defer srv.Close()
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+
+	tenant := &miniov2.Tenant{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant", Namespace: "ns"},
+		Spec: miniov2.TenantSpec{
+			Pools: []miniov2.Pool{{Name: "pool-0", Servers: 4, VolumesPerServer: 1}},
+		},
+	}
+	secretInformer := kubeinformers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0).Core().V1().Secrets()
+	secrets := secretInformer.Informer().GetIndexer()
+	tt := NewTenantTransports(secretInformer)
+
+	// the CA of the AutoCert certificates is trusted as soon as its secret is listed
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: tenant.MinIOTLSSecretName(), Namespace: "ns"},
+		Data:       map[string][]byte{miniov2.CACertificateKey: caPEM},
+	}
+	if err := secrets.Add(secret); err != nil {
+		t.Fatal(err)
+	}
+	transport := tt.Transport(tenant)
+	if tt.Transport(tenant) != transport {
+		t.Error("Transport() isn't cached")
+	}
+	if want := "tenant-minio.ns.svc." + miniov2.GetClusterDomain(); transport.TLSClientConfig.ServerName != want {
+		t.Errorf("Transport() server name = %s, want %s", transport.TLSClientConfig.ServerName, want)
+	}
+	if transport.TLSClientConfig.InsecureSkipVerify {
+		t.Error("Transport() skips the verification of the certificates")
+	}
+	if got := len(transport.TLSClientConfig.RootCAs.Subjects()); got != 1 {
+		t.Errorf("Transport() trusts %d CAs, want 1", got)
+	}
+
+	// new CAs rebuild the transport
+	if err := secrets.Delete(secret); err != nil {
+		t.Fatal(err)
+	}
+	rebuilt := tt.Transport(tenant)
+	if rebuilt == transport {
+		t.Error("Transport() wasn't rebuilt when its CA was removed")
+	}
+	if got := len(rebuilt.TLSClientConfig.RootCAs.Subjects()); got != 0 {
+		t.Errorf("Transport() trusts %d CAs, want none", got)
+	}
+
+	// the opt-out rebuilds the transport too
+	tenant.Spec.InsecureSkipVerify = true
+	if !tt.Transport(tenant).TLSClientConfig.InsecureSkipVerify {
+		t.Error("Transport() verifies the certificates of a tenant opting out")
+	}
+
+	insecure := tt.Transport(tenant)
+	tt.Forget(tenant.Namespace, tenant.Name)
+	if tt.Transport(tenant) == insecure {
+		t.Error("Transport() returned the transport of a forgotten tenant")
+	}
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package cluster
+
+import (
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+)
+
+// externalCertificates returns the certificates stored in a secret provided by the user, the keys depend on
+// the type of the secret
+func externalCertificates(secret *corev1.Secret, ca bool) [][]byte {
+	var certs [][]byte
+	switch secret.Type {
+	case "kubernetes.io/tls":
+		certs = append(certs, secret.Data[miniov2.TLSCertificateKey])
+	case "cert-manager.io/v1alpha2":
+		if !ca {
+			certs = append(certs, secret.Data[miniov2.TLSCertificateKey])
+		}
+	default:
+		certs = append(certs, secret.Data[miniov2.CSRCertificateKey])
+	}
+	// cert-manager stores the CA of the issuer along with the certificate
+	if caCert, ok := secret.Data[miniov2.CACertificateKey]; ok {
+		certs = append(certs, caCert)
+	}
+	return certs
+}
+
+// trustedCAs collects the certificates the Operator trusts when connecting to the tenant: the Kubernetes cluster
+// CA signing the CSRs, the CA of the AutoCert certificates, the CAs in `spec.externalCaCertSecret` and the
+// certificates in `spec.externalCertSecret`, which may be self-signed
+func (tt *TenantTransports) trustedCAs(tenant *miniov2.Tenant) [][]byte {
+	var cas [][]byte
+	if clusterCA := miniov2.GetPodCAFromFile(); len(clusterCA) > 0 {
+		cas = append(cas, clusterCA)
+	}
+
+	getSecret := func(name string) *corev1.Secret {
+		secret, err := tt.secretLister.Secrets(tenant.Namespace).Get(name)
+		if err != nil {
+			if !k8serrors.IsNotFound(err) {
+				klog.Warningf("Unable to read secret/%s trusted by Tenant '%s/%s': %v", name, tenant.Namespace, tenant.Name, err)
+			}
+			return nil
+		}
+		return secret
+	}
+
+	if tenant.AutoCert() {
+		// set by cert-manager CA issuers and the Operator internal CA
+		if secret := getSecret(tenant.MinIOTLSSecretName()); secret != nil && len(secret.Data[miniov2.CACertificateKey]) > 0 {
+			cas = append(cas, secret.Data[miniov2.CACertificateKey])
+		}
+	}
+	for _, ref := range tenant.Spec.ExternalCaCertSecret {
+		if secret := getSecret(ref.Name); secret != nil {
+			cas = append(cas, externalCertificates(secret, true)...)
+		}
+	}
+	if tenant.ExternalCert() {
+		for _, ref := range tenant.Spec.ExternalCertSecret {
+			if secret := getSecret(ref.Name); secret != nil {
+				cas = append(cas, externalCertificates(secret, false)...)
+			}
+		}
+	}
+	return cas
+}
@@ -0,0 +1,463 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package cluster
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	"k8s.io/klog/v2"
+)
+
+// ociRefNameAnnotation is the annotation of the manifests of an OCI image layout holding the tag of the image
+const ociRefNameAnnotation = "org.opencontainers.image.ref.name"
+
+// releaseTag returns the tag of an image, empty if the image is referenced by a digest
+func releaseTag(ref name.Reference) string {
+	if tag, ok := ref.(name.Tag); ok {
+		return tag.TagStr()
+	}
+	return ""
+}
+
+// stagedBinary returns the path of the pre-staged binary of the release of the image, empty if it isn't staged
+func stagedBinary(dir string, ref name.Reference) (string, error) {
+	tag := releaseTag(ref)
+	if dir == "" || tag == "" {
+		return "", nil
+	}
+	binary := filepath.Join(dir, "minio."+tag)
+	if _, err := os.Stat(binary); err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return binary, nil
+}
+
+// copyFile copies a file, it returns the SHA-256 checksum of the file
+func copyFile(src, dst string) ([]byte, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	if _, err = io.Copy(io.MultiWriter(out, h), in); err != nil {
+		out.Close()
+		return nil, err
+	}
+	return h.Sum(nil), out.Close()
+}
+
+// stageArtifacts copies the pre-staged binary of the release of the image to the update directory, along with its
+// signature and its checksum, which is computed when it isn't staged. It returns the release tag, empty when the
+// binary isn't staged.
+func stageArtifacts(dir string, ref name.Reference, basePath string) (string, error) {
+	binary, err := stagedBinary(dir, ref)
+	if err != nil || binary == "" {
+		return "", err
+	}
+	tag := releaseTag(ref)
+	dest := basePath + "minio." + tag
+	klog.Infof("Using the pre-staged MinIO binary %s", binary)
+	sum, err := copyFile(binary, dest)
+	if err != nil {
+		return "", err
+	}
+	if _, err = copyFile(binary+".minisig", dest+".minisig"); err != nil {
+		return "", fmt.Errorf("the signature of the pre-staged binary is required: %v", err)
+	}
+	if _, err = copyFile(binary+".sha256sum", dest+".sha256sum"); err != nil {
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		checksum := fmt.Sprintf("%x  minio.%s\n", sum, tag)
+		if err = ioutil.WriteFile(dest+".sha256sum", []byte(checksum), 0o644); err != nil {
+			return "", err
+		}
+	}
+	return tag, nil
+}
+
+// layoutTarball returns the path of the OCI image layout tarball of the image, named after its tag, empty if there
+// is none
+func layoutTarball(dir string, ref name.Reference) (string, error) {
+	tag := releaseTag(ref)
+	if dir == "" || tag == "" {
+		return "", nil
+	}
+	tarball := filepath.Join(dir, tag+".tar")
+	if _, err := os.Stat(tarball); err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return tarball, nil
+}
+
+// untar extracts the directories and regular files of a tarball to a directory
+func untar(tarball, dir string) error {
+	f, err := os.Open(tarball)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	tr := tar.NewReader(f)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(dir, filepath.Clean("/"+header.Name))
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err = os.MkdirAll(path, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+			if err != nil {
+				return err
+			}
+			if _, err = io.Copy(out, tr); err != nil {
+				out.Close()
+				return err
+			}
+			if err = out.Close(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// isIndexMediaType returns whether a manifest is an index of the images of several platforms
+func isIndexMediaType(mediaType types.MediaType) bool {
+	return mediaType == types.OCIImageIndex || mediaType == types.DockerManifestList
+}
+
+// indexImage returns the image of the index tagged with the tag, or its only image. An index of the images of
+// several platforms resolves to the image of the platform of the Operator.
+func indexImage(index v1.ImageIndex, tag string) (v1.Image, error) {
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	var desc *v1.Descriptor
+	for i := range manifest.Manifests {
+		m := &manifest.Manifests[i]
+		if len(manifest.Manifests) == 1 || tag != "" && m.Annotations[ociRefNameAnnotation] == tag {
+			desc = m
+			break
+		}
+		if tag == "" && m.Platform != nil && m.Platform.OS == runtime.GOOS && m.Platform.Architecture == runtime.GOARCH {
+			desc = m
+			break
+		}
+	}
+	if desc == nil {
+		return nil, fmt.Errorf("no image tagged %s found in the OCI image layout", tag)
+	}
+	if isIndexMediaType(desc.MediaType) {
+		platforms, err := index.ImageIndex(desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+		return indexImage(platforms, "")
+	}
+	return index.Image(desc.Digest)
+}
+
+// layoutImage returns the image of an OCI image layout tarball, extracted to a directory
+func layoutImage(tarball, dir string, ref name.Reference) (v1.Image, error) {
+	if err := untar(tarball, dir); err != nil {
+		return nil, err
+	}
+	path, err := layout.FromPath(dir)
+	if err != nil {
+		return nil, err
+	}
+	index, err := path.ImageIndex()
+	if err != nil {
+		return nil, err
+	}
+	return indexImage(index, releaseTag(ref))
+}
+
+// mirrorReference returns the reference of the image in the mirror of its registry, the reference itself when its
+// registry isn't mirrored
+func mirrorReference(ref name.Reference, mirrors map[string]string) (name.Reference, error) {
+	for registry, mirror := range mirrors {
+		r, err := name.NewRegistry(registry)
+		if err != nil || r.RegistryStr() != ref.Context().RegistryStr() {
+			continue
+		}
+		separator := ":"
+		if _, ok := ref.(name.Digest); ok {
+			separator = "@"
+		}
+		return name.ParseReference(fmt.Sprintf("%s/%s%s%s", mirror, ref.Context().RepositoryStr(), separator, ref.Identifier()))
+	}
+	return ref, nil
+}
+
+// registryTransport returns the transport to the registries, trusting the CA certificates of the file besides the
+// system ones
+func registryTransport(caFile string) (http.RoundTripper, error) {
+	if caFile == "" {
+		return http.DefaultTransport, nil
+	}
+	pem, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no CA certificate found in %s", caFile)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	return transport, nil
+}
+
+// registryReference returns the reference of the image in its registry or its mirror, along with the options to
+// reach the registry
+func (c *Controller) registryReference(ctx context.Context, tenant *miniov2.Tenant, ref name.Reference, sources miniov2.UpdateSources) (name.Reference, []remote.Option, error) {
+	mirrored, err := mirrorReference(ref, sources.RegistryMirrors)
+	if err != nil {
+		return nil, nil, err
+	}
+	transport, err := registryTransport(sources.RegistryCAFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	return mirrored, []remote.Option{
+		remote.WithAuthFromKeychain(c.imageKeychain(ctx, mirrored, tenant)),
+		remote.WithTransport(transport),
+		remote.WithContext(ctx),
+	}, nil
+}
+
+// artifactsImage returns the image holding the MinIO binary of the release of the image of the tenant, from an OCI
+// image layout tarball, or from its registry or the mirror of the registry. The cleanup function removes the files
+// of the image once the artifacts are extracted.
+func (c *Controller) artifactsImage(ctx context.Context, tenant *miniov2.Tenant, ref name.Reference, sources miniov2.UpdateSources) (v1.Image, func(), error) {
+	tarball, err := layoutTarball(sources.OCILayoutPath, ref)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tarball != "" {
+		klog.Infof("Using the OCI image layout tarball %s", tarball)
+		dir, err := ioutil.TempDir("", "oci-layout")
+		if err != nil {
+			return nil, nil, err
+		}
+		cleanup := func() {
+			_ = os.RemoveAll(dir)
+		}
+		img, err := layoutImage(tarball, dir, ref)
+		if err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		return img, cleanup, nil
+	}
+
+	mirrored, opts, err := c.registryReference(ctx, tenant, ref, sources)
+	if err != nil {
+		return nil, nil, err
+	}
+	if mirrored.Name() != ref.Name() {
+		klog.Infof("Pulling %s from %s", ref.Name(), mirrored.Name())
+	}
+	img, err := remote.Image(mirrored, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return img, func() {}, nil
+}
+
+// collectArtifacts copies the pre-staged MinIO binary of the release of the image to the directory, or extracts it
+// from an OCI image layout tarball or from the image pulled from its registry or a mirror, along with its checksum
+// and its signature. It returns the release tag of the binary, read from the binary itself unless it is pre-staged.
+func (c *Controller) collectArtifacts(ctx context.Context, tenant *miniov2.Tenant, ref name.Reference, basePath string) (string, error) {
+	sources := miniov2.GetUpdateSources()
+	tag, err := stageArtifacts(sources.ArtifactsPath, ref, basePath)
+	if err != nil || tag != "" {
+		return tag, err
+	}
+	img, cleanup, err := c.artifactsImage(ctx, tenant, ref, sources)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+	return c.extractArtifacts(img, ref, basePath)
+}
+
+// publishArtifacts verifies the signature of the MinIO binary of the release collected in the staging directory,
+// then moves the binary, its checksum and its signature to the update directory served to MinIO. The binary is
+// moved last so MinIO never downloads it before its checksum and its signature.
+func publishArtifacts(pubKey, stagingPath, basePath, tag string) error {
+	binary := "minio." + tag
+	if err := verifyMinisign(pubKey, stagingPath+binary, stagingPath+binary+".minisig"); err != nil {
+		return err
+	}
+	for _, file := range []string{binary + ".sha256sum", binary + ".minisig", binary} {
+		if err := os.Rename(stagingPath+file, basePath+file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractArtifacts extracts the MinIO binary, its checksum and its signature from the image to the update
+// directory, named after the release tag of the binary
+func (c *Controller) extractArtifacts(img v1.Image, ref name.Reference, basePath string) (tag string, err error) {
+	ls, err := img.Layers()
+	if err != nil {
+		return tag, err
+	}
+
+	// Find the file with largest size among all layers.
+	// This is the tar file with all minio relevant files.
+	start := 0
+	if len(ls) >= 2 { // skip the base layer
+		start = 1
+	}
+	maxSizeHash, _ := ls[start].Digest()
+	maxSize, _ := ls[start].Size()
+	for i := range ls {
+		if i < start {
+			continue
+		}
+		s, _ := ls[i].Size()
+		if s > maxSize {
+			maxSize, _ = ls[i].Size()
+			maxSizeHash, _ = ls[i].Digest()
+		}
+	}
+
+	f, err := os.OpenFile(basePath+"image.tar", os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0777)
+	if err != nil {
+		return tag, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	// Tarball writes a file called image.tar
+	// This file in turn has each container layer present inside in the form `<layer-hash>.tar.gz`
+	if err = tarball.Write(ref, img, f); err != nil {
+		return tag, err
+	}
+
+	// Extract the <layer-hash>.tar.gz file that has minio contents from `image.tar`
+	fileNameToExtract := strings.Split(maxSizeHash.String(), ":")[1] + ".tar.gz"
+	if err = miniov2.ExtractTar([]string{fileNameToExtract}, basePath, "image.tar"); err != nil {
+		return tag, err
+	}
+
+	// Extract the minio update related files (minio, minio.sha256sum and minio.minisig) from `<layer-hash>.tar.gz`
+	if err = miniov2.ExtractTar([]string{"usr/bin/minio", "usr/bin/minio.sha256sum", "usr/bin/minio.minisig"}, basePath, fileNameToExtract); err != nil {
+		return tag, err
+	}
+
+	srcBinary := "minio"
+	srcShaSum := "minio.sha256sum"
+	srcSig := "minio.minisig"
+
+	tag, err = c.fetchTag(basePath + srcBinary)
+	if err != nil {
+		return tag, err
+	}
+
+	if _, err = miniov2.ReleaseTagToReleaseTime(tag); err != nil {
+		return tag, err
+	}
+
+	destBinary := "minio." + tag
+	destShaSum := "minio." + tag + ".sha256sum"
+	destSig := "minio." + tag + ".minisig"
+	filesToRename := map[string]string{srcBinary: destBinary, srcShaSum: destShaSum, srcSig: destSig}
+
+	// rename all files to add tag specific values in the name.
+	// this is because minio updater looks for files in this name format.
+	for s, d := range filesToRename {
+		if err = os.Rename(basePath+s, basePath+d); err != nil {
+			return tag, err
+		}
+	}
+	return tag, nil
+}
+
+// checkArtifactsReachable verifies the MinIO binary of the release of the image of the tenant is pre-staged, in an
+// OCI image layout tarball, or that the image can be pulled from its registry or the mirror of the registry
+func (c *Controller) checkArtifactsReachable(ctx context.Context, tenant *miniov2.Tenant) error {
+	ref, err := name.ParseReference(miniov2.RewriteImage(tenant.Spec.Image))
+	if err != nil {
+		return err
+	}
+	sources := miniov2.GetUpdateSources()
+	if binary, err := stagedBinary(sources.ArtifactsPath, ref); err != nil || binary != "" {
+		return err
+	}
+	if tarball, err := layoutTarball(sources.OCILayoutPath, ref); err != nil || tarball != "" {
+		return err
+	}
+	mirrored, opts, err := c.registryReference(ctx, tenant, ref, sources)
+	if err != nil {
+		return err
+	}
+	if _, err = remote.Get(mirrored, opts...); err != nil {
+		return fmt.Errorf("unable to reach %s: %v", mirrored.Name(), err)
+	}
+	return nil
+}
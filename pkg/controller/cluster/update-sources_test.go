@@ -0,0 +1,219 @@
+// This file is part of MinIO Operator
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cluster
+
+import (
+	"archive/tar"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+)
+
+func Test_mirrorReference(t *testing.T) {
+	mirrors := map[string]string{
+		"docker.io": "registry.example.com:5000/docker",
+		"quay.io":   "registry.example.com:5000",
+	}
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{"minio/minio:RELEASE.2021-06-17T00-10-46Z", "registry.example.com:5000/docker/minio/minio:RELEASE.2021-06-17T00-10-46Z"},
+		{"quay.io/minio/minio:RELEASE.2021-06-17T00-10-46Z", "registry.example.com:5000/minio/minio:RELEASE.2021-06-17T00-10-46Z"},
+		{"gcr.io/minio/minio:RELEASE.2021-06-17T00-10-46Z", "gcr.io/minio/minio:RELEASE.2021-06-17T00-10-46Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			ref, err := name.ParseReference(tt.image)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := mirrorReference(ref, mirrors)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Name() != tt.want {
+				t.Errorf("mirrorReference() = %s, want %s", got.Name(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_stageArtifacts(t *testing.T) {
+	staged, dest := t.TempDir(), t.TempDir()+"/"
+	binary := filepath.Join(staged, "minio.RELEASE.2021-06-17T00-10-46Z")
+	if err := ioutil.WriteFile(binary, []byte("minio binary"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ref, _ := name.ParseReference("minio/minio:RELEASE.2021-06-07T21-40-51Z")
+	if tag, err := stageArtifacts(staged, ref, dest); err != nil || tag != "" {
+		t.Errorf("stageArtifacts() = %q, %v, want no binary staged", tag, err)
+	}
+
+	ref, _ = name.ParseReference("minio/minio:RELEASE.2021-06-17T00-10-46Z")
+	if _, err := stageArtifacts(staged, ref, dest); err == nil {
+		t.Errorf("stageArtifacts() staged a binary without signature")
+	}
+
+	if err := ioutil.WriteFile(binary+".minisig", []byte("signature"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tag, err := stageArtifacts(staged, ref, dest)
+	if err != nil || tag != "RELEASE.2021-06-17T00-10-46Z" {
+		t.Fatalf("stageArtifacts() = %q, %v, want RELEASE.2021-06-17T00-10-46Z", tag, err)
+	}
+	checksum, err := ioutil.ReadFile(dest + "minio.RELEASE.2021-06-17T00-10-46Z.sha256sum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte("minio binary"))
+	if want := fmt.Sprintf("%x  minio.RELEASE.2021-06-17T00-10-46Z\n", sum); string(checksum) != want {
+		t.Errorf("stageArtifacts() checksum = %q, want %q", checksum, want)
+	}
+}
+
+func Test_publishArtifacts(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyID := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	pubKey := base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), keyID...), public...))
+
+	tag := "RELEASE.2021-06-17T00-10-46Z"
+	files := []string{"minio." + tag, "minio." + tag + ".sha256sum", "minio." + tag + ".minisig"}
+	stage := func(signed []byte) (stagingPath string) {
+		stagingPath = t.TempDir() + "/"
+		for file, data := range map[string][]byte{
+			files[0]: []byte("minio binary"),
+			files[1]: []byte("checksum"),
+			files[2]: minisignTestSign(t, private, keyID, []byte("ED"), signed, "timestamp:1623888646"),
+		} {
+			if err := ioutil.WriteFile(stagingPath+file, data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return stagingPath
+	}
+
+	// nothing is served when the signature doesn't match
+	basePath := t.TempDir() + "/"
+	if err = publishArtifacts(pubKey, stage([]byte("another binary")), basePath, tag); err == nil {
+		t.Error("publishArtifacts() published a binary with an invalid signature")
+	}
+	if served, _ := ioutil.ReadDir(basePath); len(served) != 0 {
+		t.Errorf("publishArtifacts() served %d files of an invalid binary", len(served))
+	}
+
+	stagingPath := stage([]byte("minio binary"))
+	if err = publishArtifacts(pubKey, stagingPath, basePath, tag); err != nil {
+		t.Fatalf("publishArtifacts() error = %v", err)
+	}
+	for _, file := range files {
+		if _, err = os.Stat(basePath + file); err != nil {
+			t.Errorf("publishArtifacts() didn't serve %s: %v", file, err)
+		}
+		if _, err = os.Stat(stagingPath + file); !os.IsNotExist(err) {
+			t.Errorf("publishArtifacts() left %s in the staging directory", file)
+		}
+	}
+}
+
+// tarDir writes the files of a directory to a tarball
+func tarDir(t *testing.T, dir, tarball string) {
+	t.Helper()
+	f, err := os.Create(tarball)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	tw := tar.NewWriter(f)
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err = tw.WriteHeader(&tar.Header{Name: rel, Mode: 0o644, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+			return err
+		}
+		_, err = tw.Write(data)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_layoutImage(t *testing.T) {
+	dir := t.TempDir()
+	path, err := layout.Write(filepath.Join(dir, "layout"), empty.Index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := random.Image(256, 1)
+	img, _ := random.Image(256, 2)
+	if err = path.AppendImage(other, layout.WithAnnotations(map[string]string{ociRefNameAnnotation: "RELEASE.2021-06-07T21-40-51Z"})); err != nil {
+		t.Fatal(err)
+	}
+	if err = path.AppendImage(img, layout.WithAnnotations(map[string]string{ociRefNameAnnotation: "RELEASE.2021-06-17T00-10-46Z"})); err != nil {
+		t.Fatal(err)
+	}
+	sources := filepath.Join(dir, "sources")
+	if err = os.Mkdir(sources, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	tarDir(t, filepath.Join(dir, "layout"), filepath.Join(sources, "RELEASE.2021-06-17T00-10-46Z.tar"))
+
+	ref, _ := name.ParseReference("minio/minio:RELEASE.2021-06-17T00-10-46Z")
+	tarball, err := layoutTarball(sources, ref)
+	if err != nil || tarball == "" {
+		t.Fatalf("layoutTarball() = %q, %v, want the tarball of the release", tarball, err)
+	}
+	got, err := layoutImage(tarball, filepath.Join(dir, "extracted"), ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotDigest, _ := got.Digest()
+	wantDigest, _ := img.Digest()
+	if gotDigest != wantDigest {
+		t.Errorf("layoutImage() digest = %s, want %s", gotDigest, wantDigest)
+	}
+
+	ref, _ = name.ParseReference("minio/minio:RELEASE.2021-06-07T21-40-51Z")
+	if tarball, err = layoutTarball(sources, ref); err != nil || tarball != "" {
+		t.Errorf("layoutTarball() = %q, %v, want no tarball", tarball, err)
+	}
+}
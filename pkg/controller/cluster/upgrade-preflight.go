@@ -0,0 +1,151 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// errReleaseUnavailable is returned when the release of an image can't be read from its artifacts, the pre-flight
+// checks are then retried
+var errReleaseUnavailable = errors.New("unable to read the MinIO release")
+
+// imageReleaseTime returns the time of the MinIO release of an image tagged with a release tag like
+// `RELEASE.2021-06-17T00-10-46Z`, ok is false for the images referenced by digest or by another tag
+func imageReleaseTime(ref name.Reference) (releaseTime time.Time, ok bool) {
+	releaseTime, err := miniov2.ReleaseTagToReleaseTime(releaseTag(ref))
+	return releaseTime, err == nil
+}
+
+// releaseTime returns the time of the MinIO release of an image. The release of the images referenced by digest or
+// by a tag other than a release tag, like the tag of a mirror, is read from their MinIO binary. The releases of the
+// digests are kept, the image of a digest never changes.
+func (c *Controller) releaseTime(ctx context.Context, tenant *miniov2.Tenant, image string) (time.Time, error) {
+	ref, err := name.ParseReference(miniov2.RewriteImage(image))
+	if err != nil {
+		return time.Time{}, err
+	}
+	if releaseTime, ok := imageReleaseTime(ref); ok {
+		return releaseTime, nil
+	}
+	if releaseTime, ok := c.digestReleases.Load(ref.Name()); ok {
+		return releaseTime.(time.Time), nil
+	}
+
+	dir, err := ioutil.TempDir("", "release")
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer os.RemoveAll(dir)
+	tag, err := c.collectArtifacts(ctx, tenant, ref, dir+slashSeparator)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w of %s: %v", errReleaseUnavailable, image, err)
+	}
+	releaseTime, err := miniov2.ReleaseTagToReleaseTime(tag)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s isn't a MinIO release: %v", image, err)
+	}
+	if _, ok := ref.(name.Digest); ok {
+		c.digestReleases.Store(ref.Name(), releaseTime)
+	}
+	return releaseTime, nil
+}
+
+// checkUpgradeVersions verifies the image of the tenant is a MinIO release newer than the release the pods run
+func checkUpgradeVersions(from, to string, releaseTime func(image string) (time.Time, error)) error {
+	toTime, err := releaseTime(to)
+	if err != nil {
+		return err
+	}
+	fromTime, err := releaseTime(from)
+	if err != nil {
+		return err
+	}
+	if !toTime.After(fromTime) {
+		return fmt.Errorf("%s isn't newer than %s", to, from)
+	}
+	return nil
+}
+
+// checkUpgradeHealth verifies the cluster is healthy, without drives healing nor pools being decommissioned
+func checkUpgradeHealth(tenant *miniov2.Tenant, tr *http.Transport) error {
+	for i, pool := range tenant.Status.Pools {
+		if pool.State == miniov2.PoolDecommissioning {
+			return fmt.Errorf("pool %s is being decommissioned", tenant.Spec.Pools[i].Name)
+		}
+	}
+	health, err := getMinIOHealthStatusWithRetry(tenant, tr, RegularMode, 1)
+	if err != nil {
+		return fmt.Errorf("unable to get the cluster health: %v", err)
+	}
+	if health.StatusCode != http.StatusOK {
+		return fmt.Errorf("the cluster isn't healthy, status code %d", health.StatusCode)
+	}
+	if health.HealingDrives > 0 {
+		return fmt.Errorf("%d drives are healing", health.HealingDrives)
+	}
+	return nil
+}
+
+// upgradePreflight verifies an upgrade of the MinIO pods from an image to the image of the tenant can start: the
+// image of the tenant must be a newer MinIO release and reachable, the cluster healthy, without drives healing nor
+// pools being decommissioned. It returns whether the upgrade can start, the error is only set when the checks must
+// be retried, a tenant failing the version checks waits for `spec.image` to change unless the release of an image
+// couldn't be read from its artifacts.
+func (c *Controller) upgradePreflight(ctx context.Context, tenant *miniov2.Tenant, from string, totalReplicas int32) (*miniov2.Tenant, bool, error) {
+	fail := func(err error, retry bool) (*miniov2.Tenant, bool, error) {
+		msg := fmt.Sprintf("Upgrade pre-flight check failed: %v", err)
+		klog.Infof("Tenant '%s/%s': %s", tenant.Namespace, tenant.Name, msg)
+		if tenant.Status.CurrentState != msg {
+			c.recorder.Event(tenant, corev1.EventTypeWarning, "UpgradePreflightFailed", msg)
+			t, terr := c.updateTenantStatus(ctx, tenant, msg, totalReplicas)
+			if terr != nil {
+				return tenant, false, terr
+			}
+			tenant = t
+		}
+		if retry {
+			return tenant, false, err
+		}
+		return tenant, false, nil
+	}
+	releaseTime := func(image string) (time.Time, error) {
+		return c.releaseTime(ctx, tenant, image)
+	}
+	if err := checkUpgradeVersions(from, tenant.Spec.Image, releaseTime); err != nil {
+		return fail(err, errors.Is(err, errReleaseUnavailable))
+	}
+	if err := checkUpgradeHealth(tenant, c.transports.Transport(tenant)); err != nil {
+		return fail(err, true)
+	}
+	if err := c.checkArtifactsReachable(ctx, tenant); err != nil {
+		return fail(err, true)
+	}
+	return tenant, true, nil
+}
@@ -0,0 +1,154 @@
+// This file is part of MinIO Operator
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cluster
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_checkUpgradeVersions(t *testing.T) {
+	digest := "minio/minio@sha256:3d5c0b7b76ba1f1bd4ac6fa35e1e8d0bc42bc06df3b9dd6ebae0b62c5a3f1a8e"
+	releaseTime := func(image string) (time.Time, error) {
+		ref, err := name.ParseReference(image)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if releaseTime, ok := imageReleaseTime(ref); ok {
+			return releaseTime, nil
+		}
+		if image == digest {
+			return miniov2.ReleaseTagToReleaseTime("RELEASE.2021-06-17T00-10-46Z")
+		}
+		return time.Time{}, fmt.Errorf("%s isn't a MinIO release", image)
+	}
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		wantErr bool
+	}{
+		{"newer", "minio/minio:RELEASE.2021-06-07T21-40-51Z", "minio/minio:RELEASE.2021-06-17T00-10-46Z", false},
+		{"newer hotfix", "minio/minio:RELEASE.2021-06-17T00-10-46Z", "quay.io/minio/minio:RELEASE.2021-06-17T00-10-47Z.fips", false},
+		{"same", "minio/minio:RELEASE.2021-06-17T00-10-46Z", "minio/minio:RELEASE.2021-06-17T00-10-46Z", true},
+		{"older", "minio/minio:RELEASE.2021-06-17T00-10-46Z", "minio/minio:RELEASE.2021-06-07T21-40-51Z", true},
+		{"not a release", "minio/minio:RELEASE.2021-06-17T00-10-46Z", "minio/minio:latest", true},
+		{"current not a release", "minio/minio:edge", "minio/minio:RELEASE.2021-06-17T00-10-46Z", true},
+		{"newer digest", "minio/minio:RELEASE.2021-06-07T21-40-51Z", digest, false},
+		{"same digest", "minio/minio:RELEASE.2021-06-17T00-10-46Z", digest, true},
+		{"current digest", digest, "minio/minio:RELEASE.2021-06-17T00-10-47Z", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkUpgradeVersions(tt.from, tt.to, releaseTime); (err != nil) != tt.wantErr {
+				t.Errorf("checkUpgradeVersions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_releaseTime(t *testing.T) {
+	// an image with a MinIO binary printing its release, pushed to a registry under a tag of a mirror
+	var layer bytes.Buffer
+	tw := tar.NewWriter(&layer)
+	for file, content := range map[string]string{
+		"usr/bin/minio":           "#!/bin/sh\necho minio version RELEASE.2021-06-17T00-10-46Z\n",
+		"usr/bin/minio.sha256sum": "checksum",
+		"usr/bin/minio.minisig":   "signature",
+	} {
+		if err := tw.WriteHeader(&tar.Header{Name: file, Mode: 0o755, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	l, err := tarball.LayerFromReader(&layer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := mutate.AppendLayers(empty.Image, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(ioutil.Discard, "", 0))))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+	tagged, err := name.ParseReference(host + "/minio/minio:mirror")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = remote.Write(tagged, img); err != nil {
+		t.Fatal(err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Controller{}
+	tenant := &miniov2.Tenant{ObjectMeta: metav1.ObjectMeta{Name: "tenant", Namespace: "ns"}}
+	want, _ := miniov2.ReleaseTagToReleaseTime("RELEASE.2021-06-17T00-10-46Z")
+	images := []string{
+		fmt.Sprintf("%s/minio/minio@%s", host, digest),
+		host + "/minio/minio:mirror",
+		"minio/minio:RELEASE.2021-06-17T00-10-46Z",
+	}
+	for _, image := range images {
+		got, err := c.releaseTime(context.Background(), tenant, image)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("releaseTime(%s) = %v, %v, want %v", image, got, err, want)
+		}
+	}
+
+	// the release of the digest is kept, the release of the tag is read again
+	server.Close()
+	if got, err := c.releaseTime(context.Background(), tenant, images[0]); err != nil || !got.Equal(want) {
+		t.Errorf("releaseTime(%s) = %v, %v, want %v", images[0], got, err, want)
+	}
+	if _, err := c.releaseTime(context.Background(), tenant, images[1]); err == nil {
+		t.Errorf("releaseTime(%s) didn't read the release of the tag again", images[1])
+	}
+
+	from := "minio/minio:RELEASE.2021-06-07T21-40-51Z"
+	releaseTime := func(image string) (time.Time, error) {
+		return c.releaseTime(context.Background(), tenant, image)
+	}
+	if err := checkUpgradeVersions(from, images[0], releaseTime); err != nil {
+		t.Errorf("checkUpgradeVersions() error = %v for a newer release referenced by digest", err)
+	}
+}
@@ -0,0 +1,304 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// ErrRollingUpgradeInProgress is returned while the pods of a rolling upgrade restart
+var ErrRollingUpgradeInProgress = errors.New("Waiting for the rolling upgrade of the MinIO pods to progress")
+
+// poolRollout is the progress of the rollout of a new image to the pods of a pool
+type poolRollout int
+
+const (
+	// poolRolloutWaiting waits for the restarted pods to be ready
+	poolRolloutWaiting poolRollout = iota
+	// poolRolloutProgressed restarted another pod with the new image
+	poolRolloutProgressed
+	// poolRolloutUnhealthy found the cluster unhealthy once the restarted pods were ready
+	poolRolloutUnhealthy
+	// poolRolloutDone found every pod of the pool running the new image
+	poolRolloutDone
+)
+
+// startUpgrade records a new upgrade of the MinIO pods to the image of the tenant, ending the upgrade left in
+// progress if any
+func (c *Controller) startUpgrade(ctx context.Context, tenant *miniov2.Tenant, from string, strategy miniov2.UpgradeStrategyType) (*miniov2.Tenant, error) {
+	return c.updateStatus(ctx, tenant, func(status *miniov2.TenantStatus) {
+		now := metav1.Now()
+		if n := len(status.UpgradeHistory); n > 0 && status.UpgradeHistory[n-1].EndTime == nil {
+			status.UpgradeHistory[n-1].EndTime = &now
+			status.UpgradeHistory[n-1].Result = miniov2.UpgradeFailed
+			status.UpgradeHistory[n-1].Message = "Interrupted by another upgrade"
+		}
+		status.UpgradeHistory = append(status.UpgradeHistory, miniov2.UpgradeRecord{
+			From:         from,
+			To:           tenant.Spec.Image,
+			Strategy:     strategy,
+			StartTime:    now,
+			ProgressTime: &now,
+			Result:       miniov2.UpgradeInProgress,
+		})
+		if n := len(status.UpgradeHistory); n > miniov2.MaxUpgradeHistory {
+			status.UpgradeHistory = status.UpgradeHistory[n-miniov2.MaxUpgradeHistory:]
+		}
+	})
+}
+
+// updateCurrentUpgrade changes the upgrade in progress
+func (c *Controller) updateCurrentUpgrade(ctx context.Context, tenant *miniov2.Tenant, update func(upgrade *miniov2.UpgradeRecord)) (*miniov2.Tenant, error) {
+	return c.updateStatus(ctx, tenant, func(status *miniov2.TenantStatus) {
+		if n := len(status.UpgradeHistory); n > 0 && status.UpgradeHistory[n-1].EndTime == nil {
+			update(&status.UpgradeHistory[n-1])
+		}
+	})
+}
+
+// endUpgrade records the outcome of the upgrade in progress
+func (c *Controller) endUpgrade(ctx context.Context, tenant *miniov2.Tenant, result miniov2.UpgradeResult, message string) (*miniov2.Tenant, error) {
+	return c.updateCurrentUpgrade(ctx, tenant, func(upgrade *miniov2.UpgradeRecord) {
+		now := metav1.Now()
+		upgrade.EndTime = &now
+		upgrade.Result = result
+		upgrade.Message = message
+	})
+}
+
+// minioClusterHealthy returns whether the MinIO cluster keeps its write quorum, a variable so the tests can
+// stand in for MinIO
+var minioClusterHealthy = func(tenant *miniov2.Tenant, tr *http.Transport) bool {
+	health, err := getMinIOHealthStatusWithRetry(tenant, tr, RegularMode, 1)
+	if err != nil {
+		klog.V(2).Infof("Unable to get the health of Tenant '%s/%s': %v", tenant.Namespace, tenant.Name, err)
+		return false
+	}
+	return health.StatusCode == http.StatusOK
+}
+
+// rollPool restarts the pods of the pool with the image, the highest ordinal first. A pod is only restarted once
+// the pods restarted before are ready and the cluster is healthy. A rollback restarts every pod without waiting
+// for the cluster health.
+func (c *Controller) rollPool(ctx context.Context, tenant *miniov2.Tenant, ss *appsv1.StatefulSet, image string, rollback bool) (poolRollout, error) {
+	replicas := *ss.Spec.Replicas
+	var partition int32
+	if rollingUpdate := ss.Spec.UpdateStrategy.RollingUpdate; rollingUpdate != nil && rollingUpdate.Partition != nil {
+		partition = *rollingUpdate.Partition
+	}
+
+	if ss.Spec.Template.Spec.Containers[0].Image != image {
+		partition = replicas - 1
+		if rollback {
+			partition = 0
+		}
+		klog.Infof("Restarting the pods of statefulset %s/%s with ordinal %d and above with image %s", ss.Namespace, ss.Name, partition, image)
+		ssCopy := ss.DeepCopy()
+		ssCopy.Spec.Template.Spec.Containers[0].Image = image
+		ssCopy.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{
+			Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+			RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: &partition},
+		}
+		if _, err := c.kubeClientSet.AppsV1().StatefulSets(ss.Namespace).Update(ctx, ssCopy, metav1.UpdateOptions{}); err != nil {
+			return poolRolloutWaiting, err
+		}
+		return poolRolloutProgressed, nil
+	}
+
+	// the statefulset controller restarts the pods with an ordinal of at least the partition, one at a time
+	if ss.Status.ObservedGeneration < ss.Generation || ss.Status.UpdatedReplicas < replicas-partition || ss.Status.ReadyReplicas < replicas {
+		return poolRolloutWaiting, nil
+	}
+	if !rollback && !minioClusterHealthy(tenant, c.transports.Transport(tenant)) {
+		return poolRolloutUnhealthy, nil
+	}
+	if partition == 0 {
+		return poolRolloutDone, nil
+	}
+
+	partition--
+	klog.Infof("Restarting the pod of statefulset %s/%s with ordinal %d with image %s", ss.Namespace, ss.Name, partition, image)
+	ssCopy := ss.DeepCopy()
+	ssCopy.Spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateStatefulSetStrategy{Partition: &partition}
+	if _, err := c.kubeClientSet.AppsV1().StatefulSets(ss.Namespace).Update(ctx, ssCopy, metav1.UpdateOptions{}); err != nil {
+		return poolRolloutWaiting, err
+	}
+	return poolRolloutProgressed, nil
+}
+
+// rollbackUpgrade restores the image the pods ran before the upgrade in progress
+func (c *Controller) rollbackUpgrade(ctx context.Context, tenant *miniov2.Tenant, reason string) (*miniov2.Tenant, error) {
+	upgrade := tenant.CurrentUpgrade()
+	klog.Warningf("Rolling back the upgrade of Tenant '%s/%s' to %s: %s", tenant.Namespace, tenant.Name, upgrade.From, reason)
+	c.recorder.Event(tenant, corev1.EventTypeWarning, "UpgradeRollingBack",
+		fmt.Sprintf("Restoring MinIO %s: %s", upgrade.From, reason))
+	return c.updateCurrentUpgrade(ctx, tenant, func(upgrade *miniov2.UpgradeRecord) {
+		now := metav1.Now()
+		upgrade.Result = miniov2.UpgradeRollingBack
+		upgrade.Message = reason
+		upgrade.ProgressTime = &now
+		upgrade.BakeStartTime = nil
+	})
+}
+
+// checkRollingUpgrade drives the rolling upgrade of the pools to the image of the tenant. The pools are upgraded in
+// order, see rollPool. Once every pod runs the new image, the cluster health is watched for the bake time before
+// the upgrade succeeds. The previous image is restored when the cluster health turns red, or when a pod doesn't
+// become ready within the progress deadline.
+func (c *Controller) checkRollingUpgrade(ctx context.Context, tenant *miniov2.Tenant, images []string, totalReplicas int32) (*miniov2.Tenant, error) {
+	var err error
+	upgrade := tenant.CurrentUpgrade()
+	if upgrade == nil {
+		from := ""
+		for _, image := range images {
+			if image != miniov2.RewriteImage(tenant.Spec.Image) {
+				from = image
+				break
+			}
+		}
+		// a rolled back upgrade is only retried once the image changes
+		if from == "" || tenant.RolledBackUpgrade() != nil {
+			return tenant, nil
+		}
+		if !tenant.MinIOHealthCheck(c.transports.Transport(tenant)) {
+			return tenant, ErrMinIONotReady
+		}
+		var ready bool
+		if tenant, ready, err = c.upgradePreflight(ctx, tenant, from, totalReplicas); err != nil || !ready {
+			return tenant, err
+		}
+		klog.Infof("Upgrading the pods of Tenant '%s/%s' from %s to %s one at a time", tenant.Namespace, tenant.Name, from, tenant.Spec.Image)
+		c.recorder.Event(tenant, corev1.EventTypeNormal, "UpgradeStarted",
+			fmt.Sprintf("Upgrading the MinIO pods from %s to %s one at a time", from, tenant.Spec.Image))
+		if tenant, err = c.startUpgrade(ctx, tenant, from, miniov2.RollingUpgradeStrategy); err != nil {
+			return tenant, err
+		}
+		upgrade = tenant.CurrentUpgrade()
+	}
+
+	rollback := upgrade.Result == miniov2.UpgradeRollingBack
+	if !rollback && upgrade.To != tenant.Spec.Image {
+		// the image changed during the upgrade, carry on with the new one
+		klog.Infof("Upgrading the pods of Tenant '%s/%s' to %s instead of %s", tenant.Namespace, tenant.Name, tenant.Spec.Image, upgrade.To)
+		if tenant, err = c.updateCurrentUpgrade(ctx, tenant, func(upgrade *miniov2.UpgradeRecord) {
+			now := metav1.Now()
+			upgrade.To = tenant.Spec.Image
+			upgrade.ProgressTime = &now
+			upgrade.BakeStartTime = nil
+		}); err != nil {
+			return tenant, err
+		}
+		upgrade = tenant.CurrentUpgrade()
+	}
+	image := miniov2.RewriteImage(upgrade.To)
+	state := StatusRollingMinIOUpgrade
+	if rollback {
+		image = miniov2.RewriteImage(upgrade.From)
+		state = StatusRollingBackMinIOUpgrade
+	}
+
+	for pi := range tenant.Spec.Pools {
+		if tenant.PoolDecommissioned(pi) {
+			continue
+		}
+		ss, err := c.statefulSetLister.StatefulSets(tenant.Namespace).Get(tenant.Status.Pools[pi].SSName)
+		if err != nil {
+			return tenant, err
+		}
+		rollout, err := c.rollPool(ctx, tenant, ss, image, rollback)
+		if err != nil {
+			return tenant, err
+		}
+		switch rollout {
+		case poolRolloutDone:
+			continue
+		case poolRolloutUnhealthy:
+			if tenant, err = c.rollbackUpgrade(ctx, tenant, fmt.Sprintf("the cluster health turned red while upgrading pool %s", tenant.Spec.Pools[pi].Name)); err != nil {
+				return tenant, err
+			}
+			state = StatusRollingBackMinIOUpgrade
+		case poolRolloutProgressed:
+			if tenant, err = c.updateCurrentUpgrade(ctx, tenant, func(upgrade *miniov2.UpgradeRecord) {
+				now := metav1.Now()
+				upgrade.ProgressTime = &now
+			}); err != nil {
+				return tenant, err
+			}
+		case poolRolloutWaiting:
+			if !rollback && upgrade.ProgressTime != nil && time.Since(upgrade.ProgressTime.Time) > tenant.UpgradeProgressDeadline() {
+				if tenant, err = c.rollbackUpgrade(ctx, tenant, fmt.Sprintf("the pods of pool %s were not ready within %s", tenant.Spec.Pools[pi].Name, tenant.UpgradeProgressDeadline())); err != nil {
+					return tenant, err
+				}
+				state = StatusRollingBackMinIOUpgrade
+			}
+		}
+		if _, err = c.updateTenantStatus(ctx, tenant, state, totalReplicas); err != nil {
+			return tenant, err
+		}
+		return tenant, ErrRollingUpgradeInProgress
+	}
+
+	if rollback {
+		klog.Warningf("Upgrade of Tenant '%s/%s' to %s rolled back", tenant.Namespace, tenant.Name, upgrade.To)
+		c.recorder.Event(tenant, corev1.EventTypeWarning, "UpgradeRolledBack",
+			fmt.Sprintf("MinIO pods restored to %s, change the image to retry the upgrade to %s", upgrade.From, upgrade.To))
+		c.metrics.countUpgrade(tenant.Namespace, tenant.Name, upgradeRolledBack)
+		return c.endUpgrade(ctx, tenant, miniov2.UpgradeRolledBack, upgrade.Message)
+	}
+	if upgrade.BakeStartTime == nil {
+		klog.Infof("Pods of Tenant '%s/%s' run %s, watching the cluster health for %s", tenant.Namespace, tenant.Name, upgrade.To, tenant.UpgradeBakeTime())
+		if tenant, err = c.updateCurrentUpgrade(ctx, tenant, func(upgrade *miniov2.UpgradeRecord) {
+			now := metav1.Now()
+			upgrade.BakeStartTime = &now
+		}); err != nil {
+			return tenant, err
+		}
+		upgrade = tenant.CurrentUpgrade()
+	}
+	if !minioClusterHealthy(tenant, c.transports.Transport(tenant)) {
+		if tenant, err = c.rollbackUpgrade(ctx, tenant, "the cluster health turned red after the upgrade"); err != nil {
+			return tenant, err
+		}
+		if _, err = c.updateTenantStatus(ctx, tenant, StatusRollingBackMinIOUpgrade, totalReplicas); err != nil {
+			return tenant, err
+		}
+		return tenant, ErrRollingUpgradeInProgress
+	}
+	if time.Since(upgrade.BakeStartTime.Time) < tenant.UpgradeBakeTime() {
+		if _, err = c.updateTenantStatus(ctx, tenant, StatusBakingMinIOUpgrade, totalReplicas); err != nil {
+			return tenant, err
+		}
+		return tenant, ErrRollingUpgradeInProgress
+	}
+
+	klog.Infof("Tenant '%s/%s' upgraded from %s to %s", tenant.Namespace, tenant.Name, upgrade.From, upgrade.To)
+	c.recorder.Event(tenant, corev1.EventTypeNormal, "Upgraded",
+		fmt.Sprintf("MinIO pods upgraded from %s to %s", upgrade.From, upgrade.To))
+	c.metrics.countUpgrade(tenant.Namespace, tenant.Name, upgradeSucceeded)
+	return c.endUpgrade(ctx, tenant, miniov2.UpgradeSucceeded, "")
+}
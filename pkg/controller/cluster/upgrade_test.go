@@ -0,0 +1,390 @@
+// This file is part of MinIO Operator
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cluster
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	fakeminio "github.com/minio/operator/pkg/client/clientset/versioned/fake"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	upgradeTestFrom = "minio/minio:RELEASE.2021-06-09T18-51-39Z"
+	upgradeTestTo   = "minio/minio:RELEASE.2021-06-17T00-10-46Z"
+)
+
+// upgradeTestTenant returns a tenant of one pool of 4 servers with a rolling upgrade in progress
+func upgradeTestTenant(progressTime time.Time) *miniov2.Tenant {
+	progress := metav1.NewTime(progressTime)
+	return &miniov2.Tenant{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant", Namespace: "ns"},
+		Spec: miniov2.TenantSpec{
+			Image:           upgradeTestTo,
+			Pools:           []miniov2.Pool{{Name: "pool-0", Servers: 4, VolumesPerServer: 1}},
+			UpgradeStrategy: &miniov2.UpgradeStrategy{Type: miniov2.RollingUpgradeStrategy},
+		},
+		Status: miniov2.TenantStatus{
+			Pools: []miniov2.PoolStatus{{SSName: "tenant-pool-0", State: miniov2.PoolInitialized}},
+			UpgradeHistory: []miniov2.UpgradeRecord{{
+				From:         upgradeTestFrom,
+				To:           upgradeTestTo,
+				Strategy:     miniov2.RollingUpgradeStrategy,
+				StartTime:    progress,
+				ProgressTime: &progress,
+				Result:       miniov2.UpgradeInProgress,
+			}},
+		},
+	}
+}
+
+// upgradeTestStatefulSet returns the statefulset of the pool running the image, its pods with an ordinal of at
+// least the partition restarted and ready
+func upgradeTestStatefulSet(image string, partition int32) *appsv1.StatefulSet {
+	replicas := int32(4)
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant-pool-0", Namespace: "ns", Generation: 2},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "minio", Image: image}}},
+			},
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: &partition},
+			},
+		},
+		Status: appsv1.StatefulSetStatus{
+			ObservedGeneration: 2,
+			UpdatedReplicas:    replicas - partition,
+			ReadyReplicas:      replicas,
+		},
+	}
+}
+
+func newUpgradeTestController(t *testing.T, tenant *miniov2.Tenant, ss *appsv1.StatefulSet, healthy bool) *Controller {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := indexer.Add(ss); err != nil {
+		t.Fatal(err)
+	}
+	clusterHealthy := minioClusterHealthy
+	minioClusterHealthy = func(*miniov2.Tenant, *http.Transport) bool { return healthy }
+	t.Cleanup(func() { minioClusterHealthy = clusterHealthy })
+	kubeClient := fake.NewSimpleClientset(ss)
+	return &Controller{
+		kubeClientSet:     kubeClient,
+		transports:        NewTenantTransports(kubeinformers.NewSharedInformerFactory(kubeClient, 0).Core().V1().Secrets()),
+		minioClientSet:    fakeminio.NewSimpleClientset(tenant),
+		statefulSetLister: appslisters.NewStatefulSetLister(indexer),
+		recorder:          record.NewFakeRecorder(10),
+		metrics:           newOperatorMetrics(nil),
+	}
+}
+
+// updatedStatefulSet returns the statefulset of the pool as updated by the controller
+func updatedStatefulSet(t *testing.T, c *Controller) (image string, partition int32) {
+	ss, err := c.kubeClientSet.AppsV1().StatefulSets("ns").Get(context.Background(), "tenant-pool-0", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ss.Spec.Template.Spec.Containers[0].Image, *ss.Spec.UpdateStrategy.RollingUpdate.Partition
+}
+
+func Test_rollPool(t *testing.T) {
+	tests := []struct {
+		name          string
+		ss            *appsv1.StatefulSet
+		rollback      bool
+		unhealthy     bool
+		want          poolRollout
+		wantImage     string
+		wantPartition int32
+	}{
+		{
+			name:          "Restart the highest ordinal with the new image",
+			ss:            upgradeTestStatefulSet(upgradeTestFrom, 0),
+			want:          poolRolloutProgressed,
+			wantImage:     upgradeTestTo,
+			wantPartition: 3,
+		},
+		{
+			name:          "Restart the next ordinal once the restarted pods are ready",
+			ss:            upgradeTestStatefulSet(upgradeTestTo, 3),
+			want:          poolRolloutProgressed,
+			wantImage:     upgradeTestTo,
+			wantPartition: 2,
+		},
+		{
+			name: "Wait for the restarted pod to run the new image",
+			ss: func() *appsv1.StatefulSet {
+				ss := upgradeTestStatefulSet(upgradeTestTo, 2)
+				ss.Status.UpdatedReplicas = 1
+				return ss
+			}(),
+			want:          poolRolloutWaiting,
+			wantImage:     upgradeTestTo,
+			wantPartition: 2,
+		},
+		{
+			name: "Wait for the restarted pod to be ready",
+			ss: func() *appsv1.StatefulSet {
+				ss := upgradeTestStatefulSet(upgradeTestTo, 2)
+				ss.Status.ReadyReplicas = 3
+				return ss
+			}(),
+			want:          poolRolloutWaiting,
+			wantImage:     upgradeTestTo,
+			wantPartition: 2,
+		},
+		{
+			name: "Wait for the statefulset controller to observe the update",
+			ss: func() *appsv1.StatefulSet {
+				ss := upgradeTestStatefulSet(upgradeTestTo, 2)
+				ss.Generation = 3
+				return ss
+			}(),
+			want:          poolRolloutWaiting,
+			wantImage:     upgradeTestTo,
+			wantPartition: 2,
+		},
+		{
+			name:          "Stop once the cluster is unhealthy",
+			ss:            upgradeTestStatefulSet(upgradeTestTo, 2),
+			unhealthy:     true,
+			want:          poolRolloutUnhealthy,
+			wantImage:     upgradeTestTo,
+			wantPartition: 2,
+		},
+		{
+			name:          "Pool upgraded",
+			ss:            upgradeTestStatefulSet(upgradeTestTo, 0),
+			want:          poolRolloutDone,
+			wantImage:     upgradeTestTo,
+			wantPartition: 0,
+		},
+		{
+			name:          "Rollback restarts every pod",
+			ss:            upgradeTestStatefulSet(upgradeTestTo, 2),
+			rollback:      true,
+			unhealthy:     true,
+			want:          poolRolloutProgressed,
+			wantImage:     upgradeTestFrom,
+			wantPartition: 0,
+		},
+		{
+			name:          "Rollback ignores the cluster health",
+			ss:            upgradeTestStatefulSet(upgradeTestFrom, 0),
+			rollback:      true,
+			unhealthy:     true,
+			want:          poolRolloutDone,
+			wantImage:     upgradeTestFrom,
+			wantPartition: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tenant := upgradeTestTenant(time.Now())
+			c := newUpgradeTestController(t, tenant, tt.ss, !tt.unhealthy)
+			image := upgradeTestTo
+			if tt.rollback {
+				image = upgradeTestFrom
+			}
+			got, err := c.rollPool(context.Background(), tenant, tt.ss, image, tt.rollback)
+			if err != nil {
+				t.Fatalf("rollPool() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("rollPool() = %v, want %v", got, tt.want)
+			}
+			if image, partition := updatedStatefulSet(t, c); image != tt.wantImage || partition != tt.wantPartition {
+				t.Errorf("rollPool() statefulset runs %s from partition %d, want %s from %d", image, partition, tt.wantImage, tt.wantPartition)
+			}
+		})
+	}
+}
+
+func Test_checkRollingUpgrade(t *testing.T) {
+	tests := []struct {
+		name          string
+		tenant        *miniov2.Tenant
+		ss            *appsv1.StatefulSet
+		unhealthy     bool
+		wantErr       error
+		wantResult    miniov2.UpgradeResult
+		wantTo        string
+		wantState     string
+		wantImage     string
+		wantPartition int32
+	}{
+		{
+			name:          "Progress",
+			tenant:        upgradeTestTenant(time.Now()),
+			ss:            upgradeTestStatefulSet(upgradeTestTo, 3),
+			wantErr:       ErrRollingUpgradeInProgress,
+			wantResult:    miniov2.UpgradeInProgress,
+			wantTo:        upgradeTestTo,
+			wantState:     StatusRollingMinIOUpgrade,
+			wantImage:     upgradeTestTo,
+			wantPartition: 2,
+		},
+		{
+			name:   "Wait within the progress deadline",
+			tenant: upgradeTestTenant(time.Now()),
+			ss: func() *appsv1.StatefulSet {
+				ss := upgradeTestStatefulSet(upgradeTestTo, 3)
+				ss.Status.ReadyReplicas = 3
+				return ss
+			}(),
+			wantErr:       ErrRollingUpgradeInProgress,
+			wantResult:    miniov2.UpgradeInProgress,
+			wantTo:        upgradeTestTo,
+			wantState:     StatusRollingMinIOUpgrade,
+			wantImage:     upgradeTestTo,
+			wantPartition: 3,
+		},
+		{
+			name:   "Roll back past the progress deadline",
+			tenant: upgradeTestTenant(time.Now().Add(-miniov2.DefaultUpgradeProgressDeadline - time.Minute)),
+			ss: func() *appsv1.StatefulSet {
+				ss := upgradeTestStatefulSet(upgradeTestTo, 3)
+				ss.Status.ReadyReplicas = 3
+				return ss
+			}(),
+			wantErr:       ErrRollingUpgradeInProgress,
+			wantResult:    miniov2.UpgradeRollingBack,
+			wantTo:        upgradeTestTo,
+			wantState:     StatusRollingBackMinIOUpgrade,
+			wantImage:     upgradeTestTo,
+			wantPartition: 3,
+		},
+		{
+			name:          "Roll back once the cluster is unhealthy",
+			tenant:        upgradeTestTenant(time.Now()),
+			ss:            upgradeTestStatefulSet(upgradeTestTo, 3),
+			unhealthy:     true,
+			wantErr:       ErrRollingUpgradeInProgress,
+			wantResult:    miniov2.UpgradeRollingBack,
+			wantTo:        upgradeTestTo,
+			wantState:     StatusRollingBackMinIOUpgrade,
+			wantImage:     upgradeTestTo,
+			wantPartition: 3,
+		},
+		{
+			name: "Restore the previous image",
+			tenant: func() *miniov2.Tenant {
+				tenant := upgradeTestTenant(time.Now())
+				tenant.Status.UpgradeHistory[0].Result = miniov2.UpgradeRollingBack
+				return tenant
+			}(),
+			ss:            upgradeTestStatefulSet(upgradeTestTo, 3),
+			unhealthy:     true,
+			wantErr:       ErrRollingUpgradeInProgress,
+			wantResult:    miniov2.UpgradeRollingBack,
+			wantTo:        upgradeTestTo,
+			wantState:     StatusRollingBackMinIOUpgrade,
+			wantImage:     upgradeTestFrom,
+			wantPartition: 0,
+		},
+		{
+			name: "Rolled back",
+			tenant: func() *miniov2.Tenant {
+				tenant := upgradeTestTenant(time.Now())
+				tenant.Status.UpgradeHistory[0].Result = miniov2.UpgradeRollingBack
+				return tenant
+			}(),
+			ss:            upgradeTestStatefulSet(upgradeTestFrom, 0),
+			wantResult:    miniov2.UpgradeRolledBack,
+			wantTo:        upgradeTestTo,
+			wantImage:     upgradeTestFrom,
+			wantPartition: 0,
+		},
+		{
+			name: "Carry on with the image changed during the upgrade",
+			tenant: func() *miniov2.Tenant {
+				tenant := upgradeTestTenant(time.Now())
+				tenant.Spec.Image = "minio/minio:RELEASE.2021-06-18T00-00-00Z"
+				return tenant
+			}(),
+			ss:            upgradeTestStatefulSet(upgradeTestTo, 2),
+			wantErr:       ErrRollingUpgradeInProgress,
+			wantResult:    miniov2.UpgradeInProgress,
+			wantTo:        "minio/minio:RELEASE.2021-06-18T00-00-00Z",
+			wantState:     StatusRollingMinIOUpgrade,
+			wantImage:     "minio/minio:RELEASE.2021-06-18T00-00-00Z",
+			wantPartition: 3,
+		},
+		{
+			name:          "Bake once every pod is upgraded",
+			tenant:        upgradeTestTenant(time.Now()),
+			ss:            upgradeTestStatefulSet(upgradeTestTo, 0),
+			wantErr:       ErrRollingUpgradeInProgress,
+			wantResult:    miniov2.UpgradeInProgress,
+			wantTo:        upgradeTestTo,
+			wantState:     StatusBakingMinIOUpgrade,
+			wantImage:     upgradeTestTo,
+			wantPartition: 0,
+		},
+		{
+			name: "Upgraded after the bake time",
+			tenant: func() *miniov2.Tenant {
+				tenant := upgradeTestTenant(time.Now())
+				bakeStart := metav1.NewTime(time.Now().Add(-miniov2.DefaultUpgradeBakeTime - time.Minute))
+				tenant.Status.UpgradeHistory[0].BakeStartTime = &bakeStart
+				return tenant
+			}(),
+			ss:            upgradeTestStatefulSet(upgradeTestTo, 0),
+			wantResult:    miniov2.UpgradeSucceeded,
+			wantTo:        upgradeTestTo,
+			wantImage:     upgradeTestTo,
+			wantPartition: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newUpgradeTestController(t, tt.tenant, tt.ss, !tt.unhealthy)
+			_, err := c.checkRollingUpgrade(context.Background(), tt.tenant, []string{tt.ss.Spec.Template.Spec.Containers[0].Image}, 4)
+			if err != tt.wantErr {
+				t.Fatalf("checkRollingUpgrade() error = %v, want %v", err, tt.wantErr)
+			}
+			tenant, err := c.minioClientSet.MinioV2().Tenants("ns").Get(context.Background(), "tenant", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			upgrade := tenant.Status.UpgradeHistory[len(tenant.Status.UpgradeHistory)-1]
+			if upgrade.Result != tt.wantResult || upgrade.To != tt.wantTo {
+				t.Errorf("checkRollingUpgrade() upgrade = %s to %s, want %s to %s", upgrade.Result, upgrade.To, tt.wantResult, tt.wantTo)
+			}
+			if tt.wantState != "" && tenant.Status.CurrentState != tt.wantState {
+				t.Errorf("checkRollingUpgrade() state = %q, want %q", tenant.Status.CurrentState, tt.wantState)
+			}
+			if image, partition := updatedStatefulSet(t, c); image != tt.wantImage || partition != tt.wantPartition {
+				t.Errorf("checkRollingUpgrade() statefulset runs %s from partition %d, want %s from %d", image, partition, tt.wantImage, tt.wantPartition)
+			}
+		})
+	}
+}
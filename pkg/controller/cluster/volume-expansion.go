@@ -0,0 +1,204 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+)
+
+// ErrPoolVolumesResizing is returned while the volumes of a pool are being expanded
+var ErrPoolVolumesResizing = errors.New("Waiting for the volumes of the pool to be resized")
+
+// poolStorageGrowth returns the storage requested by the pool volume claim template when it is
+// larger than the one the statefulset of the pool was created with
+func poolStorageGrowth(pool *miniov2.Pool, ss *appsv1.StatefulSet) (resource.Quantity, bool) {
+	if pool.VolumeClaimTemplate == nil || len(ss.Spec.VolumeClaimTemplates) == 0 {
+		return resource.Quantity{}, false
+	}
+	requested, ok := pool.VolumeClaimTemplate.Spec.Resources.Requests[corev1.ResourceStorage]
+	if !ok {
+		return resource.Quantity{}, false
+	}
+	current := ss.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage]
+	return requested, requested.Cmp(current) > 0
+}
+
+// storageClassAllowsExpansion tells whether the volumes of the given storage class can be expanded
+func (c *Controller) storageClassAllowsExpansion(ctx context.Context, storageClassName *string) (bool, error) {
+	if storageClassName == nil || *storageClassName == "" {
+		return false, nil
+	}
+	sc, err := c.kubeClientSet.StorageV1().StorageClasses().Get(ctx, *storageClassName, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return sc.AllowVolumeExpansion != nil && *sc.AllowVolumeExpansion, nil
+}
+
+// poolClaimNames returns the names of the Persistent Volume Claims of the drives of a pool, the claims of the
+// volume claim templates of the sidecars share the labels of the pool but aren't drives
+func poolClaimNames(pool *miniov2.Pool, ssName string) map[string]bool {
+	name := miniov2.MinIOVolumeName
+	if pool.VolumeClaimTemplate != nil {
+		name = pool.VolumeClaimTemplate.Name
+	}
+	claims := map[string]bool{}
+	for i := 0; i < int(pool.VolumesPerServer); i++ {
+		for ordinal := 0; ordinal < int(pool.Servers); ordinal++ {
+			claims[fmt.Sprintf("%s%d-%s-%d", name, i, ssName, ordinal)] = true
+		}
+	}
+	return claims
+}
+
+// checkPoolVolumeExpansion grows the Persistent Volume Claims of a pool when the storage requested by its
+// volume claim template increases. Since the volume claim templates of a statefulset are immutable, once
+// every claim is resized the statefulset is deleted leaving its pods running and is created again.
+func (c *Controller) checkPoolVolumeExpansion(ctx context.Context, tenant *miniov2.Tenant, pi int, ss *appsv1.StatefulSet) (*miniov2.Tenant, *appsv1.StatefulSet, error) {
+	// the statefulset deleted to resize the volumes is created again once the lister no longer has it
+	if ss.DeletionTimestamp != nil {
+		return tenant, ss, ErrPoolVolumesResizing
+	}
+	pool := tenant.Spec.Pools[pi]
+	requested, grow := poolStorageGrowth(&pool, ss)
+	if !grow || len(tenant.Status.Pools) <= pi {
+		return tenant, ss, nil
+	}
+
+	selector := labels.SelectorFromSet(map[string]string{
+		miniov2.TenantLabel: tenant.Name,
+		miniov2.PoolLabel:   pool.Name,
+	})
+	pvcs, err := c.kubeClientSet.CoreV1().PersistentVolumeClaims(tenant.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return tenant, ss, err
+	}
+	claimNames := poolClaimNames(&pool, ss.Name)
+	var claims []corev1.PersistentVolumeClaim
+	for _, pvc := range pvcs.Items {
+		if claimNames[pvc.Name] {
+			claims = append(claims, pvc)
+		}
+	}
+
+	expansion := &miniov2.PoolVolumeExpansionStatus{
+		RequestedSize: requested.String(),
+		TotalClaims:   int32(len(claims)),
+	}
+	for _, pvc := range claims {
+		// The claims are bound, so the storage class is the one that actually provisioned the volumes
+		expandable, err := c.storageClassAllowsExpansion(ctx, pvc.Spec.StorageClassName)
+		if err != nil {
+			return tenant, ss, err
+		}
+		if !expandable {
+			expansion.Message = fmt.Sprintf("Storage class of claim %s does not allow volume expansion", pvc.Name)
+			break
+		}
+		if capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok && capacity.Cmp(requested) >= 0 {
+			expansion.ResizedClaims++
+			continue
+		}
+		current := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		if current.Cmp(requested) >= 0 {
+			continue
+		}
+		klog.Infof("Expanding PersistentVolumeClaim %s of pool %s to %s", pvc.Name, pool.Name, requested.String())
+		pvcCopy := pvc.DeepCopy()
+		pvcCopy.Spec.Resources.Requests[corev1.ResourceStorage] = requested
+		if _, err = c.kubeClientSet.CoreV1().PersistentVolumeClaims(tenant.Namespace).Update(ctx, pvcCopy, metav1.UpdateOptions{}); err != nil {
+			return tenant, ss, err
+		}
+	}
+
+	if expansion.Message != "" {
+		// Nothing we can do until the storage request is reverted, keep reconciling the rest of the tenant
+		if previous := tenant.Status.Pools[pi].VolumeExpansion; previous == nil || *previous != *expansion {
+			klog.Warningf("Unable to expand the volumes of pool %s of Tenant '%s/%s': %s", pool.Name, tenant.Namespace, tenant.Name, expansion.Message)
+			c.recorder.Event(tenant, corev1.EventTypeWarning, "VolumeExpansionNotSupported", expansion.Message)
+			tenant.Status.Pools[pi].VolumeExpansion = expansion
+			if tenant, err = c.updatePoolStatus(ctx, tenant); err != nil {
+				return tenant, ss, err
+			}
+		}
+		return tenant, ss, nil
+	}
+
+	if expansion.ResizedClaims < expansion.TotalClaims {
+		tenant.Status.Pools[pi].VolumeExpansion = expansion
+		if tenant, err = c.updatePoolStatus(ctx, tenant); err != nil {
+			return tenant, ss, err
+		}
+		return tenant, ss, ErrPoolVolumesResizing
+	}
+
+	// Every claim has the new size, replace the statefulset so new claims are created with it as well. It is
+	// created again once the lister no longer has it, with the rollout and the deferred changes of the deleted one.
+	klog.Infof("Volumes of pool %s resized, recreating StatefulSet %s", pool.Name, ss.Name)
+	c.resizedPools.Store(ss.Namespace+"/"+ss.Name, ss.DeepCopy())
+	orphan := metav1.DeletePropagationOrphan
+	err = c.kubeClientSet.AppsV1().StatefulSets(tenant.Namespace).Delete(ctx, ss.Name, metav1.DeleteOptions{
+		PropagationPolicy: &orphan,
+	})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return tenant, ss, err
+	}
+	return tenant, ss, ErrPoolVolumesResizing
+}
+
+// keepResizedPool carries the rollout of the statefulset of a pool deleted to resize its volumes over to the
+// statefulset recreating it, along with the changes deferred to the maintenance window. The deleted statefulset is
+// only kept in memory, a pool recreated by another replica of the Operator takes the spec of the tenant.
+func (c *Controller) keepResizedPool(nss *appsv1.StatefulSet, pool *miniov2.Pool, windowOpen bool) {
+	value, ok := c.resizedPools.Load(nss.Namespace + "/" + nss.Name)
+	if !ok {
+		return
+	}
+	ss := value.(*appsv1.StatefulSet)
+	keepPoolRollout(nss, ss)
+	if !windowOpen {
+		deferPoolChanges(nss, ss, pool)
+	}
+}
+
+// completePoolResize forgets the statefulset of a pool deleted to resize its volumes once it is created again, and
+// clears the volume expansion status of the pool
+func (c *Controller) completePoolResize(ctx context.Context, tenant *miniov2.Tenant, pi int, ss *appsv1.StatefulSet) (*miniov2.Tenant, error) {
+	c.resizedPools.Delete(ss.Namespace + "/" + ss.Name)
+	if len(tenant.Status.Pools) <= pi || tenant.Status.Pools[pi].VolumeExpansion == nil {
+		return tenant, nil
+	}
+	tenant.Status.Pools[pi].VolumeExpansion = nil
+	return c.updatePoolStatus(ctx, tenant)
+}
@@ -0,0 +1,185 @@
+// This file is part of MinIO Operator
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	fakeminio "github.com/minio/operator/pkg/client/clientset/versioned/fake"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func claimTemplate(size string) corev1.PersistentVolumeClaim {
+	return corev1.PersistentVolumeClaim{
+		Spec: corev1.PersistentVolumeClaimSpec{
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: resource.MustParse(size),
+				},
+			},
+		},
+	}
+}
+
+func Test_poolStorageGrowth(t *testing.T) {
+	tests := []struct {
+		name     string
+		poolSize string
+		ssSize   string
+		want     bool
+	}{
+		{name: "Same size", poolSize: "1Gi", ssSize: "1Gi"},
+		{name: "Grown", poolSize: "2Gi", ssSize: "1Gi", want: true},
+		{name: "Same size different unit", poolSize: "1024Mi", ssSize: "1Gi"},
+		{name: "Shrunk", poolSize: "1Gi", ssSize: "2Gi"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vct := claimTemplate(tt.poolSize)
+			pool := &miniov2.Pool{VolumeClaimTemplate: &vct}
+			ss := &appsv1.StatefulSet{
+				Spec: appsv1.StatefulSetSpec{
+					VolumeClaimTemplates: []corev1.PersistentVolumeClaim{claimTemplate(tt.ssSize)},
+				},
+			}
+			requested, got := poolStorageGrowth(pool, ss)
+			if got != tt.want {
+				t.Errorf("poolStorageGrowth() = %v, want %v", got, tt.want)
+			}
+			if got && requested.String() != tt.poolSize {
+				t.Errorf("poolStorageGrowth() requested = %s, want %s", requested.String(), tt.poolSize)
+			}
+		})
+	}
+}
+
+func Test_poolClaimNames(t *testing.T) {
+	pool := &miniov2.Pool{
+		Name:             "pool-0",
+		Servers:          2,
+		VolumesPerServer: 2,
+		VolumeClaimTemplate: &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "data"},
+		},
+	}
+	claims := poolClaimNames(pool, "tenant-pool-0")
+	for _, name := range []string{"data0-tenant-pool-0-0", "data1-tenant-pool-0-0", "data0-tenant-pool-0-1", "data1-tenant-pool-0-1"} {
+		if !claims[name] {
+			t.Errorf("poolClaimNames() is missing %s", name)
+		}
+	}
+	if len(claims) != 4 {
+		t.Errorf("poolClaimNames() = %v, want 4 claims", claims)
+	}
+	// the claims of the sidecars carry the labels of the pool too
+	if claims["sidecar-data-tenant-pool-0-0"] || claims["data2-tenant-pool-0-0"] || claims["data0-tenant-pool-0-2"] {
+		t.Error("poolClaimNames() includes claims that aren't drives of the pool")
+	}
+
+	pool.VolumeClaimTemplate = nil
+	if claims = poolClaimNames(pool, "tenant-pool-0"); !claims[miniov2.MinIOVolumeName+"0-tenant-pool-0-0"] {
+		t.Errorf("poolClaimNames() = %v, want the claims of the default volume name", claims)
+	}
+}
+
+func poolStatefulSet(image, cpu string) *appsv1.StatefulSet {
+	partition := int32(2)
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant-pool-0", Namespace: "default"},
+		Spec: appsv1.StatefulSetSpec{
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: &partition},
+			},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Image: image,
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+func Test_resizedPool(t *testing.T) {
+	ctx := context.Background()
+	tenant := &miniov2.Tenant{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant", Namespace: "default"},
+		Spec:       miniov2.TenantSpec{Pools: []miniov2.Pool{{Name: "pool-0"}}},
+		Status: miniov2.TenantStatus{
+			Pools: []miniov2.PoolStatus{{
+				SSName:          "tenant-pool-0",
+				VolumeExpansion: &miniov2.PoolVolumeExpansionStatus{},
+			}},
+		},
+	}
+	c := &Controller{minioClientSet: fakeminio.NewSimpleClientset(tenant)}
+
+	// the tenant is requeued while the deleted statefulset is still in the lister
+	deleted := poolStatefulSet("minio/minio:RELEASE.2021-06-01T00-00-00Z", "1")
+	now := metav1.Now()
+	deleted.DeletionTimestamp = &now
+	if _, _, err := c.checkPoolVolumeExpansion(ctx, tenant, 0, deleted); !errors.Is(err, ErrPoolVolumesResizing) {
+		t.Fatalf("checkPoolVolumeExpansion() error = %v, want %v", err, ErrPoolVolumesResizing)
+	}
+
+	// a statefulset that wasn't deleted to resize the volumes is created from the tenant
+	nss := poolStatefulSet("minio/minio:RELEASE.2021-07-01T00-00-00Z", "2")
+	c.keepResizedPool(nss, &tenant.Spec.Pools[0], false)
+	if nss.Spec.Template.Spec.Containers[0].Image != "minio/minio:RELEASE.2021-07-01T00-00-00Z" {
+		t.Errorf("keepResizedPool() changed the image of a pool that wasn't resized")
+	}
+
+	// the recreated statefulset keeps the rollout of the deleted one, and the deferred changes while the window is closed
+	c.resizedPools.Store("default/tenant-pool-0", deleted)
+	for _, windowOpen := range []bool{true, false} {
+		nss = poolStatefulSet("minio/minio:RELEASE.2021-07-01T00-00-00Z", "2")
+		*nss.Spec.UpdateStrategy.RollingUpdate.Partition = 0
+		c.keepResizedPool(nss, &tenant.Spec.Pools[0], windowOpen)
+		if nss.Spec.Template.Spec.Containers[0].Image != "minio/minio:RELEASE.2021-06-01T00-00-00Z" {
+			t.Errorf("keepResizedPool() image = %s, want the image of the deleted statefulset", nss.Spec.Template.Spec.Containers[0].Image)
+		}
+		if *nss.Spec.UpdateStrategy.RollingUpdate.Partition != 2 {
+			t.Errorf("keepResizedPool() partition = %d, want 2", *nss.Spec.UpdateStrategy.RollingUpdate.Partition)
+		}
+		cpu := nss.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU]
+		if want := map[bool]string{true: "2", false: "1"}[windowOpen]; cpu.String() != want {
+			t.Errorf("keepResizedPool() window open %v cpu = %s, want %s", windowOpen, cpu.String(), want)
+		}
+	}
+
+	tenant, err := c.completePoolResize(ctx, tenant, 0, nss)
+	if err != nil {
+		t.Fatalf("completePoolResize() error = %v", err)
+	}
+	if tenant.Status.Pools[0].VolumeExpansion != nil {
+		t.Error("completePoolResize() kept the volume expansion status")
+	}
+	if _, ok := c.resizedPools.Load("default/tenant-pool-0"); ok {
+		t.Error("completePoolResize() kept the deleted statefulset")
+	}
+}
@@ -49,11 +49,18 @@ func configureWebhookServer(c *Controller) *http.Server {
 		Queries(restQueries("bucket")...)
 	router.Methods(http.MethodGet).
 		PathPrefix(miniov2.WebhookAPIUpdate).
-		Handler(http.StripPrefix(miniov2.WebhookAPIUpdate, http.FileServer(http.Dir(updatePath))))
+		Handler(c.UpdateArtifactsHandler())
 	// CRD Conversion
 	router.Methods(http.MethodPost).
 		Path(miniov2.WebhookCRDConversaion).
 		HandlerFunc(c.CRDConversionHandler)
+	// Tenant admission
+	router.Methods(http.MethodPost).
+		Path(miniov2.WebhookValidateTenant).
+		HandlerFunc(c.ValidateTenantHandler)
+	router.Methods(http.MethodPost).
+		Path(miniov2.WebhookDefaultTenant).
+		HandlerFunc(c.DefaultTenantHandler)
 	//.
 	//		Queries(restQueries("bucket")...)
 
@@ -0,0 +1,149 @@
+// This file is part of MinIO Operator
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package iam
+
+import (
+	"reflect"
+	"testing"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	listers "github.com/minio/operator/pkg/client/listers/minio.min.io/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	queue "k8s.io/client-go/util/workqueue"
+)
+
+func Test_normalizePolicy(t *testing.T) {
+	const spec = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":["arn:aws:s3:::reports/*"]}]}`
+	tests := []struct {
+		name  string
+		minio string
+		equal bool
+	}{
+		{
+			name:  "Same document",
+			minio: spec,
+			equal: true,
+		},
+		{
+			name:  "Single action returned as a list",
+			minio: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:GetObject"],"Resource":["arn:aws:s3:::reports/*"]}]}`,
+			equal: true,
+		},
+		{
+			name:  "Action added",
+			minio: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:GetObject","s3:PutObject"],"Resource":["arn:aws:s3:::reports/*"]}]}`,
+		},
+	}
+	want, err := normalizePolicy([]byte(spec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizePolicy([]byte(tt.minio))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reflect.DeepEqual(got, want) != tt.equal {
+				t.Errorf("normalizePolicy() equal = %v, want %v", !tt.equal, tt.equal)
+			}
+		})
+	}
+
+	sorted, _ := normalizePolicy([]byte(`{"Statement":[{"Action":["s3:PutObject","s3:GetObject"]}]}`))
+	unsorted, _ := normalizePolicy([]byte(`{"Statement":[{"Action":["s3:GetObject","s3:PutObject"]}]}`))
+	if !reflect.DeepEqual(sorted, unsorted) {
+		t.Error("normalizePolicy() should ignore the order of the actions")
+	}
+}
+
+func Test_setDifference(t *testing.T) {
+	got := setDifference([]string{"b", "a", "c", ""}, []string{"c"})
+	if !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("setDifference() = %v, want [a b]", got)
+	}
+	if !sameSet([]string{"readwrite", "diagnostics"}, []string{"diagnostics", "readwrite"}) {
+		t.Error("sameSet() should ignore the order")
+	}
+	if sameSet([]string{"readwrite"}, nil) {
+		t.Error("sameSet() should detect missing elements")
+	}
+}
+
+func Test_enqueueSecretUsers(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, user := range []*miniov2.User{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "ready", Namespace: "default"},
+			Spec:       miniov2.UserSpec{CredsSecret: corev1.LocalObjectReference{Name: "user-secret"}},
+			Status:     miniov2.UserStatus{CurrentState: StatusReady},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "failing", Namespace: "default"},
+			Spec:       miniov2.UserSpec{CredsSecret: corev1.LocalObjectReference{Name: "user-secret"}},
+			Status:     miniov2.UserStatus{CurrentState: StatusError},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-secret", Namespace: "default"},
+			Spec:       miniov2.UserSpec{CredsSecret: corev1.LocalObjectReference{Name: "other-secret"}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-namespace", Namespace: "other"},
+			Spec:       miniov2.UserSpec{CredsSecret: corev1.LocalObjectReference{Name: "user-secret"}},
+		},
+	} {
+		if err := indexer.Add(user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "user-secret", Namespace: "default"}}
+
+	tests := []struct {
+		name    string
+		changed bool
+		want    []string
+	}{
+		{name: "Secret created", want: []string{"default/failing"}},
+		{name: "Secret changed", changed: true, want: []string{"default/failing", "default/ready"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Controller{
+				usersLister: listers.NewUserLister(indexer),
+				workqueue:   queue.NewRateLimitingQueue(queue.NewItemExponentialFailureRateLimiter(0, 0)),
+			}
+			c.enqueueSecretUsers(secret, tt.changed)
+			got := map[string]bool{}
+			for c.workqueue.Len() > 0 {
+				item, _ := c.workqueue.Get()
+				if item.(workItem).kind != kindUser {
+					t.Errorf("enqueueSecretUsers() enqueued a %s", item.(workItem).kind)
+				}
+				got[item.(workItem).key] = true
+			}
+			want := map[string]bool{}
+			for _, key := range tt.want {
+				want[key] = true
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("enqueueSecretUsers() enqueued %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
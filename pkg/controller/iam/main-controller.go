@@ -0,0 +1,356 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package iam
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/minio/madmin-go"
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	clientset "github.com/minio/operator/pkg/client/clientset/versioned"
+	informers "github.com/minio/operator/pkg/client/informers/externalversions/minio.min.io/v2"
+	listers "github.com/minio/operator/pkg/client/listers/minio.min.io/v2"
+	"github.com/minio/operator/pkg/controller/cluster"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	queue "k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+)
+
+// IAM states
+const (
+	StatusWaitingForTenant = "Waiting for Tenant"
+	StatusReady            = "Ready"
+	StatusError            = "Error"
+)
+
+// driftCheckInterval is how often the policies and users in MinIO are compared with their objects
+const driftCheckInterval = 5 * time.Minute
+
+// Kinds of the objects handled by the controller
+const (
+	kindPolicy = "Policy"
+	kindUser   = "User"
+)
+
+// workItem identifies an object on the workqueue
+type workItem struct {
+	kind string
+	key  string
+}
+
+// Controller reconciles the Policy and User objects against the IAM of their Tenant
+type Controller struct {
+	// kubeClientSet is a standard kubernetes clientset
+	kubeClientSet kubernetes.Interface
+	// minioClientSet is a clientset for our own API group
+	minioClientSet clientset.Interface
+
+	// policiesLister lists Policy from a shared informer's store
+	policiesLister listers.PolicyLister
+	// policiesSynced returns true if the Policy shared informer has synced at least once
+	policiesSynced cache.InformerSynced
+
+	// usersLister lists User from a shared informer's store
+	usersLister listers.UserLister
+	// usersSynced returns true if the User shared informer has synced at least once
+	usersSynced cache.InformerSynced
+
+	// tenantsLister lists Tenant from a shared informer's store
+	tenantsLister listers.TenantLister
+	// tenantsSynced returns true if the Tenant shared informer has synced at least once
+	tenantsSynced cache.InformerSynced
+
+	// transports the Operator connects to the Tenants with, shared with the Tenant controller
+	transports *cluster.TenantTransports
+
+	// workqueue is a rate limited work queue of Policy and User keys
+	workqueue queue.RateLimitingInterface
+}
+
+// NewController returns a new IAM controller
+func NewController(
+	kubeClientSet kubernetes.Interface,
+	minioClientSet clientset.Interface,
+	policyInformer informers.PolicyInformer,
+	userInformer informers.UserInformer,
+	tenantInformer informers.TenantInformer,
+	secretInformer coreinformers.SecretInformer,
+	transports *cluster.TenantTransports) *Controller {
+
+	controller := &Controller{
+		kubeClientSet:  kubeClientSet,
+		minioClientSet: minioClientSet,
+		policiesLister: policyInformer.Lister(),
+		policiesSynced: policyInformer.Informer().HasSynced,
+		usersLister:    userInformer.Lister(),
+		usersSynced:    userInformer.Informer().HasSynced,
+		tenantsLister:  tenantInformer.Lister(),
+		tenantsSynced:  tenantInformer.Informer().HasSynced,
+		transports:     transports,
+		workqueue:      queue.NewNamedRateLimitingQueue(cluster.MinIOControllerRateLimiter(), "IAM"),
+	}
+
+	klog.Info("Setting up IAM event handlers")
+	policyInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			controller.enqueue(kindPolicy, obj)
+		},
+		UpdateFunc: func(old, new interface{}) {
+			if !specChanged(old.(*miniov2.Policy), new.(*miniov2.Policy)) {
+				return
+			}
+			controller.enqueue(kindPolicy, new)
+		},
+	})
+	userInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			controller.enqueue(kindUser, obj)
+		},
+		UpdateFunc: func(old, new interface{}) {
+			if !specChanged(old.(*miniov2.User), new.(*miniov2.User)) {
+				return
+			}
+			controller.enqueue(kindUser, new)
+		},
+	})
+	// Objects waiting for their Tenant are picked up again once the Tenant changes
+	tenantInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.enqueueTenantObjects,
+		UpdateFunc: func(old, new interface{}) {
+			if new.(*miniov2.Tenant).ResourceVersion == old.(*miniov2.Tenant).ResourceVersion {
+				return
+			}
+			controller.enqueueTenantObjects(new)
+		},
+	})
+	// Users are synced again when their credentials secret is created or changes
+	secretInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			controller.enqueueSecretUsers(obj, false)
+		},
+		UpdateFunc: func(old, new interface{}) {
+			if new.(*corev1.Secret).ResourceVersion == old.(*corev1.Secret).ResourceVersion {
+				// Periodic resync will send update events for all known Secrets.
+				return
+			}
+			controller.enqueueSecretUsers(new, true)
+		},
+	})
+	return controller
+}
+
+// Start waits for the informer caches to sync and starts the workers. It doesn't
+// block, the workers stop once stopCh is closed.
+func (c *Controller) Start(threadiness int, stopCh <-chan struct{}) error {
+	klog.Info("Starting IAM controller")
+
+	klog.Info("Waiting for IAM informer caches to sync")
+	if ok := cache.WaitForCacheSync(stopCh, c.policiesSynced, c.usersSynced, c.tenantsSynced, c.transports.HasSynced); !ok {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+
+	for i := 0; i < threadiness; i++ {
+		go wait.Until(c.runWorker, time.Second, stopCh)
+	}
+	return nil
+}
+
+// Stop is called to shutdown the controller
+func (c *Controller) Stop() {
+	klog.Info("Stopping the IAM controller")
+	c.workqueue.ShutDown()
+}
+
+// runWorker processes the items of the workqueue until it is shut down
+func (c *Controller) runWorker() {
+	defer runtime.HandleCrash()
+	for c.processNextWorkItem() {
+	}
+}
+
+// processNextWorkItem reads a single item off the workqueue and syncs its object
+func (c *Controller) processNextWorkItem() bool {
+	obj, shutdown := c.workqueue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.workqueue.Done(obj)
+
+	item, ok := obj.(workItem)
+	if !ok {
+		c.workqueue.Forget(obj)
+		runtime.HandleError(fmt.Errorf("expected workItem in workqueue but got %#v", obj))
+		return true
+	}
+	if err := c.syncHandler(item); err != nil {
+		// Put the item back on the workqueue to handle any transient errors.
+		c.workqueue.AddRateLimited(item)
+		runtime.HandleError(fmt.Errorf("error syncing %s '%s': %s", item.kind, item.key, err.Error()))
+		return true
+	}
+	c.workqueue.Forget(obj)
+	klog.V(2).Infof("Successfully synced %s '%s'", item.kind, item.key)
+	return true
+}
+
+// syncHandler converges MinIO with the object of the given item and schedules the next drift check
+func (c *Controller) syncHandler(item workItem) error {
+	ctx := context.Background()
+
+	namespace, name, err := cache.SplitMetaNamespaceKey(item.key)
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("Invalid resource key: %s", item.key))
+		return nil
+	}
+
+	var recheck bool
+	switch item.kind {
+	case kindPolicy:
+		recheck, err = c.syncPolicy(ctx, namespace, name)
+	case kindUser:
+		recheck, err = c.syncUser(ctx, namespace, name)
+	}
+	if err != nil {
+		return err
+	}
+	if recheck {
+		c.workqueue.AddAfter(item, driftCheckInterval)
+	}
+	return nil
+}
+
+// enqueue puts the item of a Policy or User on the workqueue
+func (c *Controller) enqueue(kind string, obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	c.workqueue.AddRateLimited(workItem{kind: kind, key: key})
+}
+
+// enqueueTenantObjects enqueues the Policies and Users hosted by the given Tenant that are not ready
+func (c *Controller) enqueueTenantObjects(obj interface{}) {
+	tenant, ok := obj.(*miniov2.Tenant)
+	if !ok {
+		return
+	}
+	policies, err := c.policiesLister.Policies(tenant.Namespace).List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	for _, policy := range policies {
+		if policy.Spec.Tenant.Name == tenant.Name && policy.Status.CurrentState != StatusReady {
+			c.enqueue(kindPolicy, policy)
+		}
+	}
+	users, err := c.usersLister.Users(tenant.Namespace).List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	for _, user := range users {
+		if user.Spec.Tenant.Name == tenant.Name && user.Status.CurrentState != StatusReady {
+			c.enqueue(kindUser, user)
+		}
+	}
+}
+
+// enqueueSecretUsers enqueues the Users using the given secret as their credentials secret. Unless the
+// secret changed, only the Users that are not ready are enqueued.
+func (c *Controller) enqueueSecretUsers(obj interface{}, changed bool) {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		return
+	}
+	users, err := c.usersLister.Users(secret.Namespace).List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	for _, user := range users {
+		if user.Spec.CredsSecret.Name == secret.Name && (changed || user.Status.CurrentState != StatusReady) {
+			c.enqueue(kindUser, user)
+		}
+	}
+}
+
+// getTenant returns the Tenant of the given name. Ready is false when it doesn't exist,
+// is being deleted or doesn't report the Ready condition yet.
+func (c *Controller) getTenant(namespace, name string) (tenant *miniov2.Tenant, ready bool, err error) {
+	tenant, err = c.tenantsLister.Tenants(namespace).Get(name)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	ready = tenant.DeletionTimestamp == nil && meta.IsStatusConditionTrue(tenant.Status.Conditions, miniov2.TenantConditionReady)
+	return tenant, ready, nil
+}
+
+// adminClient returns the admin client of the Tenant, authenticated with its root credentials
+func (c *Controller) adminClient(ctx context.Context, tenant *miniov2.Tenant) (*madmin.AdminClient, error) {
+	if tenant.Spec.CredsSecret == nil {
+		return nil, fmt.Errorf("Tenant '%s/%s' has no credentials secret", tenant.Namespace, tenant.Name)
+	}
+	minioSecret, err := c.kubeClientSet.CoreV1().Secrets(tenant.Namespace).Get(ctx, tenant.Spec.CredsSecret.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return tenant.NewMinIOAdmin(minioSecret.Data, c.transports.Transport(tenant))
+}
+
+// specChanged tells whether an update needs the object to be synced. Status updates, including
+// the periodic drift checks, are ignored.
+func specChanged(old, new metav1.Object) bool {
+	return old.GetGeneration() != new.GetGeneration() || new.GetDeletionTimestamp() != nil
+}
+
+// hasFinalizer tells whether the finalizers include the given one
+func hasFinalizer(finalizers []string, finalizer string) bool {
+	for _, f := range finalizers {
+		if f == finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// removeFinalizer returns the finalizers without the given one
+func removeFinalizer(finalizers []string, finalizer string) []string {
+	var result []string
+	for _, f := range finalizers {
+		if f != finalizer {
+			result = append(result, f)
+		}
+	}
+	return result
+}
@@ -0,0 +1,239 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package iam
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+
+	"github.com/minio/madmin-go"
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// ErrTenantNotReady is returned while a deleted object waits for its Tenant to remove it from MinIO
+var ErrTenantNotReady = errors.New("Tenant is not ready")
+
+// policyName returns the name of the policy in MinIO
+func policyName(policy *miniov2.Policy) string {
+	if policy.Spec.Name != "" {
+		return policy.Spec.Name
+	}
+	return policy.Name
+}
+
+// normalizePolicy decodes a policy document so documents only differing in formatting compare equal.
+// MinIO returns single actions and resources as lists, and sorts them.
+func normalizePolicy(document []byte) (interface{}, error) {
+	var doc interface{}
+	if err := json.Unmarshal(document, &doc); err != nil {
+		return nil, err
+	}
+	return normalizePolicyValue("", doc), nil
+}
+
+// normalizePolicyValue normalizes the value of the given policy document key
+func normalizePolicyValue(key string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = normalizePolicyValue(k, e)
+		}
+		return v
+	case []interface{}:
+		strs := make([]string, 0, len(v))
+		for i, e := range v {
+			if s, ok := e.(string); ok {
+				strs = append(strs, s)
+				continue
+			}
+			v[i] = normalizePolicyValue("", e)
+		}
+		if len(strs) == len(v) {
+			sort.Strings(strs)
+			for i := range strs {
+				v[i] = strs[i]
+			}
+		}
+		return v
+	case string:
+		switch key {
+		case "Action", "NotAction", "Resource", "NotResource", "AWS":
+			return []interface{}{v}
+		}
+	}
+	return value
+}
+
+// applyPolicy adds or updates the canned policy in MinIO. When checkDrift is set, any difference found between
+// MinIO and the document is reported.
+func applyPolicy(ctx context.Context, adminClnt *madmin.AdminClient, name string, document []byte, checkDrift bool) ([]string, error) {
+	want, err := normalizePolicy(document)
+	if err != nil {
+		return nil, err
+	}
+
+	var drift []string
+	current, err := adminClnt.InfoCannedPolicy(ctx, name)
+	switch {
+	case err == nil:
+		got, err := normalizePolicy(current)
+		if err != nil {
+			return nil, err
+		}
+		if reflect.DeepEqual(got, want) {
+			return nil, nil
+		}
+		if checkDrift {
+			drift = append(drift, "policy document was modified in MinIO")
+		}
+	case madmin.ToErrorResponse(err).Code == "XMinioAdminNoSuchPolicy":
+		if checkDrift {
+			drift = append(drift, "policy was removed from MinIO")
+		}
+	default:
+		return nil, err
+	}
+
+	klog.Infof("Setting policy %s", name)
+	if err = adminClnt.AddCannedPolicy(ctx, name, document); err != nil {
+		return nil, err
+	}
+	return drift, nil
+}
+
+// syncPolicy converges the canned policy in MinIO with the Policy of the given name. It tells
+// whether the policy should be checked again for drift.
+func (c *Controller) syncPolicy(ctx context.Context, namespace, name string) (bool, error) {
+	policy, err := c.policiesLister.Policies(namespace).Get(name)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	// Never modify objects from the store
+	policy = policy.DeepCopy()
+
+	if policy.DeletionTimestamp != nil {
+		return false, c.deletePolicy(ctx, policy)
+	}
+	if !hasFinalizer(policy.Finalizers, miniov2.PolicyFinalizer) {
+		policy.Finalizers = append(policy.Finalizers, miniov2.PolicyFinalizer)
+		if policy, err = c.minioClientSet.MinioV2().Policies(namespace).Update(ctx, policy, metav1.UpdateOptions{}); err != nil {
+			return false, err
+		}
+	}
+
+	tenant, ready, err := c.getTenant(namespace, policy.Spec.Tenant.Name)
+	if err != nil {
+		return false, err
+	}
+	if !ready {
+		// The Tenant informer enqueues the Policy again once the Tenant changes
+		return false, c.updatePolicyStatus(ctx, policy, StatusWaitingForTenant, "", nil)
+	}
+
+	adminClnt, err := c.adminClient(ctx, tenant)
+	if err != nil {
+		return false, c.policyError(ctx, policy, err)
+	}
+	// Differences with MinIO are drift only if this generation was already applied
+	checkDrift := policy.Status.CurrentState == StatusReady && policy.Status.ObservedGeneration == policy.Generation
+	name = policyName(policy)
+	drift, err := applyPolicy(ctx, adminClnt, name, policy.Spec.Document.Raw, checkDrift)
+	if err != nil {
+		return false, c.policyError(ctx, policy, err)
+	}
+	if previous := policy.Status.PolicyName; previous != "" && previous != name {
+		klog.Infof("Removing renamed policy %s", previous)
+		if err = adminClnt.RemoveCannedPolicy(ctx, previous); err != nil && madmin.ToErrorResponse(err).Code != "XMinioAdminNoSuchPolicy" {
+			return false, c.policyError(ctx, policy, err)
+		}
+	}
+	if len(drift) > 0 {
+		klog.Warningf("Policy '%s/%s' drifted: %v", policy.Namespace, policy.Name, drift)
+	}
+
+	policy.Status.PolicyName = name
+	return true, c.updatePolicyStatus(ctx, policy, StatusReady, "", drift)
+}
+
+// policyError records a reconciliation error in the status of the Policy and returns it
+func (c *Controller) policyError(ctx context.Context, policy *miniov2.Policy, err error) error {
+	if serr := c.updatePolicyStatus(ctx, policy, StatusError, err.Error(), policy.Status.Drift); serr != nil {
+		klog.Errorf("Unable to update the status of policy '%s/%s': %v", policy.Namespace, policy.Name, serr)
+	}
+	return err
+}
+
+// updatePolicyStatus records the state of the Policy for its current generation
+func (c *Controller) updatePolicyStatus(ctx context.Context, policy *miniov2.Policy, state, message string, drift []string) error {
+	// Only ready states carry a new drift check worth recording
+	if state != StatusReady && policy.Status.CurrentState == state && policy.Status.Message == message && policy.Status.ObservedGeneration == policy.Generation {
+		return nil
+	}
+	policyCopy := policy.DeepCopy()
+	policyCopy.Status.CurrentState = state
+	policyCopy.Status.Message = message
+	policyCopy.Status.ObservedGeneration = policy.Generation
+	if state == StatusReady {
+		now := metav1.Now()
+		policyCopy.Status.Drift = drift
+		policyCopy.Status.LastDriftCheck = &now
+	}
+	_, err := c.minioClientSet.MinioV2().Policies(policy.Namespace).UpdateStatus(ctx, policyCopy, metav1.UpdateOptions{})
+	return err
+}
+
+// deletePolicy removes the canned policy from MinIO and releases the finalizer of the Policy
+func (c *Controller) deletePolicy(ctx context.Context, policy *miniov2.Policy) error {
+	if !hasFinalizer(policy.Finalizers, miniov2.PolicyFinalizer) {
+		return nil
+	}
+
+	if name := policy.Status.PolicyName; name != "" {
+		tenant, ready, err := c.getTenant(policy.Namespace, policy.Spec.Tenant.Name)
+		if err != nil {
+			return err
+		}
+		// Without a Tenant there's no policy left to remove
+		if tenant != nil && tenant.DeletionTimestamp == nil {
+			if !ready {
+				return ErrTenantNotReady
+			}
+			adminClnt, err := c.adminClient(ctx, tenant)
+			if err != nil {
+				return err
+			}
+			klog.Infof("Removing policy %s of Tenant '%s/%s'", name, tenant.Namespace, tenant.Name)
+			if err = adminClnt.RemoveCannedPolicy(ctx, name); err != nil && madmin.ToErrorResponse(err).Code != "XMinioAdminNoSuchPolicy" {
+				return err
+			}
+		}
+	}
+
+	policy.Finalizers = removeFinalizer(policy.Finalizers, miniov2.PolicyFinalizer)
+	_, err := c.minioClientSet.MinioV2().Policies(policy.Namespace).Update(ctx, policy, metav1.UpdateOptions{})
+	return err
+}
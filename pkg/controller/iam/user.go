@@ -0,0 +1,230 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package iam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/minio/madmin-go"
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// setDifference returns the elements of a that are not in b
+func setDifference(a, b []string) []string {
+	in := make(map[string]bool, len(b))
+	for _, e := range b {
+		in[e] = true
+	}
+	var diff []string
+	for _, e := range a {
+		if e != "" && !in[e] {
+			diff = append(diff, e)
+		}
+	}
+	sort.Strings(diff)
+	return diff
+}
+
+// sameSet tells whether a and b hold the same elements
+func sameSet(a, b []string) bool {
+	return len(setDifference(a, b)) == 0 && len(setDifference(b, a)) == 0
+}
+
+// applyUser adds or updates the user in MinIO along with its policies and group memberships. When
+// checkDrift is set, any difference found between MinIO and the spec is reported.
+func applyUser(ctx context.Context, adminClnt *madmin.AdminClient, accessKey, secretKey string, spec *miniov2.UserSpec, checkDrift bool) ([]string, error) {
+	var drift []string
+	info, err := adminClnt.GetUserInfo(ctx, accessKey)
+	exists := err == nil
+	if err != nil && madmin.ToErrorResponse(err).Code != "XMinioAdminNoSuchUser" {
+		return nil, err
+	}
+	if checkDrift {
+		if !exists {
+			drift = append(drift, "user was removed from MinIO")
+		} else if info.Status != madmin.AccountEnabled {
+			drift = append(drift, "user was disabled in MinIO")
+		}
+	}
+
+	// Setting the user every time keeps its secret key in sync with the credentials secret
+	if err = adminClnt.AddUser(ctx, accessKey, secretKey); err != nil {
+		return nil, err
+	}
+
+	var currentPolicies []string
+	if info.PolicyName != "" {
+		currentPolicies = strings.Split(info.PolicyName, ",")
+	}
+	if !exists || !sameSet(currentPolicies, spec.Policies) {
+		if exists && checkDrift {
+			drift = append(drift, fmt.Sprintf("policies were changed in MinIO to [%s]", info.PolicyName))
+		}
+		if err = adminClnt.SetPolicy(ctx, strings.Join(spec.Policies, ","), accessKey, false); err != nil {
+			return nil, err
+		}
+	}
+
+	join := setDifference(spec.Groups, info.MemberOf)
+	leave := setDifference(info.MemberOf, spec.Groups)
+	if exists && checkDrift && (len(join) > 0 || len(leave) > 0) {
+		drift = append(drift, fmt.Sprintf("groups were changed in MinIO to [%s]", strings.Join(info.MemberOf, ",")))
+	}
+	for _, group := range join {
+		if err = adminClnt.UpdateGroupMembers(ctx, madmin.GroupAddRemove{Group: group, Members: []string{accessKey}}); err != nil {
+			return nil, err
+		}
+	}
+	for _, group := range leave {
+		if err = adminClnt.UpdateGroupMembers(ctx, madmin.GroupAddRemove{Group: group, Members: []string{accessKey}, IsRemove: true}); err != nil {
+			return nil, err
+		}
+	}
+	return drift, nil
+}
+
+// syncUser converges the user in MinIO with the User of the given name. It tells whether the
+// user should be checked again for drift.
+func (c *Controller) syncUser(ctx context.Context, namespace, name string) (bool, error) {
+	user, err := c.usersLister.Users(namespace).Get(name)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	// Never modify objects from the store
+	user = user.DeepCopy()
+
+	if user.DeletionTimestamp != nil {
+		return false, c.deleteUser(ctx, user)
+	}
+	if !hasFinalizer(user.Finalizers, miniov2.UserFinalizer) {
+		user.Finalizers = append(user.Finalizers, miniov2.UserFinalizer)
+		if user, err = c.minioClientSet.MinioV2().Users(namespace).Update(ctx, user, metav1.UpdateOptions{}); err != nil {
+			return false, err
+		}
+	}
+
+	tenant, ready, err := c.getTenant(namespace, user.Spec.Tenant.Name)
+	if err != nil {
+		return false, err
+	}
+	if !ready {
+		// The Tenant informer enqueues the User again once the Tenant changes
+		return false, c.updateUserStatus(ctx, user, StatusWaitingForTenant, "", nil)
+	}
+
+	secret, err := c.kubeClientSet.CoreV1().Secrets(namespace).Get(ctx, user.Spec.CredsSecret.Name, metav1.GetOptions{})
+	if err != nil {
+		return false, c.userError(ctx, user, err)
+	}
+	accessKey, secretKey := string(secret.Data["accesskey"]), string(secret.Data["secretkey"])
+	if accessKey == "" || secretKey == "" {
+		return false, c.userError(ctx, user, errors.New("accesskey and secretkey must be set in the credentials secret"))
+	}
+
+	adminClnt, err := c.adminClient(ctx, tenant)
+	if err != nil {
+		return false, c.userError(ctx, user, err)
+	}
+	// Differences with MinIO are drift only if this generation was already applied with the same access key
+	checkDrift := user.Status.CurrentState == StatusReady && user.Status.ObservedGeneration == user.Generation && user.Status.AccessKey == accessKey
+	drift, err := applyUser(ctx, adminClnt, accessKey, secretKey, &user.Spec, checkDrift)
+	if err != nil {
+		return false, c.userError(ctx, user, err)
+	}
+	if previous := user.Status.AccessKey; previous != "" && previous != accessKey {
+		klog.Infof("Removing user %s replaced by %s", previous, accessKey)
+		if err = adminClnt.RemoveUser(ctx, previous); err != nil && madmin.ToErrorResponse(err).Code != "XMinioAdminNoSuchUser" {
+			return false, c.userError(ctx, user, err)
+		}
+	}
+	if len(drift) > 0 {
+		klog.Warningf("User '%s/%s' drifted: %v", user.Namespace, user.Name, drift)
+	}
+
+	user.Status.AccessKey = accessKey
+	return true, c.updateUserStatus(ctx, user, StatusReady, "", drift)
+}
+
+// userError records a reconciliation error in the status of the User and returns it
+func (c *Controller) userError(ctx context.Context, user *miniov2.User, err error) error {
+	if serr := c.updateUserStatus(ctx, user, StatusError, err.Error(), user.Status.Drift); serr != nil {
+		klog.Errorf("Unable to update the status of user '%s/%s': %v", user.Namespace, user.Name, serr)
+	}
+	return err
+}
+
+// updateUserStatus records the state of the User for its current generation
+func (c *Controller) updateUserStatus(ctx context.Context, user *miniov2.User, state, message string, drift []string) error {
+	// Only ready states carry a new drift check worth recording
+	if state != StatusReady && user.Status.CurrentState == state && user.Status.Message == message && user.Status.ObservedGeneration == user.Generation {
+		return nil
+	}
+	userCopy := user.DeepCopy()
+	userCopy.Status.CurrentState = state
+	userCopy.Status.Message = message
+	userCopy.Status.ObservedGeneration = user.Generation
+	if state == StatusReady {
+		now := metav1.Now()
+		userCopy.Status.Drift = drift
+		userCopy.Status.LastDriftCheck = &now
+	}
+	_, err := c.minioClientSet.MinioV2().Users(user.Namespace).UpdateStatus(ctx, userCopy, metav1.UpdateOptions{})
+	return err
+}
+
+// deleteUser removes the user from MinIO and releases the finalizer of the User
+func (c *Controller) deleteUser(ctx context.Context, user *miniov2.User) error {
+	if !hasFinalizer(user.Finalizers, miniov2.UserFinalizer) {
+		return nil
+	}
+
+	if accessKey := user.Status.AccessKey; accessKey != "" {
+		tenant, ready, err := c.getTenant(user.Namespace, user.Spec.Tenant.Name)
+		if err != nil {
+			return err
+		}
+		// Without a Tenant there's no user left to remove
+		if tenant != nil && tenant.DeletionTimestamp == nil {
+			if !ready {
+				return ErrTenantNotReady
+			}
+			adminClnt, err := c.adminClient(ctx, tenant)
+			if err != nil {
+				return err
+			}
+			klog.Infof("Removing user %s of Tenant '%s/%s'", accessKey, tenant.Namespace, tenant.Name)
+			if err = adminClnt.RemoveUser(ctx, accessKey); err != nil && madmin.ToErrorResponse(err).Code != "XMinioAdminNoSuchUser" {
+				return err
+			}
+		}
+	}
+
+	user.Finalizers = removeFinalizer(user.Finalizers, miniov2.UserFinalizer)
+	_, err := c.minioClientSet.MinioV2().Users(user.Namespace).Update(ctx, user, metav1.UpdateOptions{})
+	return err
+}
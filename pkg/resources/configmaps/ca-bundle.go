@@ -0,0 +1,42 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package configmaps
+
+import (
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CABundleConfigMap returns the config map publishing the CA certificate signing the certificates of the
+// tenant, for the clients to trust the tenant
+func CABundleConfigMap(t *miniov2.Tenant, caPEM []byte) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      t.CABundleConfigMapName(),
+			Namespace: t.Namespace,
+			Labels: map[string]string{
+				miniov2.TenantLabel: t.Name,
+			},
+			OwnerReferences: t.OwnerRef(),
+		},
+		Data: map[string]string{
+			miniov2.CACertificateKey: string(caPEM),
+		},
+	}
+}
@@ -27,6 +27,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // Adds required Console environment variables
@@ -100,7 +101,12 @@ func consoleSecretEnvVars(t *miniov2.Tenant) []corev1.EnvFromSource {
 func consoleMetadata(t *miniov2.Tenant) metav1.ObjectMeta {
 	meta := metav1.ObjectMeta{}
 	meta.Labels = t.Spec.Console.Labels
-	meta.Annotations = t.Spec.Console.Annotations
+	meta.Annotations = make(map[string]string)
+	for k, v := range t.Spec.Console.Annotations {
+		meta.Annotations[k] = v
+	}
+	// restart the pods with the renewed certificates
+	meta.Annotations[miniov2.Revision] = fmt.Sprintf("%d", t.Status.Revision)
 
 	if meta.Labels == nil {
 		meta.Labels = make(map[string]string)
@@ -150,7 +156,7 @@ func consoleContainer(t *miniov2.Tenant, oldConsole bool) corev1.Container {
 
 	return corev1.Container{
 		Name:  miniov2.ConsoleContainerName,
-		Image: t.Spec.Console.Image,
+		Image: miniov2.RewriteImage(t.Spec.Console.Image),
 		Ports: []corev1.ContainerPort{
 			{
 				Name:          "http",
@@ -167,6 +173,25 @@ func consoleContainer(t *miniov2.Tenant, oldConsole bool) corev1.Container {
 		EnvFrom:         consoleSecretEnvVars(t),
 		Resources:       t.Spec.Console.Resources,
 		VolumeMounts:    ConsoleVolumeMounts(t, oldConsole),
+		LivenessProbe:   consoleProbe(),
+		ReadinessProbe:  consoleProbe(),
+	}
+}
+
+// consoleProbe returns an HTTP probe of the Console UI, always served over plain HTTP on its http port
+func consoleProbe() *corev1.Probe {
+	return &corev1.Probe{
+		Handler: corev1.Handler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path:   "/",
+				Port:   intstr.FromInt(miniov2.ConsolePort),
+				Scheme: corev1.URISchemeHTTP,
+			},
+		},
+		TimeoutSeconds:   miniov2.MinIOProbeTimeoutSeconds,
+		PeriodSeconds:    miniov2.MinIOProbePeriodSeconds,
+		SuccessThreshold: 1,
+		FailureThreshold: miniov2.MinIOReadinessFailureThreshold,
 	}
 }
 
@@ -213,9 +238,7 @@ func NewConsole(t *miniov2.Tenant) *appsv1.Deployment {
 	}
 
 	var tenantCertPath = "CAs/minio.crt"
-	var tenantCertPaths = []corev1.KeyToPath{
-		{Key: "public.crt", Path: tenantCertPath},
-	}
+	var tenantCertPaths []corev1.KeyToPath
 	// External certificates will have priority over AutoCert generated certificates
 	// In the future this may change when Console supports SNI
 	if t.ConsoleExternalCert() {
@@ -238,12 +261,16 @@ func NewConsole(t *miniov2.Tenant) *appsv1.Deployment {
 		})
 	} else if t.AutoCert() {
 		// Console certificates generated by AutoCert
+		certKey, keyKey := t.AutoCertKeys()
 		podVolumeSources = append(podVolumeSources, corev1.VolumeProjection{
 			Secret: &corev1.SecretProjection{
 				LocalObjectReference: corev1.LocalObjectReference{
 					Name: t.ConsoleTLSSecretName(),
 				},
-				Items: serverCertPaths,
+				Items: []corev1.KeyToPath{
+					{Key: certKey, Path: certPath},
+					{Key: keyKey, Path: keyPath},
+				},
 			},
 		})
 	}
@@ -251,12 +278,15 @@ func NewConsole(t *miniov2.Tenant) *appsv1.Deployment {
 	// If MinIO has AutoCert enabled load the autogenerated certificate into certs/CAS/minio.crt
 	if t.AutoCert() {
 		// MinIO tenant certificate generated by AutoCert
+		certKey, _ := t.AutoCertKeys()
 		podVolumeSources = append(podVolumeSources, corev1.VolumeProjection{
 			Secret: &corev1.SecretProjection{
 				LocalObjectReference: corev1.LocalObjectReference{
 					Name: t.MinIOTLSSecretName(),
 				},
-				Items: tenantCertPaths,
+				Items: []corev1.KeyToPath{
+					{Key: certKey, Path: tenantCertPath},
+				},
 			},
 		})
 	}
@@ -24,6 +24,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // Adds required log-search-api environment variables
@@ -80,7 +81,7 @@ func logSearchAPIContainer(t *miniov2.Tenant) corev1.Container {
 	}
 	container := corev1.Container{
 		Name:  miniov2.LogSearchAPIContainerName,
-		Image: logSearchAPIImage,
+		Image: miniov2.RewriteImage(logSearchAPIImage),
 		Ports: []corev1.ContainerPort{
 			{
 				ContainerPort: miniov2.LogSearchAPIPort,
@@ -89,11 +90,28 @@ func logSearchAPIContainer(t *miniov2.Tenant) corev1.Container {
 		ImagePullPolicy: t.Spec.ImagePullPolicy,
 		Env:             logSearchAPIEnvVars(t),
 		Resources:       t.Spec.Log.Resources,
+		LivenessProbe:   logSearchAPIProbe(),
+		ReadinessProbe:  logSearchAPIProbe(),
 	}
 
 	return container
 }
 
+// logSearchAPIProbe returns a TCP probe of the Log Search API port, its HTTP endpoints all require authentication
+func logSearchAPIProbe() *corev1.Probe {
+	return &corev1.Probe{
+		Handler: corev1.Handler{
+			TCPSocket: &corev1.TCPSocketAction{
+				Port: intstr.FromInt(miniov2.LogSearchAPIPort),
+			},
+		},
+		TimeoutSeconds:   miniov2.MinIOProbeTimeoutSeconds,
+		PeriodSeconds:    miniov2.MinIOProbePeriodSeconds,
+		SuccessThreshold: 1,
+		FailureThreshold: miniov2.MinIOReadinessFailureThreshold,
+	}
+}
+
 func logSearchAPIMeta(t *miniov2.Tenant) metav1.ObjectMeta {
 	meta := metav1.ObjectMeta{}
 	meta.Labels = make(map[string]string)
@@ -0,0 +1,100 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+package ingresses
+
+import (
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	"github.com/minio/operator/pkg/resources/services"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newIngress returns an Ingress routing the given hosts to the port of the service
+func newIngress(t *miniov2.Tenant, name, serviceName string, port int32, hosts []string) *networkingv1.Ingress {
+	config := t.Spec.ExposeServices.Ingress
+	pathType := networkingv1.PathTypePrefix
+	backend := networkingv1.IngressBackend{
+		Service: &networkingv1.IngressServiceBackend{
+			Name: serviceName,
+			Port: networkingv1.ServiceBackendPort{Number: port},
+		},
+	}
+
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       t.Namespace,
+			OwnerReferences: t.OwnerRef(),
+			Labels: map[string]string{
+				miniov2.TenantLabel: t.Name,
+			},
+			Annotations: config.Annotations,
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: config.IngressClassName,
+		},
+	}
+	for _, host := range hosts {
+		ingress.Spec.Rules = append(ingress.Spec.Rules, networkingv1.IngressRule{
+			Host: host,
+			IngressRuleValue: networkingv1.IngressRuleValue{
+				HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{
+						{
+							Path:     "/",
+							PathType: &pathType,
+							Backend:  backend,
+						},
+					},
+				},
+			},
+		})
+	}
+	if config.TLSSecret != "" {
+		ingress.Spec.TLS = []networkingv1.IngressTLS{
+			{
+				Hosts:      hosts,
+				SecretName: config.TLSSecret,
+			},
+		}
+	}
+	return ingress
+}
+
+// NewForMinIO returns the Ingress exposing the MinIO service of the tenant, along with the
+// wildcard host of the buckets when Bucket DNS is enabled. Returns nil if it has no hostname.
+func NewForMinIO(t *miniov2.Tenant) *networkingv1.Ingress {
+	if !t.HasIngressEnabled() || t.Spec.ExposeServices.Ingress.MinIOHost == "" {
+		return nil
+	}
+	host := t.Spec.ExposeServices.Ingress.MinIOHost
+	hosts := []string{host}
+	if t.S3BucketDNS() {
+		hosts = append(hosts, "*."+host)
+	}
+	return newIngress(t, t.MinIOExternalName(), t.MinIOCIServiceName(), services.MinIOServicePort(t), hosts)
+}
+
+// NewForConsole returns the Ingress exposing the Console service of the tenant. Returns nil if
+// it has no hostname.
+func NewForConsole(t *miniov2.Tenant) *networkingv1.Ingress {
+	if !t.HasIngressEnabled() || !t.HasConsoleEnabled() || t.Spec.ExposeServices.Ingress.ConsoleHost == "" {
+		return nil
+	}
+	return newIngress(t, t.ConsoleExternalName(), t.ConsoleCIServiceName(), services.ConsoleServicePort(t),
+		[]string{t.Spec.ExposeServices.Ingress.ConsoleHost})
+}
@@ -0,0 +1,93 @@
+package ingresses
+
+import (
+	"testing"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestTenant(ingress *miniov2.IngressConfig) *miniov2.Tenant {
+	return &miniov2.Tenant{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "tenant-a",
+			Namespace: "ns-a",
+		},
+		Spec: miniov2.TenantSpec{
+			Console:        &miniov2.ConsoleConfiguration{},
+			ExposeServices: &miniov2.ExposeServices{Ingress: ingress},
+		},
+	}
+}
+
+// TestNewForMinIO verifies the MinIO Ingress routes its host, and the wildcard host of the buckets with Bucket DNS
+func TestNewForMinIO(t *testing.T) {
+	if NewForMinIO(newTestTenant(nil)) != nil {
+		t.Error("NewForMinIO() created an Ingress without spec.exposeServices.ingress")
+	}
+	if NewForMinIO(newTestTenant(&miniov2.IngressConfig{ConsoleHost: "console.example.com"})) != nil {
+		t.Error("NewForMinIO() created an Ingress without minioHost")
+	}
+
+	tenant := newTestTenant(&miniov2.IngressConfig{
+		MinIOHost:   "minio.example.com",
+		TLSSecret:   "minio-tls",
+		Annotations: map[string]string{"nginx.ingress.kubernetes.io/backend-protocol": "HTTPS"},
+	})
+	ingress := NewForMinIO(tenant)
+	if ingress == nil {
+		t.Fatal("NewForMinIO() = nil, want an Ingress")
+	}
+	if ingress.Name != tenant.MinIOExternalName() || ingress.Namespace != "ns-a" || !metav1.IsControlledBy(ingress, tenant) {
+		t.Errorf("NewForMinIO() = %s/%s, want %s/ns-a owned by the tenant", ingress.Namespace, ingress.Name, tenant.MinIOExternalName())
+	}
+	if ingress.Annotations["nginx.ingress.kubernetes.io/backend-protocol"] != "HTTPS" {
+		t.Errorf("NewForMinIO() annotations = %v", ingress.Annotations)
+	}
+	if len(ingress.Spec.Rules) != 1 || ingress.Spec.Rules[0].Host != "minio.example.com" {
+		t.Fatalf("NewForMinIO() rules = %v, want minio.example.com", ingress.Spec.Rules)
+	}
+	if backend := ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service; backend.Name != tenant.MinIOCIServiceName() {
+		t.Errorf("NewForMinIO() backend = %s, want %s", backend.Name, tenant.MinIOCIServiceName())
+	}
+	if len(ingress.Spec.TLS) != 1 || ingress.Spec.TLS[0].SecretName != "minio-tls" {
+		t.Errorf("NewForMinIO() TLS = %v, want minio-tls", ingress.Spec.TLS)
+	}
+
+	tenant.Spec.S3 = &miniov2.S3Features{BucketDNS: true}
+	ingress = NewForMinIO(tenant)
+	if len(ingress.Spec.Rules) != 2 || ingress.Spec.Rules[1].Host != "*.minio.example.com" {
+		t.Errorf("NewForMinIO() rules = %v, want the wildcard host of the buckets", ingress.Spec.Rules)
+	}
+	if hosts := ingress.Spec.TLS[0].Hosts; len(hosts) != 2 {
+		t.Errorf("NewForMinIO() TLS hosts = %v, want both hosts", hosts)
+	}
+}
+
+// TestNewForConsole verifies the Console Ingress is only created for tenants with a Console and a console host
+func TestNewForConsole(t *testing.T) {
+	tenant := newTestTenant(&miniov2.IngressConfig{MinIOHost: "minio.example.com"})
+	if NewForConsole(tenant) != nil {
+		t.Error("NewForConsole() created an Ingress without consoleHost")
+	}
+
+	tenant.Spec.ExposeServices.Ingress.ConsoleHost = "console.example.com"
+	ingress := NewForConsole(tenant)
+	if ingress == nil {
+		t.Fatal("NewForConsole() = nil, want an Ingress")
+	}
+	if ingress.Name != tenant.ConsoleExternalName() || len(ingress.Spec.Rules) != 1 || ingress.Spec.Rules[0].Host != "console.example.com" {
+		t.Errorf("NewForConsole() = %s with rules %v", ingress.Name, ingress.Spec.Rules)
+	}
+	if backend := ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service; backend.Name != tenant.ConsoleCIServiceName() {
+		t.Errorf("NewForConsole() backend = %s, want %s", backend.Name, tenant.ConsoleCIServiceName())
+	}
+	if ingress.Spec.TLS != nil {
+		t.Errorf("NewForConsole() TLS = %v without tlsSecret", ingress.Spec.TLS)
+	}
+
+	tenant.Spec.Console = nil
+	if NewForConsole(tenant) != nil {
+		t.Error("NewForConsole() created an Ingress for a tenant without Console")
+	}
+}
@@ -47,6 +47,11 @@ func NewForKES(t *miniov2.Tenant) *batchv1.Job {
 		}
 	} else {
 		clientCertSecret = t.MinIOClientTLSSecretName()
+		certKey, keyKey := t.AutoCertKeys()
+		clientCertPaths = []corev1.KeyToPath{
+			{Key: certKey, Path: "minio.crt"},
+			{Key: keyKey, Path: "minio.key"},
+		}
 	}
 
 	podVolumes := []corev1.Volume{
@@ -103,7 +108,7 @@ func kesJobContainer(t *miniov2.Tenant) corev1.Container {
 
 	return corev1.Container{
 		Name:            miniov2.KESContainerName,
-		Image:           t.Spec.KES.Image,
+		Image:           miniov2.RewriteImage(t.Spec.KES.Image),
 		ImagePullPolicy: t.Spec.KES.ImagePullPolicy,
 		Args:            args,
 		Env:             kesEnvironmentVars(t),
@@ -141,7 +146,7 @@ func kesMetadata(t *miniov2.Tenant) metav1.ObjectMeta {
 		meta.Labels = make(map[string]string)
 	}
 	// Add the additional label from spec
-	for k, v := range t.KESPodLabels() {
+	for k, v := range t.KESJobPodLabels() {
 		meta.Labels[k] = v
 	}
 	return meta
@@ -0,0 +1,153 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+package networkpolicies
+
+import (
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// namespaceNameLabel is set by Kubernetes on every namespace with its name
+const namespaceNameLabel = "kubernetes.io/metadata.name"
+
+// podsPeer selects the pods of the tenant namespace with the given labels
+func podsPeer(labels map[string]string) networkingv1.NetworkPolicyPeer {
+	return networkingv1.NetworkPolicyPeer{
+		PodSelector: &metav1.LabelSelector{MatchLabels: labels},
+	}
+}
+
+// namespacePeer selects every pod of the given namespace
+func namespacePeer(namespace string) networkingv1.NetworkPolicyPeer {
+	return networkingv1.NetworkPolicyPeer{
+		NamespaceSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{namespaceNameLabel: namespace},
+		},
+	}
+}
+
+// clientPeers returns the namespaces and IP ranges allowed to connect to the MinIO and Console services
+func clientPeers(t *miniov2.Tenant) []networkingv1.NetworkPolicyPeer {
+	var peers []networkingv1.NetworkPolicyPeer
+	for _, namespace := range t.Spec.NetworkPolicy.AllowedNamespaces {
+		peers = append(peers, namespacePeer(namespace))
+	}
+	for _, cidr := range t.Spec.NetworkPolicy.AllowedCIDRs {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{
+			IPBlock: &networkingv1.IPBlock{CIDR: cidr},
+		})
+	}
+	return peers
+}
+
+// newNetworkPolicy returns a NetworkPolicy only allowing the given peers to connect to the ports of
+// the selected pods
+func newNetworkPolicy(t *miniov2.Tenant, name string, podLabels map[string]string, ports []int, peers []networkingv1.NetworkPolicyPeer) *networkingv1.NetworkPolicy {
+	protocol := corev1.ProtocolTCP
+	var policyPorts []networkingv1.NetworkPolicyPort
+	for _, port := range ports {
+		port := intstr.FromInt(port)
+		policyPorts = append(policyPorts, networkingv1.NetworkPolicyPort{
+			Protocol: &protocol,
+			Port:     &port,
+		})
+	}
+	// A rule without peers would allow any, without rules every connection is denied
+	var rules []networkingv1.NetworkPolicyIngressRule
+	if len(peers) > 0 {
+		rules = []networkingv1.NetworkPolicyIngressRule{
+			{
+				Ports: policyPorts,
+				From:  peers,
+			},
+		}
+	}
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       t.Namespace,
+			OwnerReferences: t.OwnerRef(),
+			Labels: map[string]string{
+				miniov2.TenantLabel: t.Name,
+			},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: podLabels},
+			Ingress:     rules,
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+}
+
+// NewForTenant returns the NetworkPolicies isolating the pods of every component the tenant deploys.
+// operatorNamespace is the namespace of the Operator, which connects to MinIO and the Log Search API.
+func NewForTenant(t *miniov2.Tenant, operatorNamespace string) []*networkingv1.NetworkPolicy {
+	// MinIO servers connect to each other through the headless service
+	minioPeers := []networkingv1.NetworkPolicyPeer{
+		podsPeer(t.MinIOPodLabels()),
+		namespacePeer(operatorNamespace),
+	}
+	if t.HasConsoleEnabled() {
+		minioPeers = append(minioPeers, podsPeer(t.ConsolePodLabels()))
+	}
+	if t.HasPrometheusEnabled() {
+		minioPeers = append(minioPeers, podsPeer(t.PrometheusPodLabels()))
+	}
+	if t.HasPrometheusSMEnabled() {
+		for _, namespace := range t.Spec.NetworkPolicy.PrometheusNamespaces {
+			minioPeers = append(minioPeers, namespacePeer(namespace))
+		}
+	}
+	minioPeers = append(minioPeers, clientPeers(t)...)
+	policies := []*networkingv1.NetworkPolicy{
+		newNetworkPolicy(t, t.Name+"-"+miniov2.MinIOServerName, t.MinIOPodLabels(), []int{miniov2.MinIOPort}, minioPeers),
+	}
+
+	if t.HasConsoleEnabled() {
+		policies = append(policies, newNetworkPolicy(t, t.ConsoleDeploymentName(), t.ConsolePodLabels(),
+			[]int{miniov2.ConsolePort, miniov2.ConsoleTLSPort}, clientPeers(t)))
+	}
+	if t.HasKESEnabled() {
+		policies = append(policies, newNetworkPolicy(t, t.KESStatefulSetName(), t.KESPodLabels(),
+			[]int{miniov2.KESPort}, []networkingv1.NetworkPolicyPeer{podsPeer(t.MinIOPodLabels()), podsPeer(t.KESJobPodLabels())}))
+	}
+	if t.HasLogEnabled() {
+		policies = append(policies, newNetworkPolicy(t, t.LogStatefulsetName(), t.LogPgPodLabels(),
+			[]int{miniov2.LogPgPort}, []networkingv1.NetworkPolicyPeer{podsPeer(t.LogSearchAPIPodLabels())}))
+		logSearchAPIPeers := []networkingv1.NetworkPolicyPeer{
+			podsPeer(t.MinIOPodLabels()),
+			namespacePeer(operatorNamespace),
+		}
+		if t.HasConsoleEnabled() {
+			logSearchAPIPeers = append(logSearchAPIPeers, podsPeer(t.ConsolePodLabels()))
+		}
+		policies = append(policies, newNetworkPolicy(t, t.LogSearchAPIDeploymentName(), t.LogSearchAPIPodLabels(),
+			[]int{miniov2.LogSearchAPIPort}, logSearchAPIPeers))
+	}
+	if t.HasPrometheusEnabled() {
+		var prometheusPeers []networkingv1.NetworkPolicyPeer
+		if t.HasConsoleEnabled() {
+			prometheusPeers = append(prometheusPeers, podsPeer(t.ConsolePodLabels()))
+		}
+		policies = append(policies, newNetworkPolicy(t, t.PrometheusStatefulsetName(), t.PrometheusPodLabels(),
+			[]int{miniov2.PrometheusPort}, prometheusPeers))
+	}
+	return policies
+}
@@ -0,0 +1,52 @@
+package networkpolicies
+
+import (
+	"testing"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestNewForTenant verifies every component gets a policy and that components without allowed peers deny everything
+func TestNewForTenant(t *testing.T) {
+	tenant := &miniov2.Tenant{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "tenant-a",
+			Namespace: "ns-a",
+		},
+		Spec: miniov2.TenantSpec{
+			Console:       &miniov2.ConsoleConfiguration{},
+			KES:           &miniov2.KESConfig{},
+			Prometheus:    &miniov2.PrometheusConfig{},
+			NetworkPolicy: &miniov2.NetworkPolicyConfig{},
+		},
+	}
+
+	policies := NewForTenant(tenant, "minio-operator")
+	byName := make(map[string]int)
+	for _, policy := range policies {
+		byName[policy.Name] = len(policy.Spec.Ingress)
+	}
+	want := map[string]int{
+		"tenant-a-minio":      1,
+		"tenant-a-console":    0,
+		"tenant-a-kes":        1,
+		"tenant-a-prometheus": 1,
+	}
+	if len(byName) != len(want) {
+		t.Fatalf("NewForTenant() created %v, want %v", byName, want)
+	}
+	for name, rules := range want {
+		if got, ok := byName[name]; !ok || got != rules {
+			t.Errorf("NewForTenant() policy %s has %d rules, want %d", name, got, rules)
+		}
+	}
+
+	// MinIO accepts the allowed clients on top of the tenant pods and the Operator
+	tenant.Spec.NetworkPolicy.AllowedNamespaces = []string{"ingress-nginx"}
+	tenant.Spec.NetworkPolicy.AllowedCIDRs = []string{"10.0.0.0/8"}
+	minio := NewForTenant(tenant, "minio-operator")[0]
+	if peers := len(minio.Spec.Ingress[0].From); peers != 6 {
+		t.Errorf("NewForTenant() MinIO policy has %d peers, want 6", peers)
+	}
+}
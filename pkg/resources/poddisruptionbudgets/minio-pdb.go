@@ -0,0 +1,46 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+package poddisruptionbudgets
+
+import (
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	"github.com/minio/operator/pkg/resources/statefulsets"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// NewForPool returns the PodDisruptionBudget of the pool with the given statefulset, allowing the eviction
+// of maxUnavailable of its pods at a time
+func NewForPool(t *miniov2.Tenant, pool *miniov2.Pool, ssName string, maxUnavailable int32) *policyv1beta1.PodDisruptionBudget {
+	maxUnavailableServers := intstr.FromInt(int(maxUnavailable))
+	return &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            ssName,
+			Namespace:       t.Namespace,
+			OwnerReferences: t.OwnerRef(),
+			Labels: map[string]string{
+				miniov2.TenantLabel: t.Name,
+				miniov2.PoolLabel:   pool.Name,
+			},
+		},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			MaxUnavailable: &maxUnavailableServers,
+			Selector:       statefulsets.ContainerMatchLabels(t, pool),
+		},
+	}
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+package routes
+
+import (
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	"github.com/minio/operator/pkg/resources/services"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// The Gateway API types aren't vendored, routes are handled as unstructured objects
+var (
+	// HTTPRouteGVR is the resource of the HTTPRoutes terminating TLS on the Gateway
+	HTTPRouteGVR = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"}
+	// TLSRouteGVR is the resource of the TLSRoutes passing TLS through to the services
+	TLSRouteGVR = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1alpha2", Resource: "tlsroutes"}
+)
+
+// GVR returns the resource of the routes created for the tenant
+func GVR(t *miniov2.Tenant) schema.GroupVersionResource {
+	if t.Spec.ExposeServices.Gateway.TLSPassthrough {
+		return TLSRouteGVR
+	}
+	return HTTPRouteGVR
+}
+
+// newRoute returns a route attaching the given hosts to the port of the service
+func newRoute(t *miniov2.Tenant, name, serviceName string, port int32, hosts []string) *unstructured.Unstructured {
+	config := t.Spec.ExposeServices.Gateway
+	gvr := GVR(t)
+	kind := "HTTPRoute"
+	if gvr == TLSRouteGVR {
+		kind = "TLSRoute"
+	}
+
+	parentRef := map[string]interface{}{
+		"name": config.ParentRef.Name,
+	}
+	if config.ParentRef.Namespace != "" {
+		parentRef["namespace"] = config.ParentRef.Namespace
+	}
+	if config.ParentRef.SectionName != "" {
+		parentRef["sectionName"] = config.ParentRef.SectionName
+	}
+	hostnames := make([]interface{}, 0, len(hosts))
+	for _, host := range hosts {
+		hostnames = append(hostnames, host)
+	}
+
+	route := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"parentRefs": []interface{}{parentRef},
+				"hostnames":  hostnames,
+				"rules": []interface{}{
+					map[string]interface{}{
+						"backendRefs": []interface{}{
+							map[string]interface{}{
+								"name": serviceName,
+								// unstructured objects only hold int64 numbers
+								"port": int64(port),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	route.SetAPIVersion(gvr.GroupVersion().String())
+	route.SetKind(kind)
+	route.SetName(name)
+	route.SetNamespace(t.Namespace)
+	route.SetOwnerReferences(t.OwnerRef())
+	route.SetLabels(map[string]string{
+		miniov2.TenantLabel: t.Name,
+	})
+	route.SetAnnotations(config.Annotations)
+	return route
+}
+
+// NewForMinIO returns the route exposing the MinIO service of the tenant, along with the
+// wildcard host of the buckets when Bucket DNS is enabled. Returns nil if it has no hostname.
+func NewForMinIO(t *miniov2.Tenant) *unstructured.Unstructured {
+	if !t.HasGatewayEnabled() || t.Spec.ExposeServices.Gateway.MinIOHost == "" {
+		return nil
+	}
+	host := t.Spec.ExposeServices.Gateway.MinIOHost
+	hosts := []string{host}
+	if t.S3BucketDNS() {
+		hosts = append(hosts, "*."+host)
+	}
+	return newRoute(t, t.MinIOExternalName(), t.MinIOCIServiceName(), services.MinIOServicePort(t), hosts)
+}
+
+// NewForConsole returns the route exposing the Console service of the tenant. Returns nil if
+// it has no hostname.
+func NewForConsole(t *miniov2.Tenant) *unstructured.Unstructured {
+	if !t.HasGatewayEnabled() || !t.HasConsoleEnabled() || t.Spec.ExposeServices.Gateway.ConsoleHost == "" {
+		return nil
+	}
+	return newRoute(t, t.ConsoleExternalName(), t.ConsoleCIServiceName(), services.ConsoleServicePort(t),
+		[]string{t.Spec.ExposeServices.Gateway.ConsoleHost})
+}
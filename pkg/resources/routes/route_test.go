@@ -0,0 +1,95 @@
+package routes
+
+import (
+	"reflect"
+	"testing"
+
+	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newTestTenant(gateway *miniov2.GatewayConfig) *miniov2.Tenant {
+	return &miniov2.Tenant{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "tenant-a",
+			Namespace: "ns-a",
+		},
+		Spec: miniov2.TenantSpec{
+			Console:        &miniov2.ConsoleConfiguration{},
+			ExposeServices: &miniov2.ExposeServices{Gateway: gateway},
+		},
+	}
+}
+
+// TestNewForMinIO verifies the MinIO route attaches its hosts to the Gateway, as a TLSRoute with TLS passthrough
+func TestNewForMinIO(t *testing.T) {
+	if NewForMinIO(newTestTenant(nil)) != nil {
+		t.Error("NewForMinIO() created a route without spec.exposeServices.gateway")
+	}
+
+	tenant := newTestTenant(&miniov2.GatewayConfig{
+		ParentRef:   miniov2.GatewayParentReference{Name: "gateway", Namespace: "gateway-ns", SectionName: "https"},
+		MinIOHost:   "minio.example.com",
+		Annotations: map[string]string{"example.com/annotation": "value"},
+	})
+	tenant.Spec.S3 = &miniov2.S3Features{BucketDNS: true}
+	route := NewForMinIO(tenant)
+	if route == nil {
+		t.Fatal("NewForMinIO() = nil, want a route")
+	}
+	if route.GetKind() != "HTTPRoute" || route.GetAPIVersion() != HTTPRouteGVR.GroupVersion().String() {
+		t.Errorf("NewForMinIO() = %s %s, want an HTTPRoute", route.GetAPIVersion(), route.GetKind())
+	}
+	if route.GetName() != tenant.MinIOExternalName() || route.GetNamespace() != "ns-a" || !metav1.IsControlledBy(route, tenant) {
+		t.Errorf("NewForMinIO() = %s/%s, want %s/ns-a owned by the tenant", route.GetNamespace(), route.GetName(), tenant.MinIOExternalName())
+	}
+	if route.GetAnnotations()["example.com/annotation"] != "value" {
+		t.Errorf("NewForMinIO() annotations = %v", route.GetAnnotations())
+	}
+	hosts, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+	if len(hosts) != 2 || hosts[0] != "minio.example.com" || hosts[1] != "*.minio.example.com" {
+		t.Errorf("NewForMinIO() hostnames = %v, want the host and the wildcard host of the buckets", hosts)
+	}
+	parentRefs, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	if want := map[string]interface{}{"name": "gateway", "namespace": "gateway-ns", "sectionName": "https"}; len(parentRefs) != 1 || !reflect.DeepEqual(parentRefs[0], want) {
+		t.Errorf("NewForMinIO() parentRefs = %v, want %v", parentRefs, want)
+	}
+	rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+	backend := rules[0].(map[string]interface{})["backendRefs"].([]interface{})[0].(map[string]interface{})
+	if backend["name"] != tenant.MinIOCIServiceName() {
+		t.Errorf("NewForMinIO() backend = %v, want %s", backend["name"], tenant.MinIOCIServiceName())
+	}
+
+	tenant.Spec.ExposeServices.Gateway.TLSPassthrough = true
+	if route = NewForMinIO(tenant); route.GetKind() != "TLSRoute" || route.GetAPIVersion() != TLSRouteGVR.GroupVersion().String() {
+		t.Errorf("NewForMinIO() = %s %s, want a TLSRoute", route.GetAPIVersion(), route.GetKind())
+	}
+}
+
+// TestNewForConsole verifies the Console route is only created for tenants with a Console and a console host
+func TestNewForConsole(t *testing.T) {
+	tenant := newTestTenant(&miniov2.GatewayConfig{ParentRef: miniov2.GatewayParentReference{Name: "gateway"}, MinIOHost: "minio.example.com"})
+	if NewForConsole(tenant) != nil {
+		t.Error("NewForConsole() created a route without consoleHost")
+	}
+
+	tenant.Spec.ExposeServices.Gateway.ConsoleHost = "console.example.com"
+	route := NewForConsole(tenant)
+	if route == nil {
+		t.Fatal("NewForConsole() = nil, want a route")
+	}
+	hosts, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+	if route.GetName() != tenant.ConsoleExternalName() || len(hosts) != 1 || hosts[0] != "console.example.com" {
+		t.Errorf("NewForConsole() = %s with hostnames %v", route.GetName(), hosts)
+	}
+	parentRefs, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	if want := map[string]interface{}{"name": "gateway"}; len(parentRefs) != 1 || !reflect.DeepEqual(parentRefs[0], want) {
+		t.Errorf("NewForConsole() parentRefs = %v, want %v", parentRefs, want)
+	}
+
+	tenant.Spec.Console = nil
+	if NewForConsole(tenant) != nil {
+		t.Error("NewForConsole() created a route for a tenant without Console")
+	}
+}
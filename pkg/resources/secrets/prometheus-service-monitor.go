@@ -18,6 +18,9 @@
 package secrets
 
 import (
+	"fmt"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
 	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -37,3 +40,22 @@ func PromServiceMonitorSecret(t *miniov2.Tenant, accessKey, secretKey string) *c
 		},
 	}
 }
+
+// UpdatePromServiceMonitorSecret checks if the Prometheus token of the secret can still be verified with
+// the given secret key and if not returns the updated secret. Otherwise it returns nil.
+func UpdatePromServiceMonitorSecret(t *miniov2.Tenant, accessKey, secretKey string, existing *corev1.Secret) *corev1.Secret {
+	claims := &jwtgo.StandardClaims{}
+	_, err := jwtgo.ParseWithClaims(string(existing.Data[miniov2.PrometheusServiceMonitorSecretKey]), claims, func(token *jwtgo.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwtgo.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(secretKey), nil
+	})
+	if err == nil && claims.Subject == accessKey {
+		return nil
+	}
+
+	secret := existing.DeepCopy()
+	secret.Data = PromServiceMonitorSecret(t, accessKey, secretKey).Data
+	return secret
+}
@@ -27,6 +27,22 @@ import (
 	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
 )
 
+// MinIOServicePort returns the port of the MinIO ClusterIP service
+func MinIOServicePort(t *miniov2.Tenant) int32 {
+	if t.TLS() {
+		return miniov2.MinIOTLSPortLoadBalancerSVC
+	}
+	return miniov2.MinIOPortLoadBalancerSVC
+}
+
+// ConsoleServicePort returns the port of the Console ClusterIP service
+func ConsoleServicePort(t *miniov2.Tenant) int32 {
+	if t.TLS() || t.ConsoleExternalCert() {
+		return miniov2.ConsoleTLSPort
+	}
+	return miniov2.ConsolePort
+}
+
 // NewClusterIPForMinIO will return a new ClusterIP Kubernetes service for a Tenant
 func NewClusterIPForMinIO(t *miniov2.Tenant) *corev1.Service {
 	var port int32 = miniov2.MinIOPortLoadBalancerSVC
@@ -119,6 +135,8 @@ func NewHeadlessForMinIO(t *miniov2.Tenant) *corev1.Service {
 			Selector:  t.MinIOPodLabels(),
 			Type:      corev1.ServiceTypeClusterIP,
 			ClusterIP: corev1.ClusterIPNone,
+			// MinIO servers must resolve each other before they pass their readiness probe
+			PublishNotReadyAddresses: true,
 		},
 	}
 
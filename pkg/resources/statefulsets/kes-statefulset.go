@@ -18,10 +18,13 @@
 package statefulsets
 
 import (
+	"fmt"
+
 	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // KESMetadata Returns the KES pods metadata set in configuration.
@@ -30,7 +33,12 @@ import (
 func KESMetadata(t *miniov2.Tenant) metav1.ObjectMeta {
 	meta := metav1.ObjectMeta{}
 	meta.Labels = t.Spec.KES.Labels
-	meta.Annotations = t.Spec.KES.Annotations
+	meta.Annotations = make(map[string]string)
+	for k, v := range t.Spec.KES.Annotations {
+		meta.Annotations[k] = v
+	}
+	// restart the pods with the renewed certificates
+	meta.Annotations[miniov2.Revision] = fmt.Sprintf("%d", t.Status.Revision)
 
 	if meta.Labels == nil {
 		meta.Labels = make(map[string]string)
@@ -77,7 +85,7 @@ func KESServerContainer(t *miniov2.Tenant) corev1.Container {
 
 	return corev1.Container{
 		Name:  miniov2.KESContainerName,
-		Image: t.Spec.KES.Image,
+		Image: miniov2.RewriteImage(t.Spec.KES.Image),
 		Ports: []corev1.ContainerPort{
 			{
 				ContainerPort: miniov2.KESPort,
@@ -87,6 +95,23 @@ func KESServerContainer(t *miniov2.Tenant) corev1.Container {
 		VolumeMounts:    KESVolumeMounts(t),
 		Args:            args,
 		Env:             KESEnvironmentVars(t),
+		LivenessProbe:   kesProbe(),
+		ReadinessProbe:  kesProbe(),
+	}
+}
+
+// kesProbe returns a TCP probe of the KES port, KES requires mTLS so its HTTP endpoints can't be probed
+func kesProbe() *corev1.Probe {
+	return &corev1.Probe{
+		Handler: corev1.Handler{
+			TCPSocket: &corev1.TCPSocketAction{
+				Port: intstr.FromInt(miniov2.KESPort),
+			},
+		},
+		TimeoutSeconds:   miniov2.MinIOProbeTimeoutSeconds,
+		PeriodSeconds:    miniov2.MinIOProbePeriodSeconds,
+		SuccessThreshold: 1,
+		FailureThreshold: miniov2.MinIOReadinessFailureThreshold,
 	}
 }
 
@@ -126,6 +151,11 @@ func NewForKES(t *miniov2.Tenant, serviceName string) *appsv1.StatefulSet {
 		}
 	} else {
 		serverCertSecret = t.KESTLSSecretName()
+		certKey, keyKey := t.AutoCertKeys()
+		serverCertPaths = []corev1.KeyToPath{
+			{Key: certKey, Path: certPath},
+			{Key: keyKey, Path: keyPath},
+		}
 	}
 
 	if t.KESClientCert() {
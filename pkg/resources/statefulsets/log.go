@@ -100,7 +100,7 @@ func logVolumeMounts(t *miniov2.Tenant) []corev1.VolumeMount {
 func logDbContainer(t *miniov2.Tenant) corev1.Container {
 	container := corev1.Container{
 		Name:  miniov2.LogPgContainerName,
-		Image: miniov2.LogPgImage,
+		Image: miniov2.RewriteImage(miniov2.LogPgImage),
 		Ports: []corev1.ContainerPort{
 			{
 				ContainerPort: miniov2.LogPgPort,
@@ -114,7 +114,7 @@ func logDbContainer(t *miniov2.Tenant) corev1.Container {
 	if t.Spec.Log.Db != nil {
 		// if an image was specified, use it.
 		if t.Spec.Log.Db.Image != "" {
-			container.Image = t.Spec.Log.Db.Image
+			container.Image = miniov2.RewriteImage(t.Spec.Log.Db.Image)
 		}
 		// resources constraints
 		container.Resources = t.Spec.Log.Db.Resources
@@ -28,6 +28,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // Returns the MinIO environment variables set in configuration.
@@ -47,13 +48,13 @@ func minioEnvironmentVars(t *miniov2.Tenant, wsSecret *v1.Secret, hostsTemplate
 			Value: "on",
 		}, corev1.EnvVar{
 			Name:  "MINIO_UPDATE_MINISIGN_PUBKEY",
-			Value: "RWTx5Zr1tiHQLwG9keckT0c45M3AGeHD6IvimQHpyRywVWGbP1aVSGav",
+			Value: miniov2.GetUpdateSources().MinisignPubKey,
 		}, corev1.EnvVar{
 			Name: miniov2.WebhookMinIOArgs,
 			ValueFrom: &corev1.EnvVarSource{
 				SecretKeyRef: &corev1.SecretKeySelector{
 					LocalObjectReference: corev1.LocalObjectReference{
-						Name: miniov2.WebhookSecret,
+						Name: t.WebhookSecretName(),
 					},
 					Key: miniov2.WebhookMinIOArgs,
 				},
@@ -71,13 +72,13 @@ func minioEnvironmentVars(t *miniov2.Tenant, wsSecret *v1.Secret, hostsTemplate
 	if t.S3BucketDNS() {
 		envVars = append(envVars, corev1.EnvVar{
 			Name:  "MINIO_DOMAIN",
-			Value: t.MinIOBucketBaseDomain(),
+			Value: t.MinIODomain(),
 		}, corev1.EnvVar{
 			Name: miniov2.WebhookMinIOBucket,
 			ValueFrom: &corev1.EnvVarSource{
 				SecretKeyRef: &corev1.SecretKeySelector{
 					LocalObjectReference: corev1.LocalObjectReference{
-						Name: miniov2.WebhookSecret,
+						Name: t.WebhookSecretName(),
 					},
 					Key: miniov2.WebhookMinIOArgs,
 				},
@@ -205,6 +206,59 @@ func volumeMounts(t *miniov2.Tenant, pool *miniov2.Pool) (mounts []corev1.Volume
 	return mounts
 }
 
+// minioProbe returns an HTTP probe of the given MinIO health endpoint
+func minioProbe(t *miniov2.Tenant, path string, failureThreshold int32) *corev1.Probe {
+	scheme := corev1.URISchemeHTTP
+	if t.TLS() {
+		scheme = corev1.URISchemeHTTPS
+	}
+	return &corev1.Probe{
+		Handler: corev1.Handler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path:   path,
+				Port:   intstr.FromInt(miniov2.MinIOPort),
+				Scheme: scheme,
+			},
+		},
+		TimeoutSeconds:   miniov2.MinIOProbeTimeoutSeconds,
+		PeriodSeconds:    miniov2.MinIOProbePeriodSeconds,
+		SuccessThreshold: 1,
+		FailureThreshold: failureThreshold,
+	}
+}
+
+// withDefaultHandler returns the probe requested by the user, probing the default endpoint if it
+// only tunes the timings
+func withDefaultHandler(probe, defaultProbe *corev1.Probe) *corev1.Probe {
+	if probe == nil {
+		return defaultProbe
+	}
+	probe = probe.DeepCopy()
+	if probe.Exec == nil && probe.HTTPGet == nil && probe.TCPSocket == nil {
+		probe.Handler = defaultProbe.Handler
+	}
+	return probe
+}
+
+// PoolProbes returns the liveness, readiness and startup probes of the MinIO containers of the pool,
+// the ones of the pool take precedence over the ones of the tenant
+func PoolProbes(t *miniov2.Tenant, pool *miniov2.Pool) (liveness, readiness, startup *corev1.Probe) {
+	liveness, readiness, startup = t.Spec.Liveness, t.Spec.Readiness, t.Spec.Startup
+	if pool.Liveness != nil {
+		liveness = pool.Liveness
+	}
+	if pool.Readiness != nil {
+		readiness = pool.Readiness
+	}
+	if pool.Startup != nil {
+		startup = pool.Startup
+	}
+	liveness = withDefaultHandler(liveness, minioProbe(t, miniov2.MinIOLivenessPath, miniov2.MinIOLivenessFailureThreshold))
+	readiness = withDefaultHandler(readiness, minioProbe(t, miniov2.MinIOReadinessPath, miniov2.MinIOReadinessFailureThreshold))
+	startup = withDefaultHandler(startup, minioProbe(t, miniov2.MinIOLivenessPath, miniov2.MinIOStartupFailureThreshold))
+	return liveness, readiness, startup
+}
+
 // Builds the MinIO container for a Tenant.
 func poolMinioServerContainer(t *miniov2.Tenant, wsSecret *v1.Secret, pool *miniov2.Pool, hostsTemplate string, opVersion string) corev1.Container {
 	args := []string{"server", "--certs-dir", miniov2.MinIOCertPath}
@@ -223,9 +277,10 @@ func poolMinioServerContainer(t *miniov2.Tenant, wsSecret *v1.Secret, pool *mini
 		}
 	}
 
+	liveness, readiness, startup := PoolProbes(t, pool)
 	return corev1.Container{
 		Name:  miniov2.MinIOServerName,
-		Image: t.Spec.Image,
+		Image: miniov2.RewriteImage(t.Spec.Image),
 		Ports: []corev1.ContainerPort{
 			{
 				ContainerPort: miniov2.MinIOPort,
@@ -236,6 +291,9 @@ func poolMinioServerContainer(t *miniov2.Tenant, wsSecret *v1.Secret, pool *mini
 		Args:            args,
 		Env:             minioEnvironmentVars(t, wsSecret, hostsTemplate, opVersion),
 		Resources:       pool.Resources,
+		LivenessProbe:   liveness,
+		ReadinessProbe:  readiness,
+		StartupProbe:    startup,
 	}
 }
 
@@ -246,13 +304,39 @@ func GetContainerArgs(t *miniov2.Tenant, hostsTemplate string) []string {
 		// to run in standalone mode we must pass the path
 		args = append(args, t.VolumePathForPool(&t.Spec.Pools[0]))
 	} else {
+		// decommissioned pools are skipped by the endpoints as well
+		var pools []*miniov2.Pool
+		for pi := range t.Spec.Pools {
+			if !t.PoolDecommissioned(pi) {
+				pools = append(pools, &t.Spec.Pools[pi])
+			}
+		}
 		for index, endpoint := range t.MinIOEndpoints(hostsTemplate) {
-			args = append(args, fmt.Sprintf("%s%s", endpoint, t.VolumePathForPool(&t.Spec.Pools[index])))
+			args = append(args, fmt.Sprintf("%s%s", endpoint, t.VolumePathForPool(pools[index])))
 		}
 	}
 	return args
 }
 
+// GetPoolContainerArg returns the argument MinIO receives for the pool at the given index
+// of the spec, this is how the MinIO admin API identifies a pool.
+func GetPoolContainerArg(t *miniov2.Tenant, pi int, hostsTemplate string) string {
+	if t.PoolDecommissioned(pi) {
+		return ""
+	}
+	index := 0
+	for i := 0; i < pi; i++ {
+		if !t.PoolDecommissioned(i) {
+			index++
+		}
+	}
+	args := GetContainerArgs(t, hostsTemplate)
+	if index >= len(args) {
+		return ""
+	}
+	return args[index]
+}
+
 // Builds the tolerations for a Pool.
 func minioPoolTolerations(z *miniov2.Pool) []corev1.Toleration {
 	var tolerations []corev1.Toleration
@@ -292,12 +376,17 @@ func NewPool(t *miniov2.Tenant, wsSecret *v1.Secret, pool *miniov2.Pool, service
 
 	// AutoCert certificates will be used for internal communication if requested
 	if t.AutoCert() {
+		certKey, keyKey := t.AutoCertKeys()
 		podVolumeSources = append(podVolumeSources, corev1.VolumeProjection{
 			Secret: &corev1.SecretProjection{
 				LocalObjectReference: corev1.LocalObjectReference{
 					Name: t.MinIOTLSSecretName(),
 				},
-				Items: serverCertPaths,
+				Items: []corev1.KeyToPath{
+					{Key: certKey, Path: "public.crt"},
+					{Key: keyKey, Path: "private.key"},
+					{Key: certKey, Path: "CAs/public.crt"},
+				},
 			},
 		})
 	}
@@ -427,12 +516,11 @@ func NewPool(t *miniov2.Tenant, wsSecret *v1.Secret, pool *miniov2.Pool, service
 	}
 
 	// Mount Operator TLS certificate to MinIO ~/cert/CAs
-	operatorTLSSecretName := "operator-tls"
 	podVolumeSources = append(podVolumeSources, []corev1.VolumeProjection{
 		{
 			Secret: &corev1.SecretProjection{
 				LocalObjectReference: corev1.LocalObjectReference{
-					Name: operatorTLSSecretName,
+					Name: t.OperatorTLSSecretCopyName(),
 				},
 				Items: []corev1.KeyToPath{
 					{Key: "public.crt", Path: "CAs/operator.crt"},
@@ -456,6 +544,11 @@ func NewPool(t *miniov2.Tenant, wsSecret *v1.Secret, pool *miniov2.Pool, service
 			}
 		} else {
 			clientCertSecret = t.MinIOClientTLSSecretName()
+			certKey, keyKey := t.AutoCertKeys()
+			clientCertPaths = []corev1.KeyToPath{
+				{Key: certKey, Path: "client.crt"},
+				{Key: keyKey, Path: "client.key"},
+			}
 		}
 
 		// KES External certificates will have priority over AutoCert generated certificates
@@ -470,6 +563,10 @@ func NewPool(t *miniov2.Tenant, wsSecret *v1.Secret, pool *miniov2.Pool, service
 			}
 		} else {
 			kesCertSecret = t.KESTLSSecretName()
+			certKey, _ := t.AutoCertKeys()
+			KESCertPath = []corev1.KeyToPath{
+				{Key: certKey, Path: "CAs/kes.crt"},
+			}
 		}
 
 		podVolumeSources = append(podVolumeSources, []corev1.VolumeProjection{
@@ -532,7 +629,10 @@ func NewPool(t *miniov2.Tenant, wsSecret *v1.Secret, pool *miniov2.Pool, service
 
 	// attach any sidecar containers and volumes
 	if t.Spec.SideCars != nil && len(t.Spec.SideCars.Containers) > 0 {
-		containers = append(containers, t.Spec.SideCars.Containers...)
+		for _, container := range t.Spec.SideCars.Containers {
+			container.Image = miniov2.RewriteImage(container.Image)
+			containers = append(containers, container)
+		}
 		podVolumes = append(podVolumes, t.Spec.SideCars.Volumes...)
 	}
 
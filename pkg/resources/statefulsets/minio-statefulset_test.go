@@ -20,6 +20,7 @@ import (
 	"reflect"
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	miniov2 "github.com/minio/operator/pkg/apis/minio.min.io/v2"
@@ -90,6 +91,41 @@ func TestGetContainerArgs(t *testing.T) {
 				"https://minio-pool-0-{0...3}.minio-hl..svc.cluster.local/export{0...3}",
 			},
 		},
+		{
+			name: "Decommissioned Pool Tenant",
+			args: args{
+				t: &miniov2.Tenant{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "minio",
+					},
+					Spec: miniov2.TenantSpec{
+						Pools: []miniov2.Pool{
+							{
+								Name:             "pool-0",
+								Servers:          4,
+								VolumesPerServer: 4,
+								Decommission:     true,
+							},
+							{
+								Name:             "pool-1",
+								Servers:          4,
+								VolumesPerServer: 2,
+							},
+						},
+					},
+					Status: miniov2.TenantStatus{
+						Pools: []miniov2.PoolStatus{
+							{SSName: "minio-pool-0", State: miniov2.PoolDecommissioned},
+							{SSName: "minio-pool-1", State: miniov2.PoolInitialized},
+						},
+					},
+				},
+				hostsTemplate: "",
+			},
+			want: []string{
+				"https://minio-pool-1-{0...3}.minio-hl..svc.cluster.local/export{0...1}",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -102,3 +138,111 @@ func TestGetContainerArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPoolContainerArg(t *testing.T) {
+	tenant := &miniov2.Tenant{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "minio",
+		},
+		Spec: miniov2.TenantSpec{
+			Pools: []miniov2.Pool{
+				{Name: "pool-0", Servers: 4, VolumesPerServer: 4},
+				{Name: "pool-1", Servers: 4, VolumesPerServer: 4},
+				{Name: "pool-2", Servers: 4, VolumesPerServer: 4},
+			},
+		},
+		Status: miniov2.TenantStatus{
+			Pools: []miniov2.PoolStatus{
+				{SSName: "minio-pool-0", State: miniov2.PoolDecommissioned},
+				{SSName: "minio-pool-1", State: miniov2.PoolDecommissioning},
+				{SSName: "minio-pool-2", State: miniov2.PoolInitialized},
+			},
+		},
+	}
+	tenant.EnsureDefaults()
+
+	want := []string{
+		"",
+		"https://minio-pool-1-{0...3}.minio-hl..svc.cluster.local/export{0...3}",
+		"https://minio-pool-2-{0...3}.minio-hl..svc.cluster.local/export{0...3}",
+	}
+	for pi := range tenant.Spec.Pools {
+		if got := GetPoolContainerArg(tenant, pi, ""); got != want[pi] {
+			t.Errorf("GetPoolContainerArg(%d) = %v, want %v", pi, got, want[pi])
+		}
+	}
+}
+
+func TestPoolProbes(t *testing.T) {
+	disabled := false
+	exec := corev1.Handler{Exec: &corev1.ExecAction{Command: []string{"true"}}}
+	tests := []struct {
+		name          string
+		tenant        *miniov2.Tenant
+		pool          *miniov2.Pool
+		wantScheme    corev1.URIScheme
+		wantLiveness  corev1.Handler
+		wantReadiness int32
+		wantStartup   int32
+	}{
+		{
+			name:          "Defaults With TLS",
+			tenant:        &miniov2.Tenant{},
+			pool:          &miniov2.Pool{},
+			wantScheme:    corev1.URISchemeHTTPS,
+			wantReadiness: miniov2.MinIOReadinessFailureThreshold,
+			wantStartup:   miniov2.MinIOStartupFailureThreshold,
+		},
+		{
+			name: "Defaults Without TLS",
+			tenant: &miniov2.Tenant{
+				Spec: miniov2.TenantSpec{RequestAutoCert: &disabled},
+			},
+			pool:          &miniov2.Pool{},
+			wantScheme:    corev1.URISchemeHTTP,
+			wantReadiness: miniov2.MinIOReadinessFailureThreshold,
+			wantStartup:   miniov2.MinIOStartupFailureThreshold,
+		},
+		{
+			name: "Pool Overrides Tenant",
+			tenant: &miniov2.Tenant{
+				Spec: miniov2.TenantSpec{
+					Liveness:  &corev1.Probe{Handler: exec},
+					Readiness: &corev1.Probe{FailureThreshold: 5},
+					Startup:   &corev1.Probe{FailureThreshold: 120},
+				},
+			},
+			pool: &miniov2.Pool{
+				Readiness: &corev1.Probe{FailureThreshold: 1},
+			},
+			wantScheme:    corev1.URISchemeHTTPS,
+			wantLiveness:  exec,
+			wantReadiness: 1,
+			wantStartup:   120,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			liveness, readiness, startup := PoolProbes(tt.tenant, tt.pool)
+			if tt.wantLiveness.Exec != nil {
+				if !reflect.DeepEqual(liveness.Handler, tt.wantLiveness) {
+					t.Errorf("PoolProbes() liveness = %v, want %v", liveness.Handler, tt.wantLiveness)
+				}
+			} else if liveness.HTTPGet == nil || liveness.HTTPGet.Path != miniov2.MinIOLivenessPath || liveness.HTTPGet.Scheme != tt.wantScheme {
+				t.Errorf("PoolProbes() liveness = %v, want %s %s", liveness.HTTPGet, tt.wantScheme, miniov2.MinIOLivenessPath)
+			}
+			if readiness.HTTPGet == nil || readiness.HTTPGet.Path != miniov2.MinIOReadinessPath || readiness.HTTPGet.Scheme != tt.wantScheme {
+				t.Errorf("PoolProbes() readiness = %v, want %s %s", readiness.HTTPGet, tt.wantScheme, miniov2.MinIOReadinessPath)
+			}
+			if readiness.FailureThreshold != tt.wantReadiness {
+				t.Errorf("PoolProbes() readiness failureThreshold = %d, want %d", readiness.FailureThreshold, tt.wantReadiness)
+			}
+			if startup.HTTPGet == nil || startup.HTTPGet.Path != miniov2.MinIOLivenessPath {
+				t.Errorf("PoolProbes() startup = %v, want %s", startup.HTTPGet, miniov2.MinIOLivenessPath)
+			}
+			if startup.FailureThreshold != tt.wantStartup {
+				t.Errorf("PoolProbes() startup failureThreshold = %d, want %d", startup.FailureThreshold, tt.wantStartup)
+			}
+		})
+	}
+}